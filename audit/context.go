@@ -0,0 +1,30 @@
+// Package audit records every create, update and delete made through GORM as
+// an AuditLog, in the same transaction as the change
+package audit
+
+import "context"
+
+// Actor of changes made outside a request, e.g., migrations and scripts
+const ActorSystem = "system"
+
+// Caller is who made a request and where it came from
+type Caller struct {
+	Actor string
+	IP    string
+}
+
+type callerContextKey struct{}
+
+// WithCaller returns a copy of ctx carrying the caller its changes are
+// attributed to
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerContextKey{}, caller)
+}
+
+// CallerFrom returns the caller carried by ctx, or the system actor
+func CallerFrom(ctx context.Context) Caller {
+	if caller, ok := ctx.Value(callerContextKey{}).(Caller); ok {
+		return caller
+	}
+	return Caller{Actor: ActorSystem}
+}
@@ -0,0 +1,280 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"api-rentcar/logger"
+	"api-rentcar/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// beforeKey stores the rows an update or delete is about to change
+const beforeKey = "audit:before"
+
+// Plugin is a GORM plugin that writes an AuditLog for every record created,
+// updated or deleted, in the transaction of the change, so a change is never
+// made without its log. Updates and deletes load the affected rows first,
+// which costs a query each; changes to AuditLogs themselves aren't logged.
+type Plugin struct{}
+
+// NewPlugin returns the audit plugin, to install with db.Use
+func NewPlugin() *Plugin {
+	return &Plugin{}
+}
+
+// Name implements gorm.Plugin
+func (p *Plugin) Name() string {
+	return "audit"
+}
+
+// Initialize implements gorm.Plugin by registering the callbacks between the
+// opening and closing of each change's transaction
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	create := db.Callback().Create()
+	if err := create.After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register("audit:after_create", afterCreate); err != nil {
+		return err
+	}
+
+	update := db.Callback().Update()
+	if err := update.After("gorm:begin_transaction").Before("gorm:update").Register("audit:before_update", loadBefore); err != nil {
+		return err
+	}
+	if err := update.After("gorm:update").Before("gorm:commit_or_rollback_transaction").Register("audit:after_update", afterUpdate); err != nil {
+		return err
+	}
+
+	delete := db.Callback().Delete()
+	if err := delete.After("gorm:begin_transaction").Before("gorm:delete").Register("audit:before_delete", loadBefore); err != nil {
+		return err
+	}
+	return delete.After("gorm:delete").Before("gorm:commit_or_rollback_transaction").Register("audit:after_delete", afterDelete)
+}
+
+// audited reports whether the statement changes records of a model that is
+// audited
+func audited(db *gorm.DB) bool {
+	s := db.Statement.Schema
+	return db.Error == nil && s != nil && s.PrioritizedPrimaryField != nil && s.Table != auditLogTable(db)
+}
+
+func auditLogTable(db *gorm.DB) string {
+	return db.NamingStrategy.TableName("AuditLog")
+}
+
+// afterCreate logs the created records with all their fields as changes
+func afterCreate(db *gorm.DB) {
+	if !audited(db) || db.Statement.ReflectValue.Kind() == reflect.Map {
+		return
+	}
+
+	var entries []models.AuditLog
+	for _, record := range records(db.Statement.ReflectValue) {
+		after, err := fieldsOf(record.Interface())
+		if err != nil {
+			_ = db.AddError(err)
+			return
+		}
+		entries = append(entries, newEntry(db, models.AuditActionCreate, record, nil, after))
+	}
+	write(db, entries)
+}
+
+// loadBefore loads the rows an update or delete matches, by its conditions
+// and the primary key of its model
+func loadBefore(db *gorm.DB) {
+	if !audited(db) {
+		return
+	}
+
+	query := db.Session(&gorm.Session{NewDB: true})
+	conditions := 0
+	if c, ok := db.Statement.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+			query = query.Clauses(where)
+			conditions++
+		}
+	}
+	if value := reflect.Indirect(db.Statement.ReflectValue); value.Kind() == reflect.Struct {
+		for _, field := range db.Statement.Schema.PrimaryFields {
+			if v, isZero := field.ValueOf(db.Statement.Context, value); !isZero {
+				query = query.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: v})
+				conditions++
+			}
+		}
+	}
+	// GORM refuses updates and deletes of every row, so there is nothing to load
+	if conditions == 0 {
+		return
+	}
+
+	rows := reflect.New(reflect.SliceOf(db.Statement.Schema.ModelType))
+	if err := query.Find(rows.Interface()).Error; err != nil {
+		_ = db.AddError(err)
+		return
+	}
+	db.InstanceSet(beforeKey, rows.Elem())
+}
+
+// afterUpdate logs the changed fields of the updated rows, reloaded by their
+// primary keys as the update may have changed its own conditions, e.g., a
+// version
+func afterUpdate(db *gorm.DB) {
+	before, ok := beforeRows(db)
+	if !ok {
+		return
+	}
+
+	primaryKey := db.Statement.Schema.PrioritizedPrimaryField
+	ids := make([]interface{}, 0, before.Len())
+	for i := 0; i < before.Len(); i++ {
+		id, _ := primaryKey.ValueOf(db.Statement.Context, before.Index(i))
+		ids = append(ids, id)
+	}
+
+	after := reflect.New(before.Type())
+	if err := db.Session(&gorm.Session{NewDB: true}).Where(clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: primaryKey.DBName}, Values: ids}).Find(after.Interface()).Error; err != nil {
+		_ = db.AddError(err)
+		return
+	}
+	afterByID := make(map[interface{}]reflect.Value)
+	for _, row := range records(after.Elem()) {
+		id, _ := primaryKey.ValueOf(db.Statement.Context, row)
+		afterByID[id] = row
+	}
+
+	var entries []models.AuditLog
+	for _, row := range records(before) {
+		id, _ := primaryKey.ValueOf(db.Statement.Context, row)
+		updated, ok := afterByID[id]
+		if !ok {
+			continue
+		}
+		beforeFields, err := fieldsOf(row.Interface())
+		if err != nil {
+			_ = db.AddError(err)
+			return
+		}
+		afterFields, err := fieldsOf(updated.Interface())
+		if err != nil {
+			_ = db.AddError(err)
+			return
+		}
+		// Updates that set fields to the values they had change nothing
+		if entry := newEntry(db, models.AuditActionUpdate, row, beforeFields, afterFields); entry.Changes != "{}" {
+			entries = append(entries, entry)
+		}
+	}
+	write(db, entries)
+}
+
+// afterDelete logs the deleted rows with all their fields as changes
+func afterDelete(db *gorm.DB) {
+	before, ok := beforeRows(db)
+	if !ok {
+		return
+	}
+
+	var entries []models.AuditLog
+	for _, row := range records(before) {
+		fields, err := fieldsOf(row.Interface())
+		if err != nil {
+			_ = db.AddError(err)
+			return
+		}
+		entries = append(entries, newEntry(db, models.AuditActionDelete, row, fields, nil))
+	}
+	write(db, entries)
+}
+
+// beforeRows returns the rows loaded by loadBefore when the change succeeded
+// and affected any
+func beforeRows(db *gorm.DB) (reflect.Value, bool) {
+	if !audited(db) || db.RowsAffected == 0 {
+		return reflect.Value{}, false
+	}
+	value, ok := db.InstanceGet(beforeKey)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	rows := value.(reflect.Value)
+	return rows, rows.Len() > 0
+}
+
+// records returns the structs of a struct or slice value
+func records(value reflect.Value) []reflect.Value {
+	value = reflect.Indirect(value)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return []reflect.Value{value}
+	}
+
+	items := make([]reflect.Value, value.Len())
+	for i := range items {
+		items[i] = reflect.Indirect(value.Index(i))
+	}
+	return items
+}
+
+// fieldsOf returns the JSON fields of a record
+func fieldsOf(record interface{}) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// fieldChange is a changed field of an AuditLog
+type fieldChange struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// newEntry returns the AuditLog of a change to record, from the fields it had
+// before to those it has after
+func newEntry(db *gorm.DB, action string, record reflect.Value, before, after map[string]json.RawMessage) models.AuditLog {
+	changes := make(map[string]fieldChange)
+	for name, value := range before {
+		if !bytes.Equal(value, after[name]) {
+			changes[name] = fieldChange{Before: value, After: after[name]}
+		}
+	}
+	for name, value := range after {
+		if _, ok := before[name]; !ok {
+			changes[name] = fieldChange{After: value}
+		}
+	}
+	data, _ := json.Marshal(changes)
+
+	ctx := db.Statement.Context
+	caller := CallerFrom(ctx)
+	id, _ := db.Statement.Schema.PrioritizedPrimaryField.ValueOf(ctx, record)
+	return models.AuditLog{
+		Actor:      caller.Actor,
+		Action:     action,
+		EntityType: db.NamingStrategy.ColumnName("", db.Statement.Schema.Name),
+		EntityID:   fmt.Sprint(id),
+		Changes:    string(data),
+		RequestID:  logger.RequestID(ctx),
+		IP:         caller.IP,
+	}
+}
+
+// write inserts the entries in the transaction of the change, failing the
+// change when they can't be
+func write(db *gorm.DB, entries []models.AuditLog) {
+	if len(entries) == 0 {
+		return
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&entries).Error; err != nil {
+		_ = db.AddError(fmt.Errorf("failed to write audit log: %w", err))
+	}
+}
@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"api-rentcar/models"
+	productRepo "api-rentcar/repositories/product"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newAuditedDB returns an in-memory SQLite database with the plugin installed,
+// whose clock stands still so only the fields a change sets differ
+func newAuditedDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger:  logger.Discard,
+		NowFunc: func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to :memory: opens a database of its own
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&models.Product{}, &models.AuditLog{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(NewPlugin()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func createProduct(t *testing.T, db *gorm.DB) *models.Product {
+	t.Helper()
+	product := &models.Product{Name: "Child seat", Description: "A seat for children up to 4 years old"}
+	if err := db.Create(product).Error; err != nil {
+		t.Fatal(err)
+	}
+	return product
+}
+
+func auditLogs(t *testing.T, db *gorm.DB, action string) []models.AuditLog {
+	t.Helper()
+	var logs []models.AuditLog
+	if err := db.Where("action = ?", action).Order("id").Find(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+	return logs
+}
+
+func TestUpdateLogsChangedFields(t *testing.T) {
+	db := newAuditedDB(t)
+	repo := productRepo.NewProductRepository(db)
+	product := createProduct(t, db)
+
+	ctx := WithCaller(context.Background(), Caller{Actor: "admin", IP: "203.0.113.7"})
+	product.Name = "Booster seat"
+	if err := repo.Update(ctx, product); err != nil {
+		t.Fatal(err)
+	}
+
+	logs := auditLogs(t, db, models.AuditActionUpdate)
+	if len(logs) != 1 {
+		t.Fatalf("%d update logs, want 1", len(logs))
+	}
+	entry := logs[0]
+	if entry.EntityType != "product" || entry.EntityID != "1" || entry.Actor != "admin" || entry.IP != "203.0.113.7" {
+		t.Errorf("entry = %+v", entry)
+	}
+
+	var changes map[string]fieldChange
+	if err := json.Unmarshal([]byte(entry.Changes), &changes); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][2]string{
+		"name":    {`"Child seat"`, `"Booster seat"`},
+		"version": {"1", "2"},
+	}
+	if len(changes) != len(want) {
+		t.Errorf("changes = %s, want only name and version", entry.Changes)
+	}
+	for name, values := range want {
+		change := changes[name]
+		if string(change.Before) != values[0] || string(change.After) != values[1] {
+			t.Errorf("%s changed from %s to %s, want %s to %s", name, change.Before, change.After, values[0], values[1])
+		}
+	}
+}
+
+func TestUnchangedUpdateIsNotLogged(t *testing.T) {
+	db := newAuditedDB(t)
+	product := createProduct(t, db)
+
+	if err := db.Model(product).Updates(map[string]interface{}{"name": product.Name}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if logs := auditLogs(t, db, models.AuditActionUpdate); len(logs) != 0 {
+		t.Errorf("logged %d updates that changed nothing: %+v", len(logs), logs)
+	}
+	if logs := auditLogs(t, db, models.AuditActionCreate); len(logs) != 1 {
+		t.Errorf("%d create logs, want 1", len(logs))
+	}
+}
+
+func TestFailedAuditLogRollsBackChange(t *testing.T) {
+	db := newAuditedDB(t)
+	product := createProduct(t, db)
+
+	// Without its table, the audit log can't be written
+	if err := db.Migrator().DropTable(&models.AuditLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := db.Model(product).Update("name", "Booster seat").Error
+	if err == nil || !strings.Contains(err.Error(), "failed to write audit log") {
+		t.Fatalf("err = %v, want the audit log failure", err)
+	}
+	var stored models.Product
+	if err := db.First(&stored, product.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.Name != "Child seat" {
+		t.Errorf("name = %q, want the update rolled back", stored.Name)
+	}
+
+	err = db.Delete(&models.Product{}, product.ID).Error
+	if err == nil {
+		t.Fatal("delete succeeded without its audit log")
+	}
+	if err := db.First(&stored, product.ID).Error; err != nil {
+		t.Errorf("product gone after a failed delete: %v", err)
+	}
+}
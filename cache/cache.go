@@ -0,0 +1,160 @@
+// Package cache stores serialized values for a limited time, in memory or in Redis
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Drivers
+const (
+	DriverNone   = "none"
+	DriverMemory = "memory"
+	DriverRedis  = "redis"
+)
+
+// Cache stores values under keys until their TTL passes. A TTL of zero keeps
+// a value until it is deleted or evicted.
+type Cache interface {
+	// Get returns the value stored under key, and false when there is none
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Add stores value only when key holds none, and reports whether it did
+	Add(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// New returns the cache of a driver: an in-memory LRU cache of up to
+// maxEntries values, Redis at redisURL, e.g., redis://localhost:6379/0, or a
+// cache that stores nothing
+func New(driver string, maxEntries int, redisURL string) (Cache, error) {
+	switch driver {
+	case DriverNone:
+		return None{}, nil
+	case DriverMemory:
+		return NewMemory(maxEntries), nil
+	case DriverRedis:
+		return NewRedis(redisURL)
+	default:
+		return nil, fmt.Errorf("unknown cache driver %q (use %s, %s or %s)", driver, DriverNone, DriverMemory, DriverRedis)
+	}
+}
+
+// None is a Cache that stores nothing, so every read misses
+type None struct{}
+
+func (None) Get(context.Context, string) ([]byte, bool, error)                { return nil, false, nil }
+func (None) Set(context.Context, string, []byte, time.Duration) error         { return nil }
+func (None) Add(context.Context, string, []byte, time.Duration) (bool, error) { return true, nil }
+func (None) Delete(context.Context, ...string) error                          { return nil }
+
+// GetJSON decodes the JSON value stored under key into v and reports whether
+// there was one
+func GetJSON(ctx context.Context, c Cache, key string, v any) (bool, error) {
+	data, ok, err := c.Get(ctx, key)
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetJSON stores v as JSON under key
+func SetJSON(ctx context.Context, c Cache, key string, v any, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, key, data, ttl)
+}
+
+// Namespace groups the keys of an entity, e.g., cars, so they can all be
+// invalidated at once. Keys are prefixed with a generation stored in the cache
+// itself, which invalidation replaces, leaving the old keys to expire; this
+// works the same across instances sharing a Redis cache.
+type Namespace struct {
+	cache Cache
+	name  string
+	ttl   time.Duration
+}
+
+// NewNamespace returns the namespace name of c whose values live for ttl. A
+// nil Namespace caches nothing.
+func NewNamespace(c Cache, name string, ttl time.Duration) *Namespace {
+	return &Namespace{cache: c, name: name, ttl: ttl}
+}
+
+// Remember decodes the value of key into v, or, when there is none, calls
+// load to fill v and stores it. Cache errors are logged and load is used, so
+// an unavailable cache only makes reads slower. The generation is read once,
+// so a value loaded before an invalidation is never stored in the new one.
+func (n *Namespace) Remember(ctx context.Context, key string, v any, load func() error) error {
+	if n == nil {
+		return load()
+	}
+
+	prefix, err := n.prefix(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "cache unavailable", slog.String("namespace", n.name), slog.String("error", err.Error()))
+		return load()
+	}
+
+	found, err := GetJSON(ctx, n.cache, prefix+key, v)
+	if err != nil {
+		slog.WarnContext(ctx, "cache read failed", slog.String("key", prefix+key), slog.String("error", err.Error()))
+	}
+	if found {
+		return nil
+	}
+
+	if err := load(); err != nil {
+		return err
+	}
+	if err := SetJSON(ctx, n.cache, prefix+key, v, n.ttl); err != nil {
+		slog.WarnContext(ctx, "cache write failed", slog.String("key", prefix+key), slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+// Invalidate drops every value of the namespace. It runs even when ctx is
+// cancelled, as the change it follows is already made; failures are logged
+// and leave the values to expire with their TTL.
+func (n *Namespace) Invalidate(ctx context.Context) {
+	if n == nil {
+		return
+	}
+	if err := n.cache.Delete(context.WithoutCancel(ctx), n.generationKey()); err != nil {
+		slog.WarnContext(ctx, "cache invalidation failed", slog.String("namespace", n.name), slog.String("error", err.Error()))
+	}
+}
+
+// prefix returns the key prefix of the current generation, starting a new
+// generation when there is none
+func (n *Namespace) prefix(ctx context.Context) (string, error) {
+	generation, ok, err := n.cache.Get(ctx, n.generationKey())
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		buf := make([]byte, 8)
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		generation = []byte(hex.EncodeToString(buf))
+		if err := n.cache.Set(ctx, n.generationKey(), generation, 0); err != nil {
+			return "", err
+		}
+	}
+	return n.name + ":" + string(generation) + ":", nil
+}
+
+func (n *Namespace) generationKey() string {
+	return n.name + ":generation"
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNamespaceRemember(t *testing.T) {
+	ctx := context.Background()
+	cars := NewNamespace(NewMemory(100), "cars", time.Minute)
+
+	loads := 0
+	load := func(v *[]string) func() error {
+		return func() error {
+			loads++
+			*v = []string{"Avanza", "Xenia"}
+			return nil
+		}
+	}
+
+	var first, second []string
+	if err := cars.Remember(ctx, "list:1", &first, load(&first)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cars.Remember(ctx, "list:1", &second, load(&second)); err != nil {
+		t.Fatal(err)
+	}
+	if loads != 1 || len(second) != 2 || second[1] != "Xenia" {
+		t.Errorf("loaded %d times, cached value %v", loads, second)
+	}
+
+	cars.Invalidate(ctx)
+	var third []string
+	if err := cars.Remember(ctx, "list:1", &third, load(&third)); err != nil {
+		t.Fatal(err)
+	}
+	if loads != 2 {
+		t.Errorf("value served from the cache after Invalidate")
+	}
+}
+
+func TestNamespacesAreInvalidatedSeparately(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemory(100)
+	cars := NewNamespace(store, "cars", time.Minute)
+	products := NewNamespace(store, "products", time.Minute)
+
+	var value string
+	loads := 0
+	load := func() error { loads++; value = "v"; return nil }
+	_ = cars.Remember(ctx, "1", &value, load)
+	_ = products.Remember(ctx, "1", &value, load)
+
+	cars.Invalidate(ctx)
+	_ = products.Remember(ctx, "1", &value, load)
+	_ = cars.Remember(ctx, "1", &value, load)
+	if loads != 3 {
+		t.Errorf("loaded %d times, want 3: the products value should have stayed cached", loads)
+	}
+}
+
+func TestNamespaceRememberDoesNotStoreFailedLoads(t *testing.T) {
+	ctx := context.Background()
+	cars := NewNamespace(NewMemory(100), "cars", time.Minute)
+	errLoad := errors.New("database down")
+
+	var value int
+	if err := cars.Remember(ctx, "count", &value, func() error { return errLoad }); !errors.Is(err, errLoad) {
+		t.Fatalf("err = %v, want the load error", err)
+	}
+	if err := cars.Remember(ctx, "count", &value, func() error { value = 3; return nil }); err != nil || value != 3 {
+		t.Errorf("after a failed load got %d, %v, want the value loaded again", value, err)
+	}
+
+	var nilNamespace *Namespace
+	if err := nilNamespace.Remember(ctx, "count", &value, func() error { value = 4; return nil }); err != nil || value != 4 {
+		t.Errorf("nil namespace got %d, %v, want the loaded value", value, err)
+	}
+}
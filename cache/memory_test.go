@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(2)
+	_ = m.Set(ctx, "a", []byte("1"), 0)
+	_ = m.Set(ctx, "b", []byte("2"), 0)
+	if _, ok, _ := m.Get(ctx, "a"); !ok {
+		t.Fatal("a missing")
+	}
+	_ = m.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok, _ := m.Get(ctx, "b"); ok {
+		t.Error("b, the least recently used value, wasn't evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := m.Get(ctx, key); !ok {
+			t.Errorf("%s evicted", key)
+		}
+	}
+	if m.Len() != 2 {
+		t.Errorf("Len = %d, want 2", m.Len())
+	}
+}
+
+func TestMemoryExpiresValues(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(0)
+	_ = m.Set(ctx, "short", []byte("1"), time.Millisecond)
+	_ = m.Set(ctx, "forever", []byte("2"), 0)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok, _ := m.Get(ctx, "short"); ok {
+		t.Error("expired value returned")
+	}
+	if value, ok, _ := m.Get(ctx, "forever"); !ok || string(value) != "2" {
+		t.Errorf("value without TTL = %q, %v", value, ok)
+	}
+}
+
+func TestMemoryAdd(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(0)
+
+	if added, _ := m.Add(ctx, "key", []byte("first"), time.Millisecond); !added {
+		t.Fatal("first Add didn't store the value")
+	}
+	if added, _ := m.Add(ctx, "key", []byte("second"), 0); added {
+		t.Error("Add replaced a live value")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if added, _ := m.Add(ctx, "key", []byte("third"), 0); !added {
+		t.Error("Add didn't replace an expired value")
+	}
+	if value, _, _ := m.Get(ctx, "key"); string(value) != "third" {
+		t.Errorf("value = %q, want third", value)
+	}
+}
@@ -3,19 +3,28 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"api-rentcar/cache"
 	"api-rentcar/config"
 	_ "api-rentcar/docs"
+	"api-rentcar/logger"
+	"api-rentcar/metrics"
 	"api-rentcar/middleware"
+	"api-rentcar/repositories/car"
 	"api-rentcar/routes"
+	"api-rentcar/services"
+	"api-rentcar/telemetry"
 	"api-rentcar/utils"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 // @title RESTful API GO
@@ -41,6 +50,32 @@ func main() {
 		log.Fatal("Failed to load configuration:", err)
 	}
 
+	// Set up structured logging, which the standard log package writes through as well
+	appLogger, err := logger.New(os.Stdout, config.AppConfig.LogLevel, config.AppConfig.LogFormat)
+	if err != nil {
+		log.Fatal("Failed to set up logging:", err)
+	}
+	slog.SetDefault(appLogger)
+
+	// Set up tracing before the database so queries are traced
+	shutdownTracing, err := telemetry.Setup(context.Background(), telemetry.Options{
+		ServiceName:  config.AppConfig.TracingServiceName,
+		Exporter:     config.AppConfig.TracingExporter,
+		OTLPEndpoint: config.AppConfig.TracingOTLPEndpoint,
+		FilePath:     config.AppConfig.TracingFile,
+		SampleRatio:  config.AppConfig.TracingSampleRatio,
+	})
+	if err != nil {
+		log.Fatal("Failed to set up tracing:", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Error shutting down tracing: %v", err)
+		}
+	}()
+
 	// Initialize database
 	if err := config.InitDatabase(); err != nil {
 		log.Fatal("Failed to initialize database:", err)
@@ -51,9 +86,29 @@ func main() {
 		}
 	}()
 
+	// Set up the cache of car and product reads
+	appCache, err := cache.New(config.AppConfig.CacheDriver, config.AppConfig.CacheMaxEntries, config.AppConfig.RedisURL)
+	if err != nil {
+		log.Fatal("Failed to set up cache:", err)
+	}
+	if redisCache, ok := appCache.(*cache.Redis); ok {
+		// Reads fall back to the database while Redis is unreachable
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := redisCache.Ping(ctx); err != nil {
+			log.Printf("Redis cache unreachable: %v", err)
+		}
+		cancel()
+		defer redisCache.Close()
+	}
+
 	// Initialize validator
 	utils.InitValidator()
 
+	// Select the envelope or legacy response bodies
+	if err := utils.SetResponseFormat(config.AppConfig.ResponseFormat); err != nil {
+		log.Fatal("Failed to set up responses:", err)
+	}
+
 	// Set Gin mode
 	gin.SetMode(config.AppConfig.GinMode)
 
@@ -61,13 +116,78 @@ func main() {
 	router := gin.New()
 
 	// Apply global middleware
+	router.Use(middleware.RequestID(config.AppConfig.TrustRequestID))
+	if config.AppConfig.TracingExporter != telemetry.ExporterNone {
+		router.Use(otelgin.Middleware(config.AppConfig.TracingServiceName))
+	}
 	router.Use(middleware.Logger())
+	if config.AppConfig.MetricsEnabled {
+		router.Use(metrics.Middleware())
+	}
+	if config.AppConfig.CompressionEnabled {
+		router.Use(middleware.Compress(config.AppConfig.CompressionMinSize, config.AppConfig.CompressionTypes))
+	}
 	router.Use(middleware.Recovery())
 	router.Use(middleware.CORS())
 	router.Use(middleware.SecurityHeaders())
 
+	router.Use(middleware.QueryTimeout(config.AppConfig.QueryTimeout))
+
+	// Validate requests (and responses in debug mode) against the OpenAPI document
+	if config.AppConfig.OpenAPIValidation != middleware.OpenAPIValidationOff {
+		validator, err := middleware.OpenAPIValidator(config.AppConfig.OpenAPIValidation, config.AppConfig.GinMode == gin.DebugMode)
+		if err != nil {
+			log.Fatal("Failed to set up OpenAPI validation:", err)
+		}
+		router.Use(validator)
+	}
+
+	// Replay the responses of retried POST requests that carry an Idempotency-Key.
+	// Responses are kept in Redis when it is the cache, so retries reaching
+	// another instance are replayed too.
+	idempotencyStore := appCache
+	if _, ok := appCache.(*cache.Redis); !ok {
+		idempotencyStore = cache.NewMemory(config.AppConfig.CacheMaxEntries)
+	}
+	router.Use(middleware.Idempotency(idempotencyStore, config.AppConfig.IdempotencyWindow))
+
 	// Setup routes
-	routes.SetupRoutes(router, config.GetDB())
+	routes.SetupRoutes(router, config.GetDB(), appCache)
+
+	// Serve metrics on the API port unless a separate admin port is configured
+	var adminServer *http.Server
+	if config.AppConfig.MetricsEnabled {
+		if sqlDB, err := config.GetDB().DB(); err == nil {
+			if err := metrics.RegisterDBStats(sqlDB, config.AppConfig.DBType); err != nil {
+				log.Printf("Failed to export database pool metrics: %v", err)
+			}
+		}
+
+		metricsHandler := metrics.Handler(config.AppConfig.MetricsToken)
+		if config.AppConfig.MetricsPort == "" {
+			router.GET("/metrics", gin.WrapH(metricsHandler))
+		} else {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", metricsHandler)
+			adminServer = &http.Server{
+				Addr:              ":" + config.AppConfig.MetricsPort,
+				Handler:           mux,
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+		}
+	}
+
+	// Request contexts derive from baseCtx so that shutdown can cancel in-flight queries
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
+	// Apply scheduled car price changes as they take effect. Every instance
+	// may run the scheduler; each change is applied once.
+	if config.AppConfig.PriceSchedulerInterval > 0 {
+		carCache := cache.NewNamespace(appCache, "cars", config.AppConfig.CacheTTL)
+		priceService := services.NewCarPriceService(car.NewCarRepository(config.GetDB()), carCache)
+		go priceService.RunScheduler(baseCtx, config.AppConfig.PriceSchedulerInterval)
+	}
 
 	// Create HTTP server
 	server := &http.Server{
@@ -76,6 +196,9 @@ func main() {
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   10 * time.Second,
 		MaxHeaderBytes: 1 << 20, // 1 MB
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
 	}
 
 	// Start server in a goroutine
@@ -89,6 +212,15 @@ func main() {
 		}
 	}()
 
+	if adminServer != nil {
+		go func() {
+			log.Printf("Metrics available at: http://localhost:%s/metrics", config.AppConfig.MetricsPort)
+			if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatal("Failed to start metrics server:", err)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -99,7 +231,15 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if adminServer != nil {
+		if err := adminServer.Shutdown(ctx); err != nil {
+			log.Printf("Error shutting down metrics server: %v", err)
+		}
+	}
+
 	if err := server.Shutdown(ctx); err != nil {
+		// Cancel the queries of requests that didn't finish in time
+		cancelRequests()
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
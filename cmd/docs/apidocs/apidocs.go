@@ -0,0 +1,123 @@
+package apidocs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/swaggo/swag/gen"
+	"sigs.k8s.io/yaml"
+)
+
+// Paths used when generating the docs, relative to the project root
+const (
+	MainAPIFile = "cmd/api/main.go"
+	OutputDir   = "docs"
+)
+
+// Files are the generated documentation files
+var Files = []string{
+	"docs.go",
+	"swagger.json",
+	"swagger.yaml",
+	"openapi.json",
+	"openapi.yaml",
+}
+
+// Generate parses the Swagger annotations and writes the Swagger 2.0 docs
+// (docs.go, swagger.json, swagger.yaml) and their OpenAPI 3 conversion
+// (openapi.json, openapi.yaml) into dir
+func Generate(dir string) error {
+	// Same settings as: swag init -g cmd/api/main.go -o docs --parseDependency --parseInternal
+	err := gen.New().Build(&gen.Config{
+		Debugger:        log.New(io.Discard, "", 0),
+		SearchDir:       "./",
+		MainAPIFile:     MainAPIFile,
+		OutputDir:       dir,
+		OutputTypes:     []string{"go", "json", "yaml"},
+		PackageName:     "docs",
+		ParseDependency: 1,
+		ParseInternal:   true,
+		ParseGoList:     true,
+		ParseDepth:      100,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate swagger docs: %v", err)
+	}
+
+	return writeOpenAPI3(dir)
+}
+
+// Check regenerates the docs into a temporary directory and returns the
+// committed files in OutputDir that differ from the annotations
+func Check() ([]string, error) {
+	tmp, err := os.MkdirTemp("", "apidocs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := Generate(tmp); err != nil {
+		return nil, err
+	}
+
+	var drifted []string
+	for _, name := range Files {
+		committed, err := os.ReadFile(filepath.Join(OutputDir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %v", name, err)
+		}
+		generated, err := os.ReadFile(filepath.Join(tmp, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read generated %s: %v", name, err)
+		}
+		if !bytes.Equal(committed, generated) {
+			drifted = append(drifted, filepath.Join(OutputDir, name))
+		}
+	}
+
+	return drifted, nil
+}
+
+// writeOpenAPI3 converts the generated swagger.json to OpenAPI 3 and writes
+// it as openapi.json and openapi.yaml
+func writeOpenAPI3(dir string) error {
+	content, err := os.ReadFile(filepath.Join(dir, "swagger.json"))
+	if err != nil {
+		return fmt.Errorf("failed to read swagger.json: %v", err)
+	}
+
+	var doc2 openapi2.T
+	if err := json.Unmarshal(content, &doc2); err != nil {
+		return fmt.Errorf("failed to parse swagger.json: %v", err)
+	}
+
+	doc3, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		return fmt.Errorf("failed to convert to OpenAPI 3: %v", err)
+	}
+
+	jsonContent, err := json.MarshalIndent(doc3, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to encode openapi.json: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "openapi.json"), jsonContent, 0644); err != nil {
+		return fmt.Errorf("failed to write openapi.json: %v", err)
+	}
+
+	yamlContent, err := yaml.JSONToYAML(jsonContent)
+	if err != nil {
+		return fmt.Errorf("failed to encode openapi.yaml: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "openapi.yaml"), yamlContent, 0644); err != nil {
+		return fmt.Errorf("failed to write openapi.yaml: %v", err)
+	}
+
+	return nil
+}
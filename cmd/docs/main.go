@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"api-rentcar/cmd/docs/apidocs"
+)
+
+func main() {
+	check := flag.Bool("check", false, "fail if the committed docs differ from the annotations instead of regenerating them")
+	flag.Parse()
+
+	if *check {
+		drifted, err := apidocs.Check()
+		if err != nil {
+			fmt.Printf("Error checking docs: %v\n", err)
+			os.Exit(1)
+		}
+		if len(drifted) > 0 {
+			fmt.Println("API docs are out of date with the Swagger annotations:")
+			for _, file := range drifted {
+				fmt.Printf("- %s\n", file)
+			}
+			fmt.Println("Run: go run cmd/docs/main.go")
+			os.Exit(1)
+		}
+		fmt.Println("✓ API docs are up to date")
+		return
+	}
+
+	if err := apidocs.Generate(apidocs.OutputDir); err != nil {
+		fmt.Printf("Error generating docs: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✓ API docs generated in %s/\n", apidocs.OutputDir)
+}
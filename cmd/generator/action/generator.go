@@ -0,0 +1,313 @@
+package action
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+
+	"api-rentcar/cmd/generator/wiring"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+type GeneratorData struct {
+	Name        string // e.g., "Car"
+	LowerName   string // e.g., "car"
+	Action      string // e.g., "Retire"
+	LowerAction string // e.g., "retire" or "mark available"
+	Title       string // e.g., "Retire" or "Mark available"
+	Path        string // e.g., "retire" or "mark-available"
+}
+
+const requestTemplate = `
+// {{.Action}}{{.Name}}Request represents the request payload for the {{.LowerAction}} action on a {{.LowerName}}
+// @Description Request payload for the {{.LowerAction}} action on a {{.LowerName}}
+type {{.Action}}{{.Name}}Request struct {
+	// Add action parameters as needed
+}
+
+// Validate validates the {{.Action}}{{.Name}}Request
+func (r *{{.Action}}{{.Name}}Request) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+`
+
+const serviceMethodTemplate = `	{{.Action}}{{.Name}}(ctx context.Context, id uint, req *requests.{{.Action}}{{.Name}}Request) (*models.{{.Name}}, error)
+`
+
+const serviceTemplate = `
+// {{.Action}}{{.Name}} performs the {{.LowerAction}} action on a {{.LowerName}}
+func (s *{{.Name}}Service) {{.Action}}{{.Name}}(ctx context.Context, id uint, req *requests.{{.Action}}{{.Name}}Request) (*models.{{.Name}}, error) {
+	ctx, span := telemetry.StartSpan(ctx, "{{.Name}}Service.{{.Action}}{{.Name}}")
+	defer span.End()
+
+	// Check if {{.LowerName}} exists
+	existing{{.Name}}, err := s.{{.LowerName}}Repo.GetByID(ctx, id, nil)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("{{.LowerName}} not found")
+		}
+		return nil, err
+	}
+
+	// Apply the {{.LowerAction}} business rules here
+
+	if err := s.{{.LowerName}}Repo.Update(ctx, existing{{.Name}}); err != nil {
+		if errors.Is(err, {{.LowerName}}Repo.ErrVersionConflict) {
+			return nil, errors.New("{{.LowerName}} version mismatch")
+		}
+		return nil, err
+	}
+
+	return existing{{.Name}}, nil
+}
+`
+
+const controllerTemplate = `
+// {{.Action}}{{.Name}} godoc
+// @Summary {{.Title}} a {{.LowerName}}
+// @Description Perform the {{.LowerAction}} action on a {{.LowerName}}
+// @Tags {{.LowerName}}s
+// @Accept json
+// @Produce json
+// @Param id path int true "{{.Name}} ID"
+// @Param {{.LowerName}} body requests.{{.Action}}{{.Name}}Request false "{{.Name}} {{.LowerAction}} request"
+// @Param Idempotency-Key header string false "Unique key that makes retries of the request return the first response"
+// @Success 200 {object} utils.Envelope{data=responses.{{.Name}}Response}
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 409 {object} utils.Envelope
+// @Failure 422 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
+// @Router /{{.LowerName}}s/{id}/{{.Path}} [post]
+func (c *{{.Name}}Controller) {{.Action}}{{.Name}}(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid {{.LowerName}} ID", err)
+		return
+	}
+
+	// The request body is optional for actions
+	var req requests.{{.Action}}{{.Name}}Request
+	if ctx.Request.ContentLength != 0 && !utils.BindAndValidate(ctx, &req) {
+		return
+	}
+
+	{{.LowerName}}, err := c.{{.LowerName}}Service.{{.Action}}{{.Name}}(ctx.Request.Context(), uint(id), &req)
+	if err != nil {
+		switch err.Error() {
+		case "{{.LowerName}} not found":
+			utils.SendErrorResponse(ctx, http.StatusNotFound, "{{.Name}} not found", err)
+		case "{{.LowerName}} version mismatch":
+			utils.SendErrorResponse(ctx, http.StatusConflict, "{{.Name}} was modified by another request", err)
+		default:
+			utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to {{.LowerAction}} {{.LowerName}}", err)
+		}
+		return
+	}
+
+	response := responses.To{{.Name}}Response({{.LowerName}})
+	utils.SendDataResponse(ctx, http.StatusOK, response)
+}
+`
+
+const routeTemplate = `{{.LowerName}}s.POST("/:id/{{.Path}}", {{.LowerName}}Controller.{{.Action}}{{.Name}})`
+
+// Files returns the files an action edits, in the order Generate edits them
+func Files(data GeneratorData) []string {
+	return []string{
+		filepath.Join("requests", fmt.Sprintf("%s.go", data.LowerName)),
+		filepath.Join("services", fmt.Sprintf("%s_service.go", data.LowerName)),
+		filepath.Join("controllers", fmt.Sprintf("%s_controller.go", data.LowerName)),
+		wiring.RoutesFile,
+	}
+}
+
+// Generate appends the request, service method and controller handler for a custom action
+func Generate(data GeneratorData) error {
+	files := Files(data)
+	requestFile, serviceFile, controllerFile := files[0], files[1], files[2]
+
+	// Check everything up front so a failed run doesn't leave a half-wired action
+	for _, file := range files {
+		if _, err := os.Stat(file); err != nil {
+			return fmt.Errorf("%s not found, generate the %s entity first: %v", file, data.Name, err)
+		}
+	}
+	handler := data.Action + data.Name
+	if exists, err := hasFunc(serviceFile, handler); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("%s already exists in %s", handler, serviceFile)
+	}
+
+	request, err := execute("request", requestTemplate, data)
+	if err != nil {
+		return err
+	}
+	if err := editGoFile(requestFile, func(src []byte, _ *ast.File, _ *token.FileSet) ([]byte, error) {
+		return append(src, request...), nil
+	}, map[string]string{"github.com/go-playground/validator/v10": ""}); err != nil {
+		return err
+	}
+
+	method, err := execute("service method", serviceMethodTemplate, data)
+	if err != nil {
+		return err
+	}
+	impl, err := execute("service", serviceTemplate, data)
+	if err != nil {
+		return err
+	}
+	if err := editGoFile(serviceFile, func(src []byte, file *ast.File, fset *token.FileSet) ([]byte, error) {
+		closing, err := interfaceClosing(file, fset, data.Name+"ServiceInterface")
+		if err != nil {
+			return nil, err
+		}
+		result := append([]byte{}, src[:closing]...)
+		result = append(result, method...)
+		result = append(result, src[closing:]...)
+		return append(result, impl...), nil
+	}, map[string]string{
+		"context":               "",
+		"errors":                "",
+		"api-rentcar/models":    "",
+		"api-rentcar/requests":  "requests",
+		"api-rentcar/telemetry": "",
+		"gorm.io/gorm":          "",
+		"api-rentcar/repositories/" + data.LowerName: data.LowerName + "Repo",
+	}); err != nil {
+		return err
+	}
+
+	handlerSource, err := execute("controller", controllerTemplate, data)
+	if err != nil {
+		return err
+	}
+	if err := editGoFile(controllerFile, func(src []byte, _ *ast.File, _ *token.FileSet) ([]byte, error) {
+		return append(src, handlerSource...), nil
+	}, map[string]string{
+		"net/http":                 "",
+		"strconv":                  "",
+		"api-rentcar/requests":     "requests",
+		"api-rentcar/responses":    "",
+		"api-rentcar/utils":        "",
+		"github.com/gin-gonic/gin": "",
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GenerateRoute registers the action's route in its entity's route group and returns the route line
+func GenerateRoute(data GeneratorData) (string, error) {
+	route, err := execute("route", routeTemplate, data)
+	if err != nil {
+		return "", err
+	}
+	return string(route), wiring.RegisterRoute(data.LowerName, string(route))
+}
+
+// execute renders a snippet template
+func execute(kind, text string, data GeneratorData) ([]byte, error) {
+	tmpl, err := template.New(kind).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s template: %v", kind, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute %s template: %v", kind, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// editGoFile applies edit to a Go source file, adds any missing imports (path to
+// name, empty for the default name) and writes it back formatted
+func editGoFile(path string, edit func(src []byte, file *ast.File, fset *token.FileSet) ([]byte, error), imports map[string]string) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+
+	edited, err := edit(src, file, fset)
+	if err != nil {
+		return err
+	}
+
+	fset = token.NewFileSet()
+	file, err = parser.ParseFile(fset, path, edited, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("failed to parse edited %s: %v", path, err)
+	}
+	for importPath, name := range imports {
+		if !hasImport(file, importPath) {
+			astutil.AddNamedImport(fset, file, name, importPath)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return fmt.Errorf("failed to format %s: %v", path, err)
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// hasImport reports whether a file imports a path under any name
+func hasImport(file *ast.File, path string) bool {
+	for _, imp := range file.Imports {
+		if value, err := strconv.Unquote(imp.Path.Value); err == nil && value == path {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFunc reports whether a Go source file declares a function or method named name
+func hasFunc(path, name string) (bool, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// interfaceClosing returns the byte offset of the closing brace of an interface type
+func interfaceClosing(file *ast.File, fset *token.FileSet, name string) (int, error) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if iface, ok := typeSpec.Type.(*ast.InterfaceType); ok && typeSpec.Name.Name == name {
+				return fset.Position(iface.Methods.Closing).Offset, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("interface %s not found", name)
+}
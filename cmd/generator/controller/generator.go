@@ -2,19 +2,24 @@ package controller
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
-	"text/template"
+
+	"api-rentcar/cmd/generator/render"
+	"api-rentcar/cmd/generator/schema"
 )
 
 type GeneratorData struct {
-	Name      string // e.g., "User"
-	LowerName string // e.g., "user"
+	Name      string           // e.g., "User"
+	LowerName string           // e.g., "user"
+	Relations schema.Relations // associations to other entities
 }
 
 const controllerTemplate = `package controllers
 
 import (
+{{- if .Relations.Any}}
+	"errors"
+{{- end}}
 	"net/http"
 	"strconv"
 
@@ -39,14 +44,20 @@ func New{{.Name}}Controller({{.LowerName}}Service services.{{.Name}}ServiceInter
 
 // Create{{.Name}} godoc
 // @Summary Create a new {{.LowerName}}
-// @Description Create a new {{.LowerName}} with the provided information
+// @Description Create a new {{.LowerName}} with the provided information. The created {{.LowerName}} is returned as data, unless the request sends Prefer: return=minimal.
 // @Tags {{.LowerName}}s
 // @Accept json
 // @Produce json
 // @Param {{.LowerName}} body requests.Create{{.Name}}Request true "{{.Name}} creation request"
-// @Success 201 {object} utils.SuccessResponse
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Param Prefer header string false "return=minimal to omit the {{.LowerName}} from the response"
+// @Param Idempotency-Key header string false "Unique key that makes retries of the request return the first response"
+// @Success 201 {object} utils.Envelope{data=responses.{{.Name}}Response}
+// @Header 201 {string} Location "URL of the created {{.LowerName}}"
+// @Header 201 {string} ETag "Version of the {{.LowerName}}"
+// @Failure 400 {object} utils.Envelope
+// @Failure 409 {object} utils.Envelope
+// @Failure 422 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
 // @Router /{{.LowerName}}s [post]
 func (c *{{.Name}}Controller) Create{{.Name}}(ctx *gin.Context) {
 	var req requests.Create{{.Name}}Request
@@ -54,17 +65,20 @@ func (c *{{.Name}}Controller) Create{{.Name}}(ctx *gin.Context) {
 		return
 	}
 
-	{{.LowerName}}, err := c.{{.LowerName}}Service.Create{{.Name}}(&req)
+	{{.LowerName}}, err := c.{{.LowerName}}Service.Create{{.Name}}(ctx.Request.Context(), &req)
 	if err != nil {
+{{- if .Relations.Any}}
+		if errors.Is(err, services.Err{{.Name}}InvalidReference) {
+			utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid {{.LowerName}} reference", err)
+			return
+		}
+{{- end}}
 		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to create {{.LowerName}}", err)
 		return
 	}
 
-	response := utils.SuccessResponse{
-		Success: true,
-		Message: "{{.LowerName}} created successfully",
-	}
-	ctx.JSON(http.StatusCreated, response)
+	ctx.Header("ETag", utils.ETag({{.LowerName}}.Version))
+	utils.SendCreatedResponse(ctx, "{{.LowerName}} created successfully", {{.LowerName}}.ID, responses.To{{.Name}}Response({{.LowerName}}))
 }
 
 // Get{{.Name}}s godoc
@@ -73,36 +87,71 @@ func (c *{{.Name}}Controller) Create{{.Name}}(ctx *gin.Context) {
 // @Tags {{.LowerName}}s
 // @Accept json
 // @Produce json
+// @Produce application/msgpack
+// @Produce text/csv
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
-// @Success 200 {object} responses.{{.Name}}sListResponse
-// @Failure 500 {object} utils.ErrorResponse
+{{- if .Relations.Any}}
+// @Param include query string false "Comma-separated associations to include ({{range $i, $r := .Relations}}{{if $i}}, {{end}}{{$r.Include}}{{end}})"
+{{- end}}
+// @Param fields query string false "Comma-separated fields to return, e.g., id,name; all by default"
+// @Success 200 {object} utils.Envelope{data=[]responses.{{.Name}}Response}
+// @Failure 400 {object} utils.Envelope
+// @Failure 406 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
 // @Router /{{.LowerName}}s [get]
 func (c *{{.Name}}Controller) Get{{.Name}}s(ctx *gin.Context) {
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
 
-	{{.LowerName}}s, total, err := c.{{.LowerName}}Service.Get{{.Name}}s(page, limit)
+	fields, err := utils.ParseFields(ctx.Query("fields"), responses.{{.Name}}Response{})
 	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid fields", err)
+		return
+	}
+
+{{- if .Relations.Any}}
+	includes := utils.ParseCommaList(ctx.Query("include"))
+
+	{{.LowerName}}s, total, err := c.{{.LowerName}}Service.Get{{.Name}}s(ctx.Request.Context(), page, limit, fields, includes...)
+	if err != nil {
+		if errors.Is(err, services.Err{{.Name}}UnknownInclude) {
+			utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid include", err)
+			return
+		}
+{{- else}}
+	{{.LowerName}}s, total, err := c.{{.LowerName}}Service.Get{{.Name}}s(ctx.Request.Context(), page, limit, fields)
+	if err != nil {
+{{- end}}
 		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch {{.LowerName}}s", err)
 		return
 	}
 
 	response := responses.To{{.Name}}sListResponse({{.LowerName}}s, total, page, limit)
-	ctx.JSON(http.StatusOK, response)
+	utils.SendListResponse(ctx, utils.SelectFields(response.Data, fields), response.Pagination)
 }
 
 // Get{{.Name}} godoc
 // @Summary Get a {{.LowerName}} by ID
-// @Description Get a single {{.LowerName}} by its ID
+// @Description Get a single {{.LowerName}} by its ID; with If-None-Match and the ETag header, an unchanged {{.LowerName}} returns 304.
 // @Tags {{.LowerName}}s
 // @Accept json
 // @Produce json
+// @Produce application/msgpack
+// @Produce text/csv
 // @Param id path int true "{{.Name}} ID"
-// @Success 200 {object} responses.{{.Name}}Response
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 404 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+{{- if .Relations.Any}}
+// @Param include query string false "Comma-separated associations to include ({{range $i, $r := .Relations}}{{if $i}}, {{end}}{{$r.Include}}{{end}})"
+{{- end}}
+// @Param fields query string false "Comma-separated fields to return, e.g., id,name; all by default"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {object} utils.Envelope{data=responses.{{.Name}}Response}
+// @Header 200 {string} ETag "Version of the {{.LowerName}}"
+// @Success 304 "Not modified"
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 406 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
 // @Router /{{.LowerName}}s/{id} [get]
 func (c *{{.Name}}Controller) Get{{.Name}}(ctx *gin.Context) {
 	idParam := ctx.Param("id")
@@ -112,8 +161,30 @@ func (c *{{.Name}}Controller) Get{{.Name}}(ctx *gin.Context) {
 		return
 	}
 
-	{{.LowerName}}, err := c.{{.LowerName}}Service.Get{{.Name}}ByID(uint(id))
+	fields, err := utils.ParseFields(ctx.Query("fields"), responses.{{.Name}}Response{})
 	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid fields", err)
+		return
+	}
+
+	loadFields := fields
+	if len(fields) > 0 {
+		loadFields = append(fields[:len(fields):len(fields)], "version")
+	}
+
+{{- if .Relations.Any}}
+	includes := utils.ParseCommaList(ctx.Query("include"))
+
+	{{.LowerName}}, err := c.{{.LowerName}}Service.Get{{.Name}}ByID(ctx.Request.Context(), uint(id), loadFields, includes...)
+	if err != nil {
+		if errors.Is(err, services.Err{{.Name}}UnknownInclude) {
+			utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid include", err)
+			return
+		}
+{{- else}}
+	{{.LowerName}}, err := c.{{.LowerName}}Service.Get{{.Name}}ByID(ctx.Request.Context(), uint(id), loadFields)
+	if err != nil {
+{{- end}}
 		if err.Error() == "{{.LowerName}} not found" {
 			utils.SendErrorResponse(ctx, http.StatusNotFound, "{{.Name}} not found", err)
 			return
@@ -122,22 +193,34 @@ func (c *{{.Name}}Controller) Get{{.Name}}(ctx *gin.Context) {
 		return
 	}
 
+	etag := utils.ETag({{.LowerName}}.Version)
+	ctx.Header("ETag", etag)
+	if utils.MatchesIfNoneMatch(ctx.GetHeader("If-None-Match"), etag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
 	response := responses.To{{.Name}}Response({{.LowerName}})
-	ctx.JSON(http.StatusOK, response)
+	utils.SendDataResponse(ctx, http.StatusOK, utils.SelectFields(response, fields))
 }
 
 // Update{{.Name}} godoc
 // @Summary Update a {{.LowerName}}
-// @Description Update an existing {{.LowerName}} with the provided information
+// @Description Update an existing {{.LowerName}} with the provided information. The updated {{.LowerName}} is returned as data, unless the request sends Prefer: return=minimal. With If-Match, the {{.LowerName}} is only updated if nobody changed it since it was read. If-Match is required when the server sets REQUIRE_IF_MATCH.
 // @Tags {{.LowerName}}s
 // @Accept json
 // @Produce json
 // @Param id path int true "{{.Name}} ID"
+// @Param If-Match header string false "ETag the {{.LowerName}} must still have, from GET /{{.LowerName}}s/{id}"
 // @Param {{.LowerName}} body requests.Update{{.Name}}Request true "{{.Name}} update request"
-// @Success 200 {object} utils.SuccessResponse
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 404 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Param Prefer header string false "return=minimal to omit the {{.LowerName}} from the response"
+// @Success 200 {object} utils.Envelope{data=responses.{{.Name}}Response}
+// @Header 200 {string} ETag "New version of the {{.LowerName}}"
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 412 {object} utils.Envelope
+// @Failure 428 {object} utils.Envelope "If-Match is missing while REQUIRE_IF_MATCH is set"
+// @Failure 500 {object} utils.Envelope
 // @Router /{{.LowerName}}s/{id} [put]
 func (c *{{.Name}}Controller) Update{{.Name}}(ctx *gin.Context) {
 	idParam := ctx.Param("id")
@@ -147,39 +230,54 @@ func (c *{{.Name}}Controller) Update{{.Name}}(ctx *gin.Context) {
 		return
 	}
 
+	version, ok := parseIfMatch(ctx, "{{.Name}}")
+	if !ok {
+		return
+	}
+
 	var req requests.Update{{.Name}}Request
 	if !utils.BindAndValidate(ctx, &req) {
 		return
 	}
 
-	{{.LowerName}}, err := c.{{.LowerName}}Service.Update{{.Name}}(uint(id), &req)
+	{{.LowerName}}, err := c.{{.LowerName}}Service.Update{{.Name}}(ctx.Request.Context(), uint(id), version, &req)
 	if err != nil {
-		if err.Error() == "{{.LowerName}} not found" {
+		switch err.Error() {
+		case "{{.LowerName}} not found":
 			utils.SendErrorResponse(ctx, http.StatusNotFound, "{{.Name}} not found", err)
 			return
+		case "{{.LowerName}} version mismatch":
+			utils.SendErrorResponse(ctx, http.StatusPreconditionFailed, "{{.Name}} was modified by another request", err)
+			return
+		}
+{{- if .Relations.Any}}
+		if errors.Is(err, services.Err{{.Name}}InvalidReference) {
+			utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid {{.LowerName}} reference", err)
+			return
 		}
+{{- end}}
 		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to update {{.LowerName}}", err)
 		return
 	}
 
-	response := utils.SuccessResponse{
-		Success: true,
-		Message: "{{.LowerName}} updated successfully",
-	}
-	ctx.JSON(http.StatusOK, response)
+	ctx.Header("ETag", utils.ETag({{.LowerName}}.Version))
+	utils.SendWriteResponse(ctx, http.StatusOK, "{{.LowerName}} updated successfully", responses.To{{.Name}}Response({{.LowerName}}))
 }
 
 // Delete{{.Name}} godoc
 // @Summary Delete a {{.LowerName}}
-// @Description Delete a {{.LowerName}} by its ID
+// @Description Delete a {{.LowerName}} by its ID. With If-Match, the {{.LowerName}} is only deleted if nobody changed it since it was read. If-Match is required when the server sets REQUIRE_IF_MATCH.
 // @Tags {{.LowerName}}s
 // @Accept json
 // @Produce json
 // @Param id path int true "{{.Name}} ID"
-// @Success 200 {object} utils.SuccessResponse
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 404 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Param If-Match header string false "ETag the {{.LowerName}} must still have, from GET /{{.LowerName}}s/{id}"
+// @Success 200 {object} utils.Envelope
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 412 {object} utils.Envelope
+// @Failure 428 {object} utils.Envelope "If-Match is missing while REQUIRE_IF_MATCH is set"
+// @Failure 500 {object} utils.Envelope
 // @Router /{{.LowerName}}s/{id} [delete]
 func (c *{{.Name}}Controller) Delete{{.Name}}(ctx *gin.Context) {
 	idParam := ctx.Param("id")
@@ -189,49 +287,29 @@ func (c *{{.Name}}Controller) Delete{{.Name}}(ctx *gin.Context) {
 		return
 	}
 
-	err = c.{{.LowerName}}Service.Delete{{.Name}}(uint(id))
+	version, ok := parseIfMatch(ctx, "{{.Name}}")
+	if !ok {
+		return
+	}
+
+	err = c.{{.LowerName}}Service.Delete{{.Name}}(ctx.Request.Context(), uint(id), version)
 	if err != nil {
-		if err.Error() == "{{.LowerName}} not found" {
+		switch err.Error() {
+		case "{{.LowerName}} not found":
 			utils.SendErrorResponse(ctx, http.StatusNotFound, "{{.Name}} not found", err)
-			return
+		case "{{.LowerName}} version mismatch":
+			utils.SendErrorResponse(ctx, http.StatusPreconditionFailed, "{{.Name}} was modified by another request", err)
+		default:
+			utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete {{.LowerName}}", err)
 		}
-		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete {{.LowerName}}", err)
 		return
 	}
 
-	response := utils.SuccessResponse{
-		Success: true,
-		Message: "{{.LowerName}} deleted successfully",
-	}
-	ctx.JSON(http.StatusOK, response)
+	utils.SendSuccessResponse(ctx, http.StatusOK, "{{.LowerName}} deleted successfully", nil)
 }
 `
 
 // Generate creates the controller file
 func Generate(data GeneratorData) error {
-	// Create controllers directory if it doesn't exist
-	controllersDir := "controllers"
-	if err := os.MkdirAll(controllersDir, 0755); err != nil {
-		return fmt.Errorf("failed to create controllers directory: %v", err)
-	}
-
-	// Create controller file
-	controllerFile := filepath.Join(controllersDir, fmt.Sprintf("%s_controller.go", data.LowerName))
-	file, err := os.Create(controllerFile)
-	if err != nil {
-		return fmt.Errorf("failed to create controller file: %v", err)
-	}
-	defer file.Close()
-
-	// Parse and execute template
-	tmpl, err := template.New("controller").Parse(controllerTemplate)
-	if err != nil {
-		return fmt.Errorf("failed to parse controller template: %v", err)
-	}
-
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute controller template: %v", err)
-	}
-
-	return nil
+	return render.WriteFile(filepath.Join("controllers", fmt.Sprintf("%s_controller.go", data.LowerName)), "controller", controllerTemplate, data)
 }
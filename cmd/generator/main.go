@@ -1,27 +1,70 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"unicode"
 
+	"api-rentcar/cmd/docs/apidocs"
+	"api-rentcar/cmd/generator/action"
 	"api-rentcar/cmd/generator/controller"
+	"api-rentcar/cmd/generator/manifest"
 	"api-rentcar/cmd/generator/model"
 	"api-rentcar/cmd/generator/repository"
 	"api-rentcar/cmd/generator/request"
 	"api-rentcar/cmd/generator/response"
+	"api-rentcar/cmd/generator/schema"
 	"api-rentcar/cmd/generator/service"
+	"api-rentcar/cmd/generator/wiring"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run cmd/generator/main.go <EntityName>")
-		fmt.Println("Example: go run cmd/generator/main.go User")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "action":
+			generateAction(os.Args[2:])
+			return
+		case "remove":
+			removeEntity(os.Args[2:])
+			return
+		}
+	}
+
+	schemaPath := flag.String("schema", "", "path to a JSON schema describing the entity and its relations")
+	flag.Usage = usage
+	flag.Parse()
+
+	entity := &schema.Schema{}
+	if *schemaPath != "" {
+		loaded, err := schema.Load(*schemaPath)
+		if err != nil {
+			fmt.Printf("Error loading schema: %v\n", err)
+			os.Exit(1)
+		}
+		entity = loaded
+	}
+
+	// An explicit entity name takes precedence over the one in the schema
+	if flag.NArg() > 0 {
+		entity.Name = flag.Arg(0)
+	}
+	if entity.Name == "" {
+		usage()
 		os.Exit(1)
 	}
 
-	entityName := os.Args[1]
+	if err := entity.Normalize(); err != nil {
+		fmt.Printf("Error in schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	entityName := entity.Name
 	lowerName := strings.ToLower(entityName)
+	relations := entity.Relations
 
 	fmt.Printf("Generating files for entity: %s\n", entityName)
 	fmt.Println("========================================")
@@ -31,6 +74,7 @@ func main() {
 	controllerData := controller.GeneratorData{
 		Name:      entityName,
 		LowerName: lowerName,
+		Relations: relations,
 	}
 	if err := controller.Generate(controllerData); err != nil {
 		fmt.Printf("Error generating Controller: %v\n", err)
@@ -43,6 +87,7 @@ func main() {
 	repoData := repository.GeneratorData{
 		Name:      entityName,
 		LowerName: lowerName,
+		Relations: relations,
 	}
 	if err := repository.GenerateInterface(repoData); err != nil {
 		fmt.Printf("Error generating Repository Interface: %v\n", err)
@@ -63,6 +108,7 @@ func main() {
 	serviceData := service.GeneratorData{
 		Name:      entityName,
 		LowerName: lowerName,
+		Relations: relations,
 	}
 	if err := service.Generate(serviceData); err != nil {
 		fmt.Printf("Error generating Service: %v\n", err)
@@ -75,6 +121,7 @@ func main() {
 	requestData := request.GeneratorData{
 		Name:      entityName,
 		LowerName: lowerName,
+		Relations: relations,
 	}
 	if err := request.Generate(requestData); err != nil {
 		fmt.Printf("Error generating Request: %v\n", err)
@@ -87,6 +134,7 @@ func main() {
 	responseData := response.GeneratorData{
 		Name:      entityName,
 		LowerName: lowerName,
+		Relations: relations,
 	}
 	if err := response.Generate(responseData); err != nil {
 		fmt.Printf("Error generating Response: %v\n", err)
@@ -99,6 +147,7 @@ func main() {
 	modelData := model.GeneratorData{
 		Name:      entityName,
 		LowerName: lowerName,
+		Relations: relations,
 	}
 	if err := model.Generate(modelData); err != nil {
 		fmt.Printf("Error generating Model: %v\n", err)
@@ -106,15 +155,260 @@ func main() {
 	}
 	fmt.Println("✓ Model generated successfully")
 
+	// Wire the entity into the routes and migrations
+	fmt.Println("Wiring routes and migrations...")
+	files := entityFiles(lowerName)
+	wired, err := wiring.WireEntity(entityName, lowerName)
+	if err != nil {
+		fmt.Printf("! Could not wire %s: %v\n", entityName, err)
+		fmt.Println("  Register its controller, routes and migration manually")
+	} else {
+		fmt.Println("✓ Routes and migrations wired successfully")
+	}
+
+	// Record the generated files so they can be removed later
+	m, err := manifest.Load()
+	if err == nil {
+		err = m.Record(entityName, files, wired)
+	}
+	if err == nil {
+		err = m.Save()
+	}
+	if err != nil {
+		fmt.Printf("Error updating manifest: %v\n", err)
+		os.Exit(1)
+	}
+
+	regenerateDocs()
+
 	fmt.Println("\n========================================")
 	fmt.Println("All files generated successfully!")
 	fmt.Printf("\nGenerated files for entity '%s':\n", entityName)
-	fmt.Printf("- controllers/%s_controller.go\n", lowerName)
-	fmt.Printf("- repositories/%s/interface.go\n", lowerName)
-	fmt.Printf("- repositories/%s/repository.go\n", lowerName)
-	fmt.Printf("- services/%s_service.go\n", lowerName)
-	fmt.Printf("- requests/%s_request.go\n", lowerName)
-	fmt.Printf("- responses/%s_response.go\n", lowerName)
-	fmt.Printf("- models/%s.go\n", lowerName)
+	for _, file := range files {
+		fmt.Printf("- %s\n", file)
+	}
+	printRelationNotes(entityName, relations)
 	fmt.Println("\nYou can now use these files in your application!")
-}
\ No newline at end of file
+}
+
+// usage prints the command line help
+func usage() {
+	fmt.Println("Usage: go run cmd/generator/main.go [-schema <file>] <EntityName>")
+	fmt.Println("       go run cmd/generator/main.go action <EntityName> <ActionName>")
+	fmt.Println("       go run cmd/generator/main.go remove [-force] <EntityName>")
+	fmt.Println("Example: go run cmd/generator/main.go User")
+	fmt.Println("Example: go run cmd/generator/main.go -schema schemas/booking.json")
+	fmt.Println("Example: go run cmd/generator/main.go action Car Retire")
+	fmt.Println("Example: go run cmd/generator/main.go remove Booking")
+	fmt.Println("\nA schema file declares relations to other entities:")
+	fmt.Println(`  {"name": "Booking", "relations": [`)
+	fmt.Println(`    {"type": "belongs_to", "entity": "Car"},`)
+	fmt.Println(`    {"type": "has_many", "entity": "Payment"},`)
+	fmt.Println(`    {"type": "many_to_many", "entity": "Tag"}`)
+	fmt.Println(`  ]}`)
+}
+
+// printRelationNotes lists the follow-up work the generated relations depend on
+func printRelationNotes(entityName string, relations schema.Relations) {
+	if !relations.Any() {
+		return
+	}
+
+	fmt.Println("\nRelations:")
+	for _, rel := range relations {
+		fmt.Printf("- %s %s (?include=%s)\n", rel.Type, rel.Entity, rel.Include)
+
+		modelFile := filepath.Join("models", rel.LowerEntity+".go")
+		if _, err := os.Stat(modelFile); os.IsNotExist(err) {
+			fmt.Printf("  ! %s not found, generate %s before building\n", modelFile, rel.Entity)
+		}
+		if rel.Type == schema.HasMany {
+			fmt.Printf("  ! models.%s needs a %s uint field\n", rel.Entity, rel.ForeignKey)
+		}
+	}
+	fmt.Printf("Related models must be migrated before %s so its foreign keys can be created.\n", entityName)
+}
+
+// entityFiles returns the files the generator writes for an entity
+func entityFiles(lowerName string) []string {
+	return []string{
+		filepath.Join("controllers", lowerName+"_controller.go"),
+		filepath.Join("repositories", lowerName, lowerName+"_repository_interface.go"),
+		filepath.Join("repositories", lowerName, lowerName+"_repository.go"),
+		filepath.Join("services", lowerName+"_service.go"),
+		filepath.Join("requests", lowerName+".go"),
+		filepath.Join("responses", lowerName+".go"),
+		filepath.Join("models", lowerName+".go"),
+	}
+}
+
+// generateAction scaffolds a custom action such as POST /cars/:id/retire on an existing entity
+func generateAction(args []string) {
+	if len(args) != 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	entityName, actionName := args[0], args[1]
+	if !isIdentifier(entityName) || !isIdentifier(actionName) {
+		fmt.Println("Entity and action names must be Go identifiers, e.g., Car Retire")
+		os.Exit(1)
+	}
+	actionName = strings.ToUpper(actionName[:1]) + actionName[1:]
+
+	words := schema.ToSnake(actionName)
+	lowerAction := strings.ReplaceAll(words, "_", " ")
+	data := action.GeneratorData{
+		Name:        entityName,
+		LowerName:   strings.ToLower(entityName),
+		Action:      actionName,
+		LowerAction: lowerAction,
+		Title:       strings.ToUpper(lowerAction[:1]) + lowerAction[1:],
+		Path:        strings.ReplaceAll(words, "_", "-"),
+	}
+
+	fmt.Printf("Generating %s action for entity: %s\n", actionName, entityName)
+	fmt.Println("========================================")
+
+	if err := action.Generate(data); err != nil {
+		fmt.Printf("Error generating action: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("✓ Request, service method and controller handler generated successfully")
+
+	// Actions extend generated files, so keep the manifest hashes current
+	m, err := manifest.Load()
+	if err == nil {
+		err = m.Refresh(entityName, action.Files(data))
+	}
+	if err == nil {
+		err = m.Save()
+	}
+	if err != nil {
+		fmt.Printf("Error updating manifest: %v\n", err)
+		os.Exit(1)
+	}
+
+	route, err := action.GenerateRoute(data)
+	if err != nil {
+		fmt.Printf("! Could not register route: %v\n", err)
+		fmt.Printf("  Add it to routes/routes.go manually: %s\n", route)
+	} else {
+		fmt.Println("✓ Route registered successfully")
+	}
+
+	regenerateDocs()
+
+	fmt.Println("\n========================================")
+	fmt.Printf("POST /api/v1/%ss/:id/%s -> %sController.%s%s\n", data.LowerName, data.Path, entityName, actionName, entityName)
+	fmt.Printf("Implement the business rules in services/%s_service.go (%s%s)\n", data.LowerName, actionName, entityName)
+}
+
+// isIdentifier reports whether name is a valid Go identifier made of letters and digits
+func isIdentifier(name string) bool {
+	if name == "" || !unicode.IsLetter(rune(name[0])) {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// removeEntity deletes an entity's generated files and unwires its routes and migration
+func removeEntity(args []string) {
+	flags := flag.NewFlagSet("remove", flag.ExitOnError)
+	force := flags.Bool("force", false, "remove files even if they were modified since generation")
+	flags.Usage = usage
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		usage()
+		os.Exit(1)
+	}
+	entityName := flags.Arg(0)
+
+	m, err := manifest.Load()
+	if err != nil {
+		fmt.Printf("Error loading manifest: %v\n", err)
+		os.Exit(1)
+	}
+
+	entity, ok := m.Entities[entityName]
+	if !ok {
+		fmt.Printf("No generation record for %s in %s\n", entityName, manifest.Path)
+		os.Exit(1)
+	}
+
+	modified, err := entity.Modified()
+	if err != nil {
+		fmt.Printf("Error checking files: %v\n", err)
+		os.Exit(1)
+	}
+	if len(modified) > 0 && !*force {
+		fmt.Printf("Refusing to remove %s, these files were modified since generation:\n", entityName)
+		for _, file := range modified {
+			fmt.Printf("- %s\n", file)
+		}
+		fmt.Println("Run again with -force to remove them anyway")
+		os.Exit(1)
+	}
+
+	fmt.Printf("Removing entity: %s\n", entityName)
+	fmt.Println("========================================")
+
+	if entity.Wiring != nil {
+		if err := wiring.Unwire(entity.Wiring); err != nil {
+			fmt.Printf("Error unwiring routes and migrations: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("✓ Routes and migrations unwired")
+	}
+
+	files := make([]string, 0, len(entity.Files))
+	for file := range entity.Files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("Error removing %s: %v\n", file, err)
+			os.Exit(1)
+		}
+		dirs[filepath.Dir(file)] = true
+		fmt.Printf("- removed %s\n", file)
+	}
+
+	// Remove directories the generator created for the entity, such as repositories/<entity>
+	for dir := range dirs {
+		if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+			os.Remove(dir)
+		}
+	}
+
+	delete(m.Entities, entityName)
+	if err := m.Save(); err != nil {
+		fmt.Printf("Error updating manifest: %v\n", err)
+		os.Exit(1)
+	}
+
+	regenerateDocs()
+
+	fmt.Println("\n========================================")
+	fmt.Printf("%s removed successfully!\n", entityName)
+}
+
+// regenerateDocs brings the Swagger and OpenAPI docs in line with the generated annotations
+func regenerateDocs() {
+	fmt.Println("Regenerating API docs...")
+	if err := apidocs.Generate(apidocs.OutputDir); err != nil {
+		fmt.Printf("! Could not regenerate API docs: %v\n", err)
+		fmt.Println("  Fix the error and run: go run cmd/docs/main.go")
+		return
+	}
+	fmt.Println("✓ API docs regenerated successfully")
+}
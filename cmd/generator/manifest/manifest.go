@@ -0,0 +1,143 @@
+package manifest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"api-rentcar/cmd/generator/wiring"
+)
+
+// Path is where the manifest is stored, relative to the project root
+const Path = "cmd/generator/manifest.json"
+
+// Entity records what the generator produced for an entity
+type Entity struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Files       map[string]string `json:"files"` // path to SHA-256 of the content the generator wrote
+	Wiring      *wiring.Wiring    `json:"wiring,omitempty"`
+}
+
+// Manifest records every entity the generator has produced
+type Manifest struct {
+	Entities map[string]*Entity `json:"entities"`
+}
+
+// Load reads the manifest, returning an empty one if it doesn't exist yet
+func Load() (*Manifest, error) {
+	m := &Manifest{Entities: make(map[string]*Entity)}
+
+	content, err := os.ReadFile(Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %v", err)
+	}
+
+	if err := json.Unmarshal(content, m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %v", err)
+	}
+	if m.Entities == nil {
+		m.Entities = make(map[string]*Entity)
+	}
+
+	return m, nil
+}
+
+// Save writes the manifest
+func (m *Manifest) Save() error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(m); err != nil {
+		return fmt.Errorf("failed to encode manifest: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(Path), 0755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %v", err)
+	}
+	return os.WriteFile(Path, buf.Bytes(), 0644)
+}
+
+// Record stores the current hashes of an entity's generated files and its wiring
+func (m *Manifest) Record(name string, files []string, w *wiring.Wiring) error {
+	entity := &Entity{
+		GeneratedAt: time.Now().UTC(),
+		Files:       make(map[string]string, len(files)),
+		Wiring:      w,
+	}
+
+	for _, file := range files {
+		hash, err := HashFile(file)
+		if err != nil {
+			return err
+		}
+		entity.Files[filepath.ToSlash(file)] = hash
+	}
+
+	m.Entities[name] = entity
+	return nil
+}
+
+// Refresh updates the stored hashes of files the generator has just edited,
+// ignoring files the entity doesn't track
+func (m *Manifest) Refresh(name string, files []string) error {
+	entity, ok := m.Entities[name]
+	if !ok {
+		return nil
+	}
+
+	for _, file := range files {
+		key := filepath.ToSlash(file)
+		if _, tracked := entity.Files[key]; !tracked {
+			continue
+		}
+		hash, err := HashFile(file)
+		if err != nil {
+			return err
+		}
+		entity.Files[key] = hash
+	}
+
+	return nil
+}
+
+// Modified returns the tracked files of an entity whose content changed since
+// generation. Files that no longer exist are not reported.
+func (e *Entity) Modified() ([]string, error) {
+	var modified []string
+	for file, expected := range e.Files {
+		hash, err := HashFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hash != expected {
+			modified = append(modified, file)
+		}
+	}
+	sort.Strings(modified)
+	return modified, nil
+}
+
+// HashFile returns the hex-encoded SHA-256 of a file's content
+func HashFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
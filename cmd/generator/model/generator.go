@@ -2,14 +2,16 @@ package model
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
-	"text/template"
+
+	"api-rentcar/cmd/generator/render"
+	"api-rentcar/cmd/generator/schema"
 )
 
 type GeneratorData struct {
-	Name      string // e.g., "User"
-	LowerName string // e.g., "user"
+	Name      string           // e.g., "User"
+	LowerName string           // e.g., "user"
+	Relations schema.Relations // associations to other entities
 }
 
 const modelTemplate = `package models
@@ -37,6 +39,33 @@ type {{.Name}} struct {
 	// @Example "This is a sample {{.LowerName}} description"
 	Description string ` + "`gorm:\"type:text\" json:\"description\" validate:\"required,min=10,max=500\" example:\"This is a sample {{.LowerName}} description\"`" + `
 
+	// Version, incremented on every update for optimistic concurrency control
+	// @Description Version of the {{.LowerName}}, incremented on every update
+	// @Example 1
+	Version uint ` + "`gorm:\"not null;default:1\" json:\"version\" example:\"1\"`" + `
+{{- range .Relations.BelongsTo}}
+
+	// {{.Entity}} this {{$.LowerName}} belongs to
+	// @Description ID of the related {{.LowerEntity}}
+	// @Example 1
+	{{.ForeignKey}} uint ` + "`gorm:\"not null;index\" json:\"{{.ForeignKeyJSON}}\" example:\"1\"`" + `
+
+	// @Description Related {{.LowerEntity}}, loaded with ?include={{.Include}}
+	{{.Field}} *{{.Entity}} ` + "`gorm:\"foreignKey:{{.ForeignKey}};constraint:OnUpdate:CASCADE,OnDelete:RESTRICT\" json:\"{{.Include}},omitempty\"`" + `
+{{- end}}
+{{- range .Relations.HasMany}}
+
+	// {{.Field}} owned by this {{$.LowerName}}, requires a {{.ForeignKey}} field on {{.Entity}}
+	// @Description Related {{.LowerEntity}}s, loaded with ?include={{.Include}}
+	{{.Field}} []{{.Entity}} ` + "`gorm:\"foreignKey:{{.ForeignKey}}\" json:\"{{.Include}},omitempty\"`" + `
+{{- end}}
+{{- range .Relations.ManyToMany}}
+
+	// {{.Field}} linked to this {{$.LowerName}} through the {{.JoinTable}} table
+	// @Description Related {{.LowerEntity}}s, loaded with ?include={{.Include}}
+	{{.Field}} []{{.Entity}} ` + "`gorm:\"many2many:{{.JoinTable}}\" json:\"{{.Include}},omitempty\"`" + `
+{{- end}}
+
 	// Timestamps
 	// @Description Creation timestamp
 	// @Example "2023-01-01T00:00:00Z"
@@ -96,29 +125,5 @@ func ({{.LowerName}} *{{.Name}}) AfterDelete(tx *gorm.DB) error {
 
 // Generate creates the model file
 func Generate(data GeneratorData) error {
-	// Create models directory if it doesn't exist
-	modelsDir := "models"
-	if err := os.MkdirAll(modelsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create models directory: %v", err)
-	}
-
-	// Create model file
-	modelFile := filepath.Join(modelsDir, fmt.Sprintf("%s.go", data.LowerName))
-	file, err := os.Create(modelFile)
-	if err != nil {
-		return fmt.Errorf("failed to create model file: %v", err)
-	}
-	defer file.Close()
-
-	// Parse and execute template
-	tmpl, err := template.New("model").Parse(modelTemplate)
-	if err != nil {
-		return fmt.Errorf("failed to parse model template: %v", err)
-	}
-
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute model template: %v", err)
-	}
-
-	return nil
-}
\ No newline at end of file
+	return render.WriteFile(filepath.Join("models", fmt.Sprintf("%s.go", data.LowerName)), "model", modelTemplate, data)
+}
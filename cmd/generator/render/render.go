@@ -0,0 +1,42 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// WriteFile executes a template, formats the result as Go source and writes it to path.
+// kind names the generated file in error messages, e.g., "controller".
+func WriteFile(path, kind, text string, data any) error {
+	// Create parent directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %v", kind, err)
+	}
+
+	// Parse and execute template
+	tmpl, err := template.New(kind).Parse(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s template: %v", kind, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to execute %s template: %v", kind, err)
+	}
+
+	// Format the output so optional template sections don't leave misaligned code
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %v", kind, err)
+	}
+
+	if err := os.WriteFile(path, source, 0644); err != nil {
+		return fmt.Errorf("failed to create %s file: %v", kind, err)
+	}
+
+	return nil
+}
@@ -2,41 +2,64 @@ package repository
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
-	"text/template"
+
+	"api-rentcar/cmd/generator/render"
+	"api-rentcar/cmd/generator/schema"
 )
 
 type GeneratorData struct {
-	Name      string // e.g., "User"
-	LowerName string // e.g., "user"
+	Name      string           // e.g., "User"
+	LowerName string           // e.g., "user"
+	Relations schema.Relations // associations to other entities
 }
 
 const repositoryInterfaceTemplate = `package {{.LowerName}}
 
 import (
+	"context"
+
 	"api-rentcar/models"
 )
 
 // {{.Name}}RepositoryInterface defines the contract for {{.LowerName}} data operations
 type {{.Name}}RepositoryInterface interface {
-	Create({{.LowerName}} *models.{{.Name}}) error
-	GetByID(id uint) (*models.{{.Name}}, error)
-	GetAll(page, limit int) ([]models.{{.Name}}, int64, error)
-	Update({{.LowerName}} *models.{{.Name}}) error
-	Delete(id uint) error
-	Count() (int64, error)
-	ExistsByID(id uint) (bool, error)
+	Create(ctx context.Context, {{.LowerName}} *models.{{.Name}}) error
+{{- if .Relations.Any}}
+	GetByID(ctx context.Context, id uint, fields []string, includes ...string) (*models.{{.Name}}, error)
+	GetAll(ctx context.Context, page, limit int, fields []string, includes ...string) ([]models.{{.Name}}, int64, error)
+{{- else}}
+	GetByID(ctx context.Context, id uint, fields []string) (*models.{{.Name}}, error)
+	GetAll(ctx context.Context, page, limit int, fields []string) ([]models.{{.Name}}, int64, error)
+{{- end}}
+	Update(ctx context.Context, {{.LowerName}} *models.{{.Name}}) error
+	Delete(ctx context.Context, id uint, version uint) error
+	Count(ctx context.Context) (int64, error)
+	ExistsByID(ctx context.Context, id uint) (bool, error)
+{{- range .Relations.BelongsTo}}
+	{{.Field}}Exists(ctx context.Context, id uint) (bool, error)
+{{- end}}
+{{- range .Relations.ManyToMany}}
+	Find{{.Field}}ByIDs(ctx context.Context, ids []uint) ([]models.{{.Entity}}, error)
+	Replace{{.Field}}(ctx context.Context, {{$.LowerName}} *models.{{$.Name}}, {{.Var}} []models.{{.Entity}}) error
+{{- end}}
 }
 `
 
 const repositoryImplementationTemplate = `package {{.LowerName}}
 
 import (
+	"context"
+	"errors"
+
 	"api-rentcar/models"
+	"api-rentcar/repositories"
 	"gorm.io/gorm"
 )
 
+// ErrVersionConflict is returned when a {{.LowerName}} changed since it was read
+var ErrVersionConflict = errors.New("{{.LowerName}} version conflict")
+
 // {{.Name}}Repository implements {{.Name}}RepositoryInterface
 type {{.Name}}Repository struct {
 	db *gorm.DB
@@ -50,27 +73,30 @@ func New{{.Name}}Repository(db *gorm.DB) {{.Name}}RepositoryInterface {
 }
 
 // Create creates a new {{.LowerName}} in the database
-func (r *{{.Name}}Repository) Create({{.LowerName}} *models.{{.Name}}) error {
-	return r.db.Create({{.LowerName}}).Error
+func (r *{{.Name}}Repository) Create(ctx context.Context, {{.LowerName}} *models.{{.Name}}) error {
+	return r.db.WithContext(ctx).Create({{.LowerName}}).Error
 }
 
-// GetByID retrieves a {{.LowerName}} by its ID
-func (r *{{.Name}}Repository) GetByID(id uint) (*models.{{.Name}}, error) {
+{{- if not .Relations.Any}}
+// GetByID retrieves a {{.LowerName}} by its ID, loading only the given JSON
+// fields unless fields is empty
+func (r *{{.Name}}Repository) GetByID(ctx context.Context, id uint, fields []string) (*models.{{.Name}}, error) {
 	var {{.LowerName}} models.{{.Name}}
-	err := r.db.First(&{{.LowerName}}, id).Error
+	err := r.db.WithContext(ctx).Scopes(repositories.SelectFields(fields)).First(&{{.LowerName}}, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &{{.LowerName}}, nil
 }
 
-// GetAll retrieves all {{.LowerName}}s with pagination
-func (r *{{.Name}}Repository) GetAll(page, limit int) ([]models.{{.Name}}, int64, error) {
+// GetAll retrieves all {{.LowerName}}s with pagination, loading only the given
+// JSON fields unless fields is empty
+func (r *{{.Name}}Repository) GetAll(ctx context.Context, page, limit int, fields []string) ([]models.{{.Name}}, int64, error) {
 	var {{.LowerName}}s []models.{{.Name}}
 	var total int64
 
 	// Count total records
-	if err := r.db.Model(&models.{{.Name}}{}).Count(&total).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&models.{{.Name}}{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -78,93 +104,148 @@ func (r *{{.Name}}Repository) GetAll(page, limit int) ([]models.{{.Name}}, int64
 	offset := (page - 1) * limit
 
 	// Get paginated results
-	err := r.db.Offset(offset).Limit(limit).Find(&{{.LowerName}}s).Error
+	err := r.db.WithContext(ctx).Scopes(repositories.SelectFields(fields)).Order("id").Offset(offset).Limit(limit).Find(&{{.LowerName}}s).Error
 	if err != nil {
 		return nil, 0, err
 	}
 
 	return {{.LowerName}}s, total, nil
 }
+{{- else}}
+// GetByID retrieves a {{.LowerName}} by its ID, loading only the given JSON
+// fields unless fields is empty and preloading the given associations
+func (r *{{.Name}}Repository) GetByID(ctx context.Context, id uint, fields []string, includes ...string) (*models.{{.Name}}, error) {
+	var {{.LowerName}} models.{{.Name}}
+	err := preload(r.db.WithContext(ctx), includes).Scopes(repositories.SelectFields(fields)).First(&{{.LowerName}}, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &{{.LowerName}}, nil
+}
+
+// GetAll retrieves all {{.LowerName}}s with pagination, loading only the given
+// JSON fields unless fields is empty and preloading the given associations
+func (r *{{.Name}}Repository) GetAll(ctx context.Context, page, limit int, fields []string, includes ...string) ([]models.{{.Name}}, int64, error) {
+	var {{.LowerName}}s []models.{{.Name}}
+	var total int64
+
+	// Count total records
+	if err := r.db.WithContext(ctx).Model(&models.{{.Name}}{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
 
-// Update updates an existing {{.LowerName}}
-func (r *{{.Name}}Repository) Update({{.LowerName}} *models.{{.Name}}) error {
-	return r.db.Save({{.LowerName}}).Error
+	// Get paginated results
+	err := preload(r.db.WithContext(ctx), includes).Scopes(repositories.SelectFields(fields)).Order("id").Offset(offset).Limit(limit).Find(&{{.LowerName}}s).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return {{.LowerName}}s, total, nil
+}
+{{- end}}
+
+// Update updates an existing {{.LowerName}} and increments its version. It
+// returns ErrVersionConflict when the {{.LowerName}} is no longer at the version
+// it was read at.
+func (r *{{.Name}}Repository) Update(ctx context.Context, {{.LowerName}} *models.{{.Name}}) error {
+	version := {{.LowerName}}.Version
+	{{.LowerName}}.Version++
+
+	result := r.db.WithContext(ctx).Model({{.LowerName}}).
+		Where("version = ?", version).
+		Select("*").
+		Omit("id", "created_at").
+		Updates({{.LowerName}})
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		{{.LowerName}}.Version = version
+	}
+	return result.Error
 }
 
-// Delete deletes a {{.LowerName}} by its ID
-func (r *{{.Name}}Repository) Delete(id uint) error {
-	return r.db.Delete(&models.{{.Name}}{}, id).Error
+// Delete deletes a {{.LowerName}} by its ID. When version isn't zero, the
+// {{.LowerName}} is only deleted at that version, and ErrVersionConflict is
+// returned otherwise.
+func (r *{{.Name}}Repository) Delete(ctx context.Context, id uint, version uint) error {
+	query := r.db.WithContext(ctx)
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+
+	result := query.Delete(&models.{{.Name}}{}, id)
+	if result.Error == nil && version != 0 && result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return result.Error
 }
 
 // Count returns the total number of {{.LowerName}}s
-func (r *{{.Name}}Repository) Count() (int64, error) {
+func (r *{{.Name}}Repository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.{{.Name}}{}).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&models.{{.Name}}{}).Count(&count).Error
 	return count, err
 }
 
 // ExistsByID checks if a {{.LowerName}} exists by its ID
-func (r *{{.Name}}Repository) ExistsByID(id uint) (bool, error) {
+func (r *{{.Name}}Repository) ExistsByID(ctx context.Context, id uint) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.{{.Name}}{}).Where("id = ?", id).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&models.{{.Name}}{}).Where("id = ?", id).Count(&count).Error
 	return count > 0, err
 }
-`
-
-// GenerateInterface creates the repository interface file
-func GenerateInterface(data GeneratorData) error {
-	// Create repository directory if it doesn't exist
-	repoDir := filepath.Join("repositories", data.LowerName)
-	if err := os.MkdirAll(repoDir, 0755); err != nil {
-		return fmt.Errorf("failed to create repository directory: %v", err)
-	}
+{{- range .Relations.BelongsTo}}
 
-	// Create repository interface file
-	interfaceFile := filepath.Join(repoDir, fmt.Sprintf("%s_repository_interface.go", data.LowerName))
-	file, err := os.Create(interfaceFile)
-	if err != nil {
-		return fmt.Errorf("failed to create repository interface file: %v", err)
+// {{.Field}}Exists checks if the referenced {{.LowerEntity}} exists
+func (r *{{$.Name}}Repository) {{.Field}}Exists(ctx context.Context, id uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.{{.Entity}}{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+{{- end}}
+{{- range .Relations.ManyToMany}}
+
+// Find{{.Field}}ByIDs retrieves the {{.LowerEntity}}s with the given IDs
+func (r *{{$.Name}}Repository) Find{{.Field}}ByIDs(ctx context.Context, ids []uint) ([]models.{{.Entity}}, error) {
+	var {{.Var}} []models.{{.Entity}}
+	if len(ids) == 0 {
+		return {{.Var}}, nil
 	}
-	defer file.Close()
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&{{.Var}}).Error
+	return {{.Var}}, err
+}
 
-	// Parse and execute template
-	tmpl, err := template.New("repositoryInterface").Parse(repositoryInterfaceTemplate)
-	if err != nil {
-		return fmt.Errorf("failed to parse repository interface template: %v", err)
+// Replace{{.Field}} replaces the {{.LowerEntity}}s linked to a {{$.LowerName}}
+func (r *{{$.Name}}Repository) Replace{{.Field}}(ctx context.Context, {{$.LowerName}} *models.{{$.Name}}, {{.Var}} []models.{{.Entity}}) error {
+	if len({{.Var}}) == 0 {
+		return r.db.WithContext(ctx).Model({{$.LowerName}}).Association("{{.Field}}").Clear()
 	}
+	return r.db.WithContext(ctx).Model({{$.LowerName}}).Association("{{.Field}}").Replace({{.Var}})
+}
+{{- end}}
+{{- if .Relations.Any}}
 
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute repository interface template: %v", err)
+// preload applies the requested associations to a query
+func preload(db *gorm.DB, includes []string) *gorm.DB {
+	for _, include := range includes {
+		db = db.Preload(include)
 	}
+	return db
+}
+{{- end}}
+`
 
-	return nil
+// GenerateInterface creates the repository interface file
+func GenerateInterface(data GeneratorData) error {
+	interfaceFile := filepath.Join("repositories", data.LowerName, fmt.Sprintf("%s_repository_interface.go", data.LowerName))
+	return render.WriteFile(interfaceFile, "repository interface", repositoryInterfaceTemplate, data)
 }
 
 // GenerateImplementation creates the repository implementation file
 func GenerateImplementation(data GeneratorData) error {
-	// Create repository directory if it doesn't exist
-	repoDir := filepath.Join("repositories", data.LowerName)
-	if err := os.MkdirAll(repoDir, 0755); err != nil {
-		return fmt.Errorf("failed to create repository directory: %v", err)
-	}
-
-	// Create repository implementation file
-	implFile := filepath.Join(repoDir, fmt.Sprintf("%s_repository.go", data.LowerName))
-	file, err := os.Create(implFile)
-	if err != nil {
-		return fmt.Errorf("failed to create repository implementation file: %v", err)
-	}
-	defer file.Close()
-
-	// Parse and execute template
-	tmpl, err := template.New("repositoryImplementation").Parse(repositoryImplementationTemplate)
-	if err != nil {
-		return fmt.Errorf("failed to parse repository implementation template: %v", err)
-	}
-
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute repository implementation template: %v", err)
-	}
-
-	return nil
-}
\ No newline at end of file
+	implFile := filepath.Join("repositories", data.LowerName, fmt.Sprintf("%s_repository.go", data.LowerName))
+	return render.WriteFile(implFile, "repository implementation", repositoryImplementationTemplate, data)
+}
@@ -2,14 +2,16 @@ package request
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
-	"text/template"
+
+	"api-rentcar/cmd/generator/render"
+	"api-rentcar/cmd/generator/schema"
 )
 
 type GeneratorData struct {
-	Name      string // e.g., "User"
-	LowerName string // e.g., "user"
+	Name      string           // e.g., "User"
+	LowerName string           // e.g., "user"
+	Relations schema.Relations // associations to other entities
 }
 
 const requestTemplate = `package requests
@@ -30,6 +32,20 @@ type Create{{.Name}}Request struct {
 	// @Description Description of the {{.LowerName}}
 	// @Example "This is a sample {{.LowerName}} description"
 	Description string ` + "`json:\"description\" validate:\"required,min=10,max=500\" example:\"This is a sample {{.LowerName}} description\"`" + `
+{{- range .Relations.BelongsTo}}
+
+	// ID of the {{.LowerEntity}} this {{$.LowerName}} belongs to
+	// @Description ID of the related {{.LowerEntity}}
+	// @Example 1
+	{{.ForeignKey}} uint ` + "`json:\"{{.ForeignKeyJSON}}\" validate:\"required,gt=0\" example:\"1\"`" + `
+{{- end}}
+{{- range .Relations.ManyToMany}}
+
+	// IDs of the {{.LowerEntity}}s linked to this {{$.LowerName}}
+	// @Description IDs of the related {{.LowerEntity}}s
+	// @Example [1,2]
+	{{.IDsField}} []uint ` + "`json:\"{{.IDsJSON}},omitempty\" validate:\"omitempty,dive,gt=0\" example:\"1,2\"`" + `
+{{- end}}
 
 	// Add other fields as needed
 }
@@ -52,6 +68,20 @@ type Update{{.Name}}Request struct {
 	// @Description Description of the {{.LowerName}}
 	// @Example "This is an updated {{.LowerName}} description"
 	Description *string ` + "`json:\"description,omitempty\" validate:\"omitempty,min=10,max=500\" example:\"This is an updated {{.LowerName}} description\"`" + `
+{{- range .Relations.BelongsTo}}
+
+	// ID of the {{.LowerEntity}} this {{$.LowerName}} belongs to
+	// @Description ID of the related {{.LowerEntity}}
+	// @Example 1
+	{{.ForeignKey}} *uint ` + "`json:\"{{.ForeignKeyJSON}},omitempty\" validate:\"omitempty,gt=0\" example:\"1\"`" + `
+{{- end}}
+{{- range .Relations.ManyToMany}}
+
+	// IDs of the {{.LowerEntity}}s linked to this {{$.LowerName}}, replaces the current set when present
+	// @Description IDs of the related {{.LowerEntity}}s
+	// @Example [1,2]
+	{{.IDsField}} *[]uint ` + "`json:\"{{.IDsJSON}},omitempty\" validate:\"omitempty\" example:\"1,2\"`" + `
+{{- end}}
 
 	// Add other fields as needed
 }
@@ -65,29 +95,5 @@ func (r *Update{{.Name}}Request) Validate() error {
 
 // Generate creates the request file
 func Generate(data GeneratorData) error {
-	// Create requests directory if it doesn't exist
-	requestsDir := "requests"
-	if err := os.MkdirAll(requestsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create requests directory: %v", err)
-	}
-
-	// Create request file
-	requestFile := filepath.Join(requestsDir, fmt.Sprintf("%s.go", data.LowerName))
-	file, err := os.Create(requestFile)
-	if err != nil {
-		return fmt.Errorf("failed to create request file: %v", err)
-	}
-	defer file.Close()
-
-	// Parse and execute template
-	tmpl, err := template.New("request").Parse(requestTemplate)
-	if err != nil {
-		return fmt.Errorf("failed to parse request template: %v", err)
-	}
-
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute request template: %v", err)
-	}
-
-	return nil
-}
\ No newline at end of file
+	return render.WriteFile(filepath.Join("requests", fmt.Sprintf("%s.go", data.LowerName)), "request", requestTemplate, data)
+}
@@ -2,14 +2,16 @@ package response
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
-	"text/template"
+
+	"api-rentcar/cmd/generator/render"
+	"api-rentcar/cmd/generator/schema"
 )
 
 type GeneratorData struct {
-	Name      string // e.g., "User"
-	LowerName string // e.g., "user"
+	Name      string           // e.g., "User"
+	LowerName string           // e.g., "user"
+	Relations schema.Relations // associations to other entities
 }
 
 const responseTemplate = `package responses
@@ -38,6 +40,34 @@ type {{.Name}}Response struct {
 	// @Example "This is a sample {{.LowerName}} description"
 	Description string ` + "`json:\"description\" example:\"This is a sample {{.LowerName}} description\"`" + `
 
+	// Version
+	// @Description Version of the {{.LowerName}}, also sent as its ETag
+	// @Example 1
+	Version uint ` + "`json:\"version\" example:\"1\"`" + `
+{{- range .Relations.BelongsTo}}
+
+	// ID of the related {{.LowerEntity}}
+	// @Description ID of the related {{.LowerEntity}}
+	// @Example 1
+	{{.ForeignKey}} uint ` + "`json:\"{{.ForeignKeyJSON}}\" example:\"1\"`" + `
+
+	// Related {{.LowerEntity}}
+	// @Description Related {{.LowerEntity}}, present with ?include={{.Include}}
+	{{.Field}} *{{.Entity}}Response ` + "`json:\"{{.Include}},omitempty\"`" + `
+{{- end}}
+{{- range .Relations.HasMany}}
+
+	// Related {{.LowerEntity}}s
+	// @Description Related {{.LowerEntity}}s, present with ?include={{.Include}}
+	{{.Field}} []{{.Entity}}Response ` + "`json:\"{{.Include}},omitempty\"`" + `
+{{- end}}
+{{- range .Relations.ManyToMany}}
+
+	// Related {{.LowerEntity}}s
+	// @Description Related {{.LowerEntity}}s, present with ?include={{.Include}}
+	{{.Field}} []{{.Entity}}Response ` + "`json:\"{{.Include}},omitempty\"`" + `
+{{- end}}
+
 	// Creation timestamp
 	// @Description Creation timestamp
 	// @Example "2023-01-01T00:00:00Z"
@@ -65,14 +95,51 @@ type {{.Name}}sListResponse struct {
 
 // To{{.Name}}Response converts a {{.Name}} model to {{.Name}}Response
 func To{{.Name}}Response({{.LowerName}} *models.{{.Name}}) {{.Name}}Response {
+{{- if not .Relations.Any}}
 	return {{.Name}}Response{
 		ID:          {{.LowerName}}.ID,
 		Name:        {{.LowerName}}.Name,
 		Description: {{.LowerName}}.Description,
+		Version:     {{.LowerName}}.Version,
 		CreatedAt:   {{.LowerName}}.CreatedAt,
 		UpdatedAt:   {{.LowerName}}.UpdatedAt,
 		// Add other field mappings as needed
 	}
+{{- else}}
+	response := {{.Name}}Response{
+		ID:          {{.LowerName}}.ID,
+		Name:        {{.LowerName}}.Name,
+		Description: {{.LowerName}}.Description,
+{{- range .Relations.BelongsTo}}
+		{{.ForeignKey}}: {{$.LowerName}}.{{.ForeignKey}},
+{{- end}}
+		CreatedAt:   {{.LowerName}}.CreatedAt,
+		UpdatedAt:   {{.LowerName}}.UpdatedAt,
+		// Add other field mappings as needed
+	}
+{{- range .Relations.BelongsTo}}
+
+	// Nested {{.LowerEntity}} is only present when it was preloaded
+	if {{$.LowerName}}.{{.Field}} != nil {
+		nested := To{{.Entity}}Response({{$.LowerName}}.{{.Field}})
+		response.{{.Field}} = &nested
+	}
+{{- end}}
+{{- range .Relations.HasMany}}
+
+	for i := range {{$.LowerName}}.{{.Field}} {
+		response.{{.Field}} = append(response.{{.Field}}, To{{.Entity}}Response(&{{$.LowerName}}.{{.Field}}[i]))
+	}
+{{- end}}
+{{- range .Relations.ManyToMany}}
+
+	for i := range {{$.LowerName}}.{{.Field}} {
+		response.{{.Field}} = append(response.{{.Field}}, To{{.Entity}}Response(&{{$.LowerName}}.{{.Field}}[i]))
+	}
+{{- end}}
+
+	return response
+{{- end}}
 }
 
 // To{{.Name}}sListResponse converts a slice of {{.Name}} models to {{.Name}}sListResponse with pagination
@@ -91,29 +158,5 @@ func To{{.Name}}sListResponse({{.LowerName}}s []models.{{.Name}}, total int64, p
 
 // Generate creates the response file
 func Generate(data GeneratorData) error {
-	// Create responses directory if it doesn't exist
-	responsesDir := "responses"
-	if err := os.MkdirAll(responsesDir, 0755); err != nil {
-		return fmt.Errorf("failed to create responses directory: %v", err)
-	}
-
-	// Create response file
-	responseFile := filepath.Join(responsesDir, fmt.Sprintf("%s.go", data.LowerName))
-	file, err := os.Create(responseFile)
-	if err != nil {
-		return fmt.Errorf("failed to create response file: %v", err)
-	}
-	defer file.Close()
-
-	// Parse and execute template
-	tmpl, err := template.New("response").Parse(responseTemplate)
-	if err != nil {
-		return fmt.Errorf("failed to parse response template: %v", err)
-	}
-
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute response template: %v", err)
-	}
-
-	return nil
-}
\ No newline at end of file
+	return render.WriteFile(filepath.Join("responses", fmt.Sprintf("%s.go", data.LowerName)), "response", responseTemplate, data)
+}
@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// RelationType is the kind of association between two entities
+type RelationType string
+
+const (
+	BelongsTo  RelationType = "belongs_to"
+	HasMany    RelationType = "has_many"
+	ManyToMany RelationType = "many_to_many"
+)
+
+// Relation describes an association from the generated entity to another entity
+type Relation struct {
+	Type      RelationType `json:"type"`                 // belongs_to, has_many or many_to_many
+	Entity    string       `json:"entity"`               // e.g., "Car"
+	Field     string       `json:"field,omitempty"`      // association field, e.g., "Car" or "Tags"
+	JoinTable string       `json:"join_table,omitempty"` // many_to_many only, e.g., "booking_tags"
+
+	// Derived values filled in by Normalize
+	LowerEntity    string `json:"-"` // e.g., "car"
+	Var            string `json:"-"` // variable name for the association, e.g., "car" or "tags"
+	Include        string `json:"-"` // ?include= name and JSON key, e.g., "car" or "tags"
+	ForeignKey     string `json:"-"` // e.g., "CarID" (belongs_to) or "BookingID" (has_many)
+	ForeignKeyJSON string `json:"-"` // e.g., "car_id"
+	IDsField       string `json:"-"` // many_to_many only, e.g., "TagIDs"
+	IDsJSON        string `json:"-"` // many_to_many only, e.g., "tag_ids"
+}
+
+// Relations is a list of relations with helpers for use in templates
+type Relations []Relation
+
+// Schema describes an entity to generate
+type Schema struct {
+	Name      string    `json:"name"` // e.g., "Booking"
+	Relations Relations `json:"relations,omitempty"`
+}
+
+// Load reads a JSON schema file
+func Load(path string) (*Schema, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %v", err)
+	}
+
+	var s Schema
+	if err := json.Unmarshal(content, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse schema file: %v", err)
+	}
+
+	return &s, nil
+}
+
+// Normalize validates the schema and fills in derived relation values
+func (s *Schema) Normalize() error {
+	if s.Name == "" {
+		return fmt.Errorf("schema has no entity name")
+	}
+
+	seen := make(map[string]bool)
+	for i := range s.Relations {
+		rel := &s.Relations[i]
+		if rel.Entity == "" {
+			return fmt.Errorf("relation %d has no entity", i)
+		}
+
+		rel.LowerEntity = strings.ToLower(rel.Entity)
+
+		switch rel.Type {
+		case BelongsTo:
+			if rel.Field == "" {
+				rel.Field = rel.Entity
+			}
+			rel.ForeignKey = rel.Field + "ID"
+		case HasMany:
+			if rel.Field == "" {
+				rel.Field = rel.Entity + "s"
+			}
+			rel.ForeignKey = s.Name + "ID"
+		case ManyToMany:
+			if rel.Field == "" {
+				rel.Field = rel.Entity + "s"
+			}
+			if rel.JoinTable == "" {
+				rel.JoinTable = strings.ToLower(s.Name) + "_" + rel.LowerEntity + "s"
+			}
+			rel.IDsField = rel.Entity + "IDs"
+			rel.IDsJSON = rel.LowerEntity + "_ids"
+		default:
+			return fmt.Errorf("relation %s has unsupported type %q (use belongs_to, has_many or many_to_many)", rel.Entity, rel.Type)
+		}
+
+		rel.Var = strings.ToLower(rel.Field[:1]) + rel.Field[1:]
+		rel.Include = ToSnake(rel.Field)
+		if rel.ForeignKey != "" {
+			rel.ForeignKeyJSON = ToSnake(rel.ForeignKey)
+		}
+
+		if seen[rel.Field] {
+			return fmt.Errorf("duplicate relation field %s", rel.Field)
+		}
+		seen[rel.Field] = true
+	}
+
+	return nil
+}
+
+// Any reports whether there are any relations
+func (r Relations) Any() bool {
+	return len(r) > 0
+}
+
+// BelongsTo returns the belongs_to relations
+func (r Relations) BelongsTo() Relations {
+	return r.filter(BelongsTo)
+}
+
+// HasMany returns the has_many relations
+func (r Relations) HasMany() Relations {
+	return r.filter(HasMany)
+}
+
+// ManyToMany returns the many_to_many relations
+func (r Relations) ManyToMany() Relations {
+	return r.filter(ManyToMany)
+}
+
+func (r Relations) filter(t RelationType) Relations {
+	var result Relations
+	for _, rel := range r {
+		if rel.Type == t {
+			result = append(result, rel)
+		}
+	}
+	return result
+}
+
+// ToSnake converts a Go identifier to snake_case, e.g., "CarID" to "car_id"
+func ToSnake(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word unless we're inside an acronym such as "ID"
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -2,37 +2,64 @@ package service
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
-	"text/template"
+
+	"api-rentcar/cmd/generator/render"
+	"api-rentcar/cmd/generator/schema"
 )
 
 type GeneratorData struct {
-	Name      string // e.g., "User"
-	LowerName string // e.g., "user"
+	Name      string           // e.g., "User"
+	LowerName string           // e.g., "user"
+	Relations schema.Relations // associations to other entities
 }
 
 const serviceTemplate = `package services
 
 import (
+	"context"
 	"errors"
+{{- if .Relations.Any}}
+	"fmt"
+{{- end}}
 	"api-rentcar/models"
 	requests "api-rentcar/requests"
 	{{.LowerName}}Repo "api-rentcar/repositories/{{.LowerName}}"
+	"api-rentcar/telemetry"
 	"api-rentcar/utils"
 	"gorm.io/gorm"
 )
 
 // {{.Name}}ServiceInterface defines the contract for {{.LowerName}} business logic
 type {{.Name}}ServiceInterface interface {
-	Create{{.Name}}(req *requests.Create{{.Name}}Request) (*models.{{.Name}}, error)
-	Get{{.Name}}ByID(id uint) (*models.{{.Name}}, error)
-	Get{{.Name}}s(page, limit int) ([]models.{{.Name}}, int64, error)
-	Update{{.Name}}(id uint, req *requests.Update{{.Name}}Request) (*models.{{.Name}}, error)
-	Delete{{.Name}}(id uint) error
-	Get{{.Name}}Stats() (map[string]interface{}, error)
+	Create{{.Name}}(ctx context.Context, req *requests.Create{{.Name}}Request) (*models.{{.Name}}, error)
+{{- if .Relations.Any}}
+	Get{{.Name}}ByID(ctx context.Context, id uint, fields []string, includes ...string) (*models.{{.Name}}, error)
+	Get{{.Name}}s(ctx context.Context, page, limit int, fields []string, includes ...string) ([]models.{{.Name}}, int64, error)
+{{- else}}
+	Get{{.Name}}ByID(ctx context.Context, id uint, fields []string) (*models.{{.Name}}, error)
+	Get{{.Name}}s(ctx context.Context, page, limit int, fields []string) ([]models.{{.Name}}, int64, error)
+{{- end}}
+	Update{{.Name}}(ctx context.Context, id uint, version uint, req *requests.Update{{.Name}}Request) (*models.{{.Name}}, error)
+	Delete{{.Name}}(ctx context.Context, id uint, version uint) error
+	Get{{.Name}}Stats(ctx context.Context) (map[string]interface{}, error)
+}
+
+{{- if .Relations.Any}}
+// Err{{.Name}}InvalidReference is returned when a request references a related entity that does not exist
+var Err{{.Name}}InvalidReference = errors.New("invalid {{.LowerName}} reference")
+
+// Err{{.Name}}UnknownInclude is returned when ?include= names an association that cannot be preloaded
+var Err{{.Name}}UnknownInclude = errors.New("unknown include")
+
+// {{.LowerName}}Includes maps ?include= values to the associations they preload
+var {{.LowerName}}Includes = map[string]string{
+{{- range .Relations}}
+	"{{.Include}}": "{{.Field}}",
+{{- end}}
 }
 
+{{end -}}
 // {{.Name}}Service implements {{.Name}}ServiceInterface
 type {{.Name}}Service struct {
 	{{.LowerName}}Repo {{.LowerName}}Repo.{{.Name}}RepositoryInterface
@@ -46,26 +73,66 @@ func New{{.Name}}Service({{.LowerName}}Repo {{.LowerName}}Repo.{{.Name}}Reposito
 }
 
 // Create{{.Name}} creates a new {{.LowerName}} with business logic validation
-func (s *{{.Name}}Service) Create{{.Name}}(req *requests.Create{{.Name}}Request) (*models.{{.Name}}, error) {
+func (s *{{.Name}}Service) Create{{.Name}}(ctx context.Context, req *requests.Create{{.Name}}Request) (*models.{{.Name}}, error) {
+	ctx, span := telemetry.StartSpan(ctx, "{{.Name}}Service.Create{{.Name}}")
+	defer span.End()
+
 	{{.LowerName}} := &models.{{.Name}}{}
 	
 	// Use reflection-based field mapping for automatic assignment
 	utils.MapFields(req, {{.LowerName}})
+{{- range .Relations.BelongsTo}}
+
+	if err := s.validate{{.Field}}(ctx, req.{{.ForeignKey}}); err != nil {
+		return nil, err
+	}
+{{- end}}
+{{- range .Relations.ManyToMany}}
 
-	if err := s.{{.LowerName}}Repo.Create({{.LowerName}}); err != nil {
+	{{.Var}}, err := s.resolve{{.Field}}(ctx, req.{{.IDsField}})
+	if err != nil {
+		return nil, err
+	}
+	{{$.LowerName}}.{{.Field}} = {{.Var}}
+{{- end}}
+
+	if err := s.{{.LowerName}}Repo.Create(ctx, {{.LowerName}}); err != nil {
 		return nil, err
 	}
 
 	return {{.LowerName}}, nil
 }
 
-// Get{{.Name}}ByID retrieves a {{.LowerName}} by its ID
-func (s *{{.Name}}Service) Get{{.Name}}ByID(id uint) (*models.{{.Name}}, error) {
+{{- if not .Relations.Any}}
+// Get{{.Name}}ByID retrieves a {{.LowerName}} by its ID. When fields, JSON field
+// names, are given only those are loaded.
+func (s *{{.Name}}Service) Get{{.Name}}ByID(ctx context.Context, id uint, fields []string) (*models.{{.Name}}, error) {
+	ctx, span := telemetry.StartSpan(ctx, "{{.Name}}Service.Get{{.Name}}ByID")
+	defer span.End()
+
+	if id == 0 {
+		return nil, errors.New("invalid {{.LowerName}} ID")
+	}
+
+	{{.LowerName}}, err := s.{{.LowerName}}Repo.GetByID(ctx, id, fields)
+{{- else}}
+// Get{{.Name}}ByID retrieves a {{.LowerName}} by its ID with the requested
+// associations. When fields, JSON field names, are given only those are loaded.
+func (s *{{.Name}}Service) Get{{.Name}}ByID(ctx context.Context, id uint, fields []string, includes ...string) (*models.{{.Name}}, error) {
+	ctx, span := telemetry.StartSpan(ctx, "{{.Name}}Service.Get{{.Name}}ByID")
+	defer span.End()
+
 	if id == 0 {
 		return nil, errors.New("invalid {{.LowerName}} ID")
 	}
 
-	{{.LowerName}}, err := s.{{.LowerName}}Repo.GetByID(id)
+	associations, err := resolve{{.Name}}Includes(includes)
+	if err != nil {
+		return nil, err
+	}
+
+	{{.LowerName}}, err := s.{{.LowerName}}Repo.GetByID(ctx, id, fields, associations...)
+{{- end}}
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("{{.LowerName}} not found")
@@ -76,8 +143,29 @@ func (s *{{.Name}}Service) Get{{.Name}}ByID(id uint) (*models.{{.Name}}, error)
 	return {{.LowerName}}, nil
 }
 
-// Get{{.Name}}s retrieves all {{.LowerName}}s with pagination
-func (s *{{.Name}}Service) Get{{.Name}}s(page, limit int) ([]models.{{.Name}}, int64, error) {
+{{- if not .Relations.Any}}
+// Get{{.Name}}s retrieves all {{.LowerName}}s with pagination. When fields, JSON
+// field names, are given only those are loaded.
+func (s *{{.Name}}Service) Get{{.Name}}s(ctx context.Context, page, limit int, fields []string) ([]models.{{.Name}}, int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "{{.Name}}Service.Get{{.Name}}s")
+	defer span.End()
+
+	// Business logic: validate pagination parameters
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	{{.LowerName}}s, total, err := s.{{.LowerName}}Repo.GetAll(ctx, page, limit, fields)
+{{- else}}
+// Get{{.Name}}s retrieves all {{.LowerName}}s with pagination and the requested
+// associations. When fields, JSON field names, are given only those are loaded.
+func (s *{{.Name}}Service) Get{{.Name}}s(ctx context.Context, page, limit int, fields []string, includes ...string) ([]models.{{.Name}}, int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "{{.Name}}Service.Get{{.Name}}s")
+	defer span.End()
+
 	// Business logic: validate pagination parameters
 	if page < 1 {
 		page = 1
@@ -86,7 +174,13 @@ func (s *{{.Name}}Service) Get{{.Name}}s(page, limit int) ([]models.{{.Name}}, i
 		limit = 10
 	}
 
-	{{.LowerName}}s, total, err := s.{{.LowerName}}Repo.GetAll(page, limit)
+	associations, err := resolve{{.Name}}Includes(includes)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	{{.LowerName}}s, total, err := s.{{.LowerName}}Repo.GetAll(ctx, page, limit, fields, associations...)
+{{- end}}
 	if err != nil {
 		return nil, 0, err
 	}
@@ -94,32 +188,72 @@ func (s *{{.Name}}Service) Get{{.Name}}s(page, limit int) ([]models.{{.Name}}, i
 	return {{.LowerName}}s, total, nil
 }
 
-// Update{{.Name}} updates an existing {{.LowerName}}
-func (s *{{.Name}}Service) Update{{.Name}}(id uint, req *requests.Update{{.Name}}Request) (*models.{{.Name}}, error) {
+// Update{{.Name}} updates an existing {{.LowerName}}. When version isn't zero,
+// the {{.LowerName}} must still be at that version.
+func (s *{{.Name}}Service) Update{{.Name}}(ctx context.Context, id uint, version uint, req *requests.Update{{.Name}}Request) (*models.{{.Name}}, error) {
+	ctx, span := telemetry.StartSpan(ctx, "{{.Name}}Service.Update{{.Name}}")
+	defer span.End()
+
 	// Check if {{.LowerName}} exists
-	existing{{.Name}}, err := s.{{.LowerName}}Repo.GetByID(id)
+	existing{{.Name}}, err := s.{{.LowerName}}Repo.GetByID(ctx, id, nil)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("{{.LowerName}} not found")
 		}
 		return nil, err
 	}
+	if version != 0 && existing{{.Name}}.Version != version {
+		return nil, errors.New("{{.LowerName}} version mismatch")
+	}
+
+{{- range .Relations.BelongsTo}}
+
+	if req.{{.ForeignKey}} != nil {
+		if err := s.validate{{.Field}}(ctx, *req.{{.ForeignKey}}); err != nil {
+			return nil, err
+		}
+	}
+{{- end}}
+{{- range .Relations.ManyToMany}}
+
+	var {{.Var}} []models.{{.Entity}}
+	if req.{{.IDsField}} != nil {
+		if {{.Var}}, err = s.resolve{{.Field}}(ctx, *req.{{.IDsField}}); err != nil {
+			return nil, err
+		}
+	}
+{{- end}}
 
 	// Use reflection-based field mapping for automatic assignment
 	// This will handle all pointer fields automatically
 	utils.MapFieldsWithExclusions(req, existing{{.Name}}, "ID", "CreatedAt", "UpdatedAt", "DeletedAt")
 
-	if err := s.{{.LowerName}}Repo.Update(existing{{.Name}}); err != nil {
+	if err := s.{{.LowerName}}Repo.Update(ctx, existing{{.Name}}); err != nil {
+		if errors.Is(err, {{.LowerName}}Repo.ErrVersionConflict) {
+			return nil, errors.New("{{.LowerName}} version mismatch")
+		}
 		return nil, err
 	}
+{{- range .Relations.ManyToMany}}
+
+	if req.{{.IDsField}} != nil {
+		if err := s.{{$.LowerName}}Repo.Replace{{.Field}}(ctx, existing{{$.Name}}, {{.Var}}); err != nil {
+			return nil, err
+		}
+	}
+{{- end}}
 
 	return existing{{.Name}}, nil
 }
 
-// Delete{{.Name}} deletes a {{.LowerName}} by its ID
-func (s *{{.Name}}Service) Delete{{.Name}}(id uint) error {
+// Delete{{.Name}} deletes a {{.LowerName}} by its ID. When version isn't zero,
+// the {{.LowerName}} must still be at that version.
+func (s *{{.Name}}Service) Delete{{.Name}}(ctx context.Context, id uint, version uint) error {
+	ctx, span := telemetry.StartSpan(ctx, "{{.Name}}Service.Delete{{.Name}}")
+	defer span.End()
+
 	// Check if {{.LowerName}} exists
-	exists, err := s.{{.LowerName}}Repo.ExistsByID(id)
+	exists, err := s.{{.LowerName}}Repo.ExistsByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -127,12 +261,21 @@ func (s *{{.Name}}Service) Delete{{.Name}}(id uint) error {
 		return errors.New("{{.LowerName}} not found")
 	}
 
-	return s.{{.LowerName}}Repo.Delete(id)
+	if err := s.{{.LowerName}}Repo.Delete(ctx, id, version); err != nil {
+		if errors.Is(err, {{.LowerName}}Repo.ErrVersionConflict) {
+			return errors.New("{{.LowerName}} version mismatch")
+		}
+		return err
+	}
+	return nil
 }
 
 // Get{{.Name}}Stats returns statistics about {{.LowerName}}s
-func (s *{{.Name}}Service) Get{{.Name}}Stats() (map[string]interface{}, error) {
-	total, err := s.{{.LowerName}}Repo.Count()
+func (s *{{.Name}}Service) Get{{.Name}}Stats(ctx context.Context) (map[string]interface{}, error) {
+	ctx, span := telemetry.StartSpan(ctx, "{{.Name}}Service.Get{{.Name}}Stats")
+	defer span.End()
+
+	total, err := s.{{.LowerName}}Repo.Count(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -143,33 +286,60 @@ func (s *{{.Name}}Service) Get{{.Name}}Stats() (map[string]interface{}, error) {
 
 	return stats, nil
 }
-`
+{{- range .Relations.BelongsTo}}
 
-// Generate creates the service file
-func Generate(data GeneratorData) error {
-	// Create services directory if it doesn't exist
-	servicesDir := "services"
-	if err := os.MkdirAll(servicesDir, 0755); err != nil {
-		return fmt.Errorf("failed to create services directory: %v", err)
+// validate{{.Field}} checks that the referenced {{.LowerEntity}} exists
+func (s *{{$.Name}}Service) validate{{.Field}}(ctx context.Context, id uint) error {
+	exists, err := s.{{$.LowerName}}Repo.{{.Field}}Exists(ctx, id)
+	if err != nil {
+		return err
 	}
+	if !exists {
+		return fmt.Errorf("%w: {{.LowerEntity}} %d does not exist", Err{{$.Name}}InvalidReference, id)
+	}
+	return nil
+}
+{{- end}}
+{{- range .Relations.ManyToMany}}
 
-	// Create service file
-	serviceFile := filepath.Join(servicesDir, fmt.Sprintf("%s_service.go", data.LowerName))
-	file, err := os.Create(serviceFile)
+// resolve{{.Field}} loads the referenced {{.LowerEntity}}s, failing if any of them do not exist
+func (s *{{$.Name}}Service) resolve{{.Field}}(ctx context.Context, ids []uint) ([]models.{{.Entity}}, error) {
+	{{.Var}}, err := s.{{$.LowerName}}Repo.Find{{.Field}}ByIDs(ctx, ids)
 	if err != nil {
-		return fmt.Errorf("failed to create service file: %v", err)
+		return nil, err
 	}
-	defer file.Close()
 
-	// Parse and execute template
-	tmpl, err := template.New("service").Parse(serviceTemplate)
-	if err != nil {
-		return fmt.Errorf("failed to parse service template: %v", err)
+	found := make(map[uint]bool, len({{.Var}}))
+	for _, item := range {{.Var}} {
+		found[item.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return nil, fmt.Errorf("%w: {{.LowerEntity}} %d does not exist", Err{{$.Name}}InvalidReference, id)
+		}
 	}
 
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute service template: %v", err)
+	return {{.Var}}, nil
+}
+{{- end}}
+{{- if .Relations.Any}}
+
+// resolve{{.Name}}Includes converts ?include= values to association names
+func resolve{{.Name}}Includes(includes []string) ([]string, error) {
+	associations := make([]string, 0, len(includes))
+	for _, include := range includes {
+		association, ok := {{.LowerName}}Includes[include]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", Err{{.Name}}UnknownInclude, include)
+		}
+		associations = append(associations, association)
 	}
+	return associations, nil
+}
+{{- end}}
+`
 
-	return nil
-}
\ No newline at end of file
+// Generate creates the service file
+func Generate(data GeneratorData) error {
+	return render.WriteFile(filepath.Join("services", fmt.Sprintf("%s_service.go", data.LowerName)), "service", serviceTemplate, data)
+}
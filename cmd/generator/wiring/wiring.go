@@ -0,0 +1,334 @@
+package wiring
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// Files the generator wires entities into
+const (
+	RoutesFile   = "routes/routes.go"
+	DatabaseFile = "config/database.go"
+)
+
+// Markers are comment lines the generator inserts code above
+const (
+	ControllersMarker = "// generator:controllers"
+	RoutesMarker      = "// generator:routes"
+	MigrationsMarker  = "// generator:migrations"
+)
+
+// Snippet is a line of code the generator inserted into a file
+type Snippet struct {
+	File string `json:"file"`
+	Line string `json:"line"`
+}
+
+// Import is an import the generator added to a file
+type Import struct {
+	File string `json:"file"`
+	Path string `json:"path"`
+}
+
+// Wiring records everything the generator wired in for an entity
+type Wiring struct {
+	Snippets   []Snippet `json:"snippets,omitempty"`
+	Imports    []Import  `json:"imports,omitempty"`
+	RouteGroup string    `json:"route_group,omitempty"` // e.g., "bookings"
+}
+
+// WireEntity registers a generated entity's controller, routes and migration and
+// returns what was added. Pieces that are already present are left alone.
+func WireEntity(name, lowerName string) (*Wiring, error) {
+	wiring := &Wiring{}
+
+	repoImport := "api-rentcar/repositories/" + lowerName
+	added, err := addImport(RoutesFile, repoImport)
+	if err != nil {
+		return nil, err
+	}
+	if added {
+		wiring.Imports = append(wiring.Imports, Import{File: RoutesFile, Path: repoImport})
+	}
+
+	controller := fmt.Sprintf("%sController := controllers.New%sController(services.New%sService(%s.New%sRepository(db)))",
+		lowerName, name, name, lowerName, name)
+	added, err = insertAboveMarker(RoutesFile, ControllersMarker, []string{controller})
+	if err != nil {
+		return nil, err
+	}
+	if added {
+		wiring.Snippets = append(wiring.Snippets, Snippet{File: RoutesFile, Line: controller})
+	}
+
+	group := lowerName + "s"
+	exists, err := hasRouteGroup(RoutesFile, group)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		routes := []string{
+			fmt.Sprintf("// %s routes", name),
+			fmt.Sprintf("%s := v1.Group(\"/%s\")", group, group),
+			"{",
+			fmt.Sprintf("\t%s.POST(\"\", %sController.Create%s)", group, lowerName, name),
+			fmt.Sprintf("\t%s.GET(\"\", %sController.Get%ss)", group, lowerName, name),
+			fmt.Sprintf("\t%s.GET(\"/:id\", %sController.Get%s)", group, lowerName, name),
+			fmt.Sprintf("\t%s.PUT(\"/:id\", requireIfMatch, %sController.Update%s)", group, lowerName, name),
+			fmt.Sprintf("\t%s.DELETE(\"/:id\", requireIfMatch, %sController.Delete%s)", group, lowerName, name),
+			"}",
+			"",
+		}
+		if _, err := insertAboveMarker(RoutesFile, RoutesMarker, routes); err != nil {
+			return nil, err
+		}
+		wiring.RouteGroup = group
+	}
+
+	migration := fmt.Sprintf("&models.%s{},", name)
+	added, err = insertAboveMarker(DatabaseFile, MigrationsMarker, []string{migration})
+	if err != nil {
+		return nil, err
+	}
+	if added {
+		wiring.Snippets = append(wiring.Snippets, Snippet{File: DatabaseFile, Line: migration})
+	}
+
+	return wiring, nil
+}
+
+// Unwire removes everything recorded in a Wiring
+func Unwire(wiring *Wiring) error {
+	if wiring.RouteGroup != "" {
+		if err := removeRouteGroup(RoutesFile, wiring.RouteGroup); err != nil {
+			return err
+		}
+	}
+
+	for _, snippet := range wiring.Snippets {
+		if err := removeLine(snippet.File, snippet.Line); err != nil {
+			return err
+		}
+	}
+
+	for _, imp := range wiring.Imports {
+		if err := deleteImport(imp.File, imp.Path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RegisterRoute adds a route line to the end of an entity's route group, e.g., the
+// block following cars := v1.Group("/cars")
+func RegisterRoute(lowerName, route string) error {
+	lines, err := readLines(RoutesFile)
+	if err != nil {
+		return err
+	}
+
+	start, end, err := findRouteGroup(lines, lowerName+"s")
+	if err != nil {
+		return err
+	}
+	if start < 0 {
+		return fmt.Errorf("route group for %ss not found in %s", lowerName, RoutesFile)
+	}
+
+	indent := leadingWhitespace(lines[end])
+	updated := append([]string{}, lines[:end]...)
+	updated = append(updated, indent+"\t"+route)
+	updated = append(updated, lines[end:]...)
+	return writeLines(RoutesFile, updated)
+}
+
+// insertAboveMarker inserts lines above a marker comment, indented like the marker,
+// reporting whether anything was inserted. A single line that is already present is skipped.
+func insertAboveMarker(path, marker string, insert []string) (bool, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return false, err
+	}
+
+	index := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == marker {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return false, fmt.Errorf("marker %q not found in %s", marker, path)
+	}
+
+	if len(insert) == 1 && containsLine(lines, insert[0]) {
+		return false, nil
+	}
+
+	indent := leadingWhitespace(lines[index])
+	indented := make([]string, len(insert))
+	for i, line := range insert {
+		if line != "" {
+			line = indent + line
+		}
+		indented[i] = line
+	}
+
+	updated := append([]string{}, lines[:index]...)
+	updated = append(updated, indented...)
+	updated = append(updated, lines[index:]...)
+	return true, writeLines(path, updated)
+}
+
+// removeLine removes the first line matching text, ignoring indentation
+func removeLine(path, text string) error {
+	lines, err := readLines(path)
+	if err != nil {
+		return err
+	}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == text {
+			return writeLines(path, append(lines[:i:i], lines[i+1:]...))
+		}
+	}
+	return nil
+}
+
+// hasRouteGroup reports whether a route group such as "cars" is declared
+func hasRouteGroup(path, group string) (bool, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return false, err
+	}
+
+	start, _, err := findRouteGroup(lines, group)
+	return start >= 0, err
+}
+
+// removeRouteGroup removes a route group block along with its heading comment
+// and the blank line before it
+func removeRouteGroup(path, group string) error {
+	lines, err := readLines(path)
+	if err != nil {
+		return err
+	}
+
+	start, end, err := findRouteGroup(lines, group)
+	if err != nil || start < 0 {
+		return err
+	}
+
+	if start > 0 && strings.HasPrefix(strings.TrimSpace(lines[start-1]), "//") && strings.HasSuffix(lines[start-1], " routes") {
+		start--
+	}
+	if start > 0 && strings.TrimSpace(lines[start-1]) == "" {
+		start--
+	}
+
+	return writeLines(path, append(lines[:start:start], lines[end+1:]...))
+}
+
+// findRouteGroup returns the line of a group declaration such as
+// cars := v1.Group("/cars") and the line of its block's closing brace, or -1
+// if the group isn't declared
+func findRouteGroup(lines []string, group string) (int, int, error) {
+	declaration := fmt.Sprintf("%s := v1.Group(\"/%s\")", group, group)
+
+	start := -1
+	for i, line := range lines {
+		if strings.TrimSpace(line) == declaration {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return -1, -1, nil
+	}
+	if start+1 >= len(lines) || strings.TrimSpace(lines[start+1]) != "{" {
+		return -1, -1, fmt.Errorf("route group for %s has no block in %s", group, RoutesFile)
+	}
+
+	// The block closes with a brace at the same indentation as the opening one
+	closing := leadingWhitespace(lines[start+1]) + "}"
+	for i := start + 2; i < len(lines); i++ {
+		if lines[i] == closing {
+			return start, i, nil
+		}
+	}
+
+	return -1, -1, fmt.Errorf("end of route group for %s not found in %s", group, RoutesFile)
+}
+
+// addImport adds an import to a file, reporting whether it was missing
+func addImport(path, importPath string) (bool, error) {
+	return rewriteImports(path, func(fset *token.FileSet, file *ast.File) bool {
+		return astutil.AddImport(fset, file, importPath)
+	})
+}
+
+// deleteImport removes an import from a file
+func deleteImport(path, importPath string) error {
+	_, err := rewriteImports(path, func(fset *token.FileSet, file *ast.File) bool {
+		return astutil.DeleteImport(fset, file, importPath)
+	})
+	return err
+}
+
+// rewriteImports parses a file, applies an import change and writes it back if anything changed
+func rewriteImports(path string, change func(fset *token.FileSet, file *ast.File) bool) (bool, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+
+	if !change(fset, file) {
+		return false, nil
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return false, fmt.Errorf("failed to format %s: %v", path, err)
+	}
+	return true, os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func readLines(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return strings.Split(string(content), "\n"), nil
+}
+
+// writeLines joins lines and writes them back formatted
+func writeLines(path string, lines []string) error {
+	source, err := format.Source([]byte(strings.Join(lines, "\n")))
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %v", path, err)
+	}
+	return os.WriteFile(path, source, 0644)
+}
+
+func containsLine(lines []string, text string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) == text {
+			return true
+		}
+	}
+	return false
+}
+
+func leadingWhitespace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
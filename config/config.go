@@ -1,8 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +20,88 @@ type Config struct {
 	APIVersion string
 	APITitle   string
 	APIDesc    string
+
+	// TrustRequestID reuses valid inbound X-Request-ID and traceparent headers
+	// as request IDs. Enable it behind a gateway that sets them.
+	TrustRequestID bool
+
+	// QueryTimeout bounds the time the database queries of a single request
+	// may take in total, zero for no limit
+	QueryTimeout time.Duration
+
+	// Tracing exports OpenTelemetry spans for requests, service calls and queries.
+	// TracingExporter is none, otlp (OTLP/HTTP), stdout or file, and
+	// TracingSampleRatio the fraction of new traces that are sampled.
+	TracingExporter     string
+	TracingOTLPEndpoint string
+	TracingFile         string
+	TracingSampleRatio  float64
+	TracingServiceName  string
+
+	// MetricsEnabled serves Prometheus metrics at /metrics, on the API port or,
+	// when MetricsPort is set, on a separate admin port. When MetricsToken is
+	// set, scrapes must send it as a bearer token.
+	MetricsEnabled bool
+	MetricsPort    string
+	MetricsToken   string
+
+	// LogLevel is the minimum level logged: debug, info, warn or error.
+	// SQL statements are logged at debug.
+	LogLevel string
+	// LogFormat is the log output format: json or text
+	LogFormat string
+
+	// OpenAPIValidation checks requests against the published OpenAPI document:
+	// "off", "log" to log mismatches or "reject" to answer them with 400.
+	// In debug mode responses are checked and mismatches logged as well.
+	OpenAPIValidation string
+
+	// ImportAsyncRows is the number of rows above which fleet imports run as
+	// background jobs instead of within the request
+	ImportAsyncRows int
+
+	// RequireIfMatch rejects updates and deletions of cars, products and
+	// generated resources without an If-Match header with 428, so clients
+	// can't overwrite changes they haven't seen
+	RequireIfMatch bool
+
+	// ResponseFormat is the format of response bodies: "envelope", or "legacy"
+	// for the bodies of earlier versions while clients migrate
+	ResponseFormat string
+
+	// CompressionEnabled compresses responses with gzip or brotli, as the
+	// client's Accept-Encoding allows, once they reach CompressionMinSize bytes
+	// and their content type is one of CompressionTypes
+	CompressionEnabled bool
+	CompressionMinSize int
+	CompressionTypes   []string
+
+	// CacheDriver caches car and product reads: "memory" for an LRU cache of
+	// up to CacheMaxEntries values per instance, "redis" for a cache at
+	// RedisURL shared by all instances, or "none". Cached values live for
+	// CacheTTL and are dropped when cars or products change.
+	CacheDriver     string
+	CacheTTL        time.Duration
+	CacheMaxEntries int
+	RedisURL        string
+
+	// HTTPCacheMaxAge is the max-age of the Cache-Control header of car and
+	// product reads; zero makes clients revalidate every time
+	HTTPCacheMaxAge time.Duration
+
+	// IdempotencyWindow is how long the response to a POST with an
+	// Idempotency-Key is kept to replay to retries
+	IdempotencyWindow time.Duration
+
+	// AdminToken is the bearer token of administrators, who may read the audit
+	// log; changes made with it are attributed to "admin". Admin endpoints are
+	// disabled while it is empty.
+	AdminToken string
+
+	// PriceSchedulerInterval is how often scheduled car price changes that
+	// have taken effect are applied; zero disables the scheduler on this
+	// instance
+	PriceSchedulerInterval time.Duration
 }
 
 // AppConfig is the global configuration instance
@@ -28,6 +114,71 @@ func LoadConfig() error {
 		log.Println("No .env file found, using system environment variables")
 	}
 
+	queryTimeout, err := time.ParseDuration(getEnv("DB_QUERY_TIMEOUT", "5s"))
+	if err != nil {
+		return fmt.Errorf("invalid DB_QUERY_TIMEOUT: %v", err)
+	}
+
+	trustRequestID, err := strconv.ParseBool(getEnv("TRUST_REQUEST_ID", "false"))
+	if err != nil {
+		return fmt.Errorf("invalid TRUST_REQUEST_ID: %v", err)
+	}
+
+	sampleRatio, err := strconv.ParseFloat(getEnv("TRACING_SAMPLE_RATIO", "1"), 64)
+	if err != nil {
+		return fmt.Errorf("invalid TRACING_SAMPLE_RATIO: %v", err)
+	}
+
+	metricsEnabled, err := strconv.ParseBool(getEnv("METRICS_ENABLED", "true"))
+	if err != nil {
+		return fmt.Errorf("invalid METRICS_ENABLED: %v", err)
+	}
+
+	importAsyncRows, err := strconv.Atoi(getEnv("IMPORT_ASYNC_ROWS", "200"))
+	if err != nil {
+		return fmt.Errorf("invalid IMPORT_ASYNC_ROWS: %v", err)
+	}
+
+	requireIfMatch, err := strconv.ParseBool(getEnv("REQUIRE_IF_MATCH", "false"))
+	if err != nil {
+		return fmt.Errorf("invalid REQUIRE_IF_MATCH: %v", err)
+	}
+
+	compressionEnabled, err := strconv.ParseBool(getEnv("COMPRESSION_ENABLED", "true"))
+	if err != nil {
+		return fmt.Errorf("invalid COMPRESSION_ENABLED: %v", err)
+	}
+
+	compressionMinSize, err := strconv.Atoi(getEnv("COMPRESSION_MIN_SIZE", "1024"))
+	if err != nil {
+		return fmt.Errorf("invalid COMPRESSION_MIN_SIZE: %v", err)
+	}
+
+	cacheTTL, err := time.ParseDuration(getEnv("CACHE_TTL", "5m"))
+	if err != nil {
+		return fmt.Errorf("invalid CACHE_TTL: %v", err)
+	}
+
+	cacheMaxEntries, err := strconv.Atoi(getEnv("CACHE_MAX_ENTRIES", "10000"))
+	if err != nil {
+		return fmt.Errorf("invalid CACHE_MAX_ENTRIES: %v", err)
+	}
+
+	httpCacheMaxAge, err := time.ParseDuration(getEnv("HTTP_CACHE_MAX_AGE", "0s"))
+	if err != nil {
+		return fmt.Errorf("invalid HTTP_CACHE_MAX_AGE: %v", err)
+	}
+
+	idempotencyWindow, err := time.ParseDuration(getEnv("IDEMPOTENCY_WINDOW", "24h"))
+	if err != nil {
+		return fmt.Errorf("invalid IDEMPOTENCY_WINDOW: %v", err)
+	}
+
+	priceSchedulerInterval, err := time.ParseDuration(getEnv("PRICE_SCHEDULER_INTERVAL", "1m"))
+	if err != nil {
+		return fmt.Errorf("invalid PRICE_SCHEDULER_INTERVAL: %v", err)
+	}
+
 	AppConfig = &Config{
 		Port:       getEnv("PORT", "8080"),
 		GinMode:    getEnv("GIN_MODE", "debug"),
@@ -36,6 +187,47 @@ func LoadConfig() error {
 		APIVersion: getEnv("API_VERSION", "v1"),
 		APITitle:   getEnv("API_TITLE", "RentCar API"),
 		APIDesc:    getEnv("API_DESCRIPTION", "A RESTful API for car rental management"),
+
+		TrustRequestID: trustRequestID,
+		QueryTimeout:   queryTimeout,
+
+		TracingExporter:     getEnv("TRACING_EXPORTER", "none"),
+		TracingOTLPEndpoint: getEnv("TRACING_OTLP_ENDPOINT", ""),
+		TracingFile:         getEnv("TRACING_FILE", "./data/traces.json"),
+		TracingSampleRatio:  sampleRatio,
+		TracingServiceName:  getEnv("OTEL_SERVICE_NAME", "api-rentcar"),
+
+		MetricsEnabled: metricsEnabled,
+		MetricsPort:    getEnv("METRICS_PORT", ""),
+		MetricsToken:   getEnv("METRICS_TOKEN", ""),
+
+		LogLevel:  getEnv("LOG_LEVEL", "info"),
+		LogFormat: getEnv("LOG_FORMAT", "json"),
+
+		OpenAPIValidation: getEnv("OPENAPI_VALIDATION", "off"),
+
+		ImportAsyncRows: importAsyncRows,
+
+		RequireIfMatch: requireIfMatch,
+
+		ResponseFormat: getEnv("RESPONSE_FORMAT", "envelope"),
+
+		CompressionEnabled: compressionEnabled,
+		CompressionMinSize: compressionMinSize,
+		CompressionTypes:   splitList(getEnv("COMPRESSION_TYPES", "application/json,application/x-ndjson,application/yaml,text/csv,text/plain,text/html,text/css,application/javascript")),
+
+		CacheDriver:     getEnv("CACHE_DRIVER", "memory"),
+		CacheTTL:        cacheTTL,
+		CacheMaxEntries: cacheMaxEntries,
+		RedisURL:        getEnv("REDIS_URL", "redis://localhost:6379/0"),
+
+		HTTPCacheMaxAge: httpCacheMaxAge,
+
+		IdempotencyWindow: idempotencyWindow,
+
+		AdminToken: getEnv("ADMIN_TOKEN", ""),
+
+		PriceSchedulerInterval: priceSchedulerInterval,
 	}
 
 	log.Printf("Configuration loaded: Port=%s, Mode=%s, DB=%s",
@@ -44,6 +236,17 @@ func LoadConfig() error {
 	return nil
 }
 
+// splitList splits a comma-separated value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // getEnv gets an environment variable with a fallback value
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
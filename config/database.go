@@ -3,17 +3,25 @@ package config
 import (
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	otelgorm "gorm.io/plugin/opentelemetry/tracing"
 
+	"api-rentcar/audit"
+	"api-rentcar/logger"
 	"api-rentcar/models"
 )
 
+// slowQueryThreshold is the duration above which SQL statements are logged as warnings
+const slowQueryThreshold = 200 * time.Millisecond
+
 var DB *gorm.DB
 
 func InitDatabase() error {
@@ -32,14 +40,10 @@ func InitDatabase() error {
 			return fmt.Errorf("failed to create data directory: %w", err)
 		}
 
-		// Configure GORM logger
-		logLevel := logger.Info
-		if os.Getenv("GIN_MODE") == "release" {
-			logLevel = logger.Error
-		}
-
+		// Log SQL through the application logger
 		config := &gorm.Config{
-			Logger: logger.Default.LogMode(logLevel),
+			Logger:         logger.NewGormLogger(slog.Default(), slowQueryThreshold),
+			TranslateError: true,
 		}
 
 		// Open database connection with pure Go SQLite driver
@@ -49,6 +53,13 @@ func InitDatabase() error {
 			return fmt.Errorf("failed to connect to database: %w", err)
 		}
 
+		if err = DB.Use(otelgorm.NewPlugin(otelgorm.WithoutMetrics())); err != nil {
+			return fmt.Errorf("failed to enable query tracing: %w", err)
+		}
+		if err = DB.Use(audit.NewPlugin()); err != nil {
+			return fmt.Errorf("failed to enable audit log: %w", err)
+		}
+
 		// Run auto migrations
 		err = runMigrations()
 		if err != nil {
@@ -65,17 +76,26 @@ func InitDatabase() error {
 			os.Getenv("DB_PORT"),
 			os.Getenv("DB_NAME"),
 		)
-		DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+		DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
+			Logger:         logger.NewGormLogger(slog.Default(), slowQueryThreshold),
+			TranslateError: true,
+		})
 		if err != nil {
 			return fmt.Errorf("failed to connect to MySQL database: %w", err)
 		}
+		if err = DB.Use(otelgorm.NewPlugin(otelgorm.WithoutMetrics())); err != nil {
+			return fmt.Errorf("failed to enable query tracing: %w", err)
+		}
+		if err = DB.Use(audit.NewPlugin()); err != nil {
+			return fmt.Errorf("failed to enable audit log: %w", err)
+		}
 		log.Println("Connected to MySQL database")
 	default:
 		return fmt.Errorf("unsupported database type: %s", AppConfig.DBType)
 	}
 
 	// Auto-migrate your models
-	err = DB.AutoMigrate(&models.Product{}, &models.Car{})
+	err = runMigrations()
 	if err != nil {
 		return fmt.Errorf("failed to auto-migrate database: %w", err)
 	}
@@ -86,10 +106,51 @@ func InitDatabase() error {
 
 // runMigrations runs database migrations for all models
 func runMigrations() error {
-	return DB.AutoMigrate(
+	if err := checkDuplicateLicensePlates(); err != nil {
+		return err
+	}
+
+	err := DB.AutoMigrate(
 		&models.Product{},
 		&models.Car{},
+		&models.AuditLog{},
+		&models.CarPrice{},
+		&models.PricingRule{},
+		// generator:migrations
 	)
+	if err != nil {
+		return err
+	}
+	return backfillCarPrices()
+}
+
+// backfillCarPrices starts the price history of cars that have none, e.g.,
+// cars created before it was kept, with their current prices
+func backfillCarPrices() error {
+	return DB.Exec(`INSERT INTO car_prices (car_id, price_per_day, price_per_week, price_per_month, effective_from, applied_at, created_at)
+		SELECT id, price_per_day, price_per_week, price_per_month, updated_at, updated_at, ?
+		FROM cars
+		WHERE NOT EXISTS (SELECT 1 FROM car_prices WHERE car_prices.car_id = cars.id)`, time.Now()).Error
+}
+
+// checkDuplicateLicensePlates fails when several cars share a license plate,
+// which the unique index on it can't be created over
+func checkDuplicateLicensePlates() error {
+	if !DB.Migrator().HasTable(&models.Car{}) {
+		return nil
+	}
+
+	var plates []string
+	err := DB.Model(&models.Car{}).
+		Group("license_plate").Having("COUNT(*) > 1").
+		Limit(10).Pluck("license_plate", &plates).Error
+	if err != nil {
+		return err
+	}
+	if len(plates) > 0 {
+		return fmt.Errorf("license plates used by several cars, make them unique before migrating: %s", strings.Join(plates, ", "))
+	}
+	return nil
 }
 
 // GetDB returns the database instance
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	auditRepo "api-rentcar/repositories/audit"
+	"api-rentcar/responses"
+	"api-rentcar/services"
+	"api-rentcar/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditController handles audit log requests
+type AuditController struct {
+	auditService services.AuditServiceInterface
+}
+
+// NewAuditController creates a new audit controller
+func NewAuditController(auditService services.AuditServiceInterface) *AuditController {
+	return &AuditController{
+		auditService: auditService,
+	}
+}
+
+// GetAuditLogs godoc
+// @Summary Get the audit log of an entity
+// @Description Get the changes made to entities of a type, e.g., car, or to one entity, newest first. Each entry has who made the change, from which IP and request, and the changed fields before and after. Requires the admin bearer token.
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Produce application/msgpack
+// @Param Authorization header string true "Bearer followed by the admin token"
+// @Param entity query string true "Entity type, e.g., car or product"
+// @Param id query string false "Entity ID"
+// @Param action query string false "Only changes of this action" Enums(create, update, delete)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} utils.Envelope{data=[]responses.AuditLogResponse}
+// @Failure 400 {object} utils.Envelope
+// @Failure 401 {object} utils.Envelope
+// @Failure 403 {object} utils.Envelope
+// @Failure 406 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
+// @Router /audit [get]
+func (c *AuditController) GetAuditLogs(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	page, limit = utils.NormalizePagination(page, limit)
+	filter := auditRepo.AuditLogFilter{
+		EntityType: ctx.Query("entity"),
+		EntityID:   ctx.Query("id"),
+		Action:     ctx.Query("action"),
+	}
+
+	entries, total, err := c.auditService.GetAuditLogs(ctx.Request.Context(), filter, page, limit)
+	if err != nil {
+		switch err.Error() {
+		case "entity is required", "action must be create, update or delete":
+			utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid audit log query", err)
+		default:
+			utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch audit log", err)
+		}
+		return
+	}
+
+	utils.SendPaginatedResponse(ctx, "Audit log retrieved successfully", responses.ToAuditLogResponses(entries), total, page, limit)
+}
@@ -1,12 +1,22 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	"api-rentcar/middleware"
+	"api-rentcar/models"
 	requests "api-rentcar/requests"
 	"api-rentcar/responses"
 	"api-rentcar/services"
+	"api-rentcar/spreadsheet"
 	"api-rentcar/utils"
 
 	"github.com/gin-gonic/gin"
@@ -26,14 +36,20 @@ func NewCarController(carService services.CarServiceInterface) *CarController {
 
 // CreateCar godoc
 // @Summary Create a new car
-// @Description Create a new car with the provided information
+// @Description Create a new car with the provided information. The created car is returned as data, unless the request sends Prefer: return=minimal.
 // @Tags cars
 // @Accept json
 // @Produce json
 // @Param car body requests.CreateCarRequest true "Car creation request"
-// @Success 201 {object} utils.SuccessResponse
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Param Prefer header string false "return=minimal to omit the car from the response"
+// @Param Idempotency-Key header string false "Unique key that makes retries of the request return the first response"
+// @Success 201 {object} utils.Envelope{data=responses.CarResponse}
+// @Header 201 {string} Location "URL of the created car"
+// @Header 201 {string} ETag "Version of the car"
+// @Failure 400 {object} utils.Envelope
+// @Failure 409 {object} utils.Envelope
+// @Failure 422 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
 // @Router /cars [post]
 func (c *CarController) CreateCar(ctx *gin.Context) {
 	var req requests.CreateCarRequest
@@ -41,17 +57,18 @@ func (c *CarController) CreateCar(ctx *gin.Context) {
 		return
 	}
 
-	_, err := c.carService.CreateCar(&req)
+	car, err := c.carService.CreateCar(ctx.Request.Context(), &req)
 	if err != nil {
+		if err.Error() == "license plate already in use" {
+			utils.SendErrorResponse(ctx, http.StatusConflict, "License plate already in use", err)
+			return
+		}
 		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to create car", err)
 		return
 	}
 
-	response := utils.SuccessResponse{
-		Success: true,
-		Message: "Car created successfully",
-	}
-	ctx.JSON(http.StatusCreated, response)
+	ctx.Header("ETag", utils.ETag(car.Version))
+	utils.SendCreatedResponse(ctx, "Car created successfully", car.ID, responses.ToCarResponse(car))
 }
 
 // GetCars godoc
@@ -60,48 +77,153 @@ func (c *CarController) CreateCar(ctx *gin.Context) {
 // @Tags cars
 // @Accept json
 // @Produce json
+// @Produce application/msgpack
+// @Produce text/csv
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
 // @Param available query bool false "Filter by availability"
-// @Success 200 {object} responses.CarsListResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Param fields query string false "Comma-separated fields to return, e.g., id,name,price_per_day,is_available; all by default"
+// @Success 200 {object} utils.Envelope{data=[]responses.CarResponse}
+// @Header 200 {string} Last-Modified "Latest update time of the listed cars"
+// @Header 200 {string} Cache-Control "How long the list may be reused"
+// @Failure 400 {object} utils.Envelope
+// @Failure 406 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
 // @Router /cars [get]
 func (c *CarController) GetCars(ctx *gin.Context) {
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
-	available := ctx.Query("available")
-
-	var availableBool *bool
-	if available != "" {
-		switch available {
-		case "true":
-			availableBool = &[]bool{true}[0]
-		case "false":
-			availableBool = &[]bool{false}[0]
-		}
+	availableBool := parseAvailableFilter(ctx)
+
+	fields, err := utils.ParseFields(ctx.Query("fields"), responses.CarResponse{})
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid fields", err)
+		return
 	}
 
-	cars, total, err := c.carService.GetCars(page, limit, availableBool)
+	cars, total, err := c.carService.GetCars(ctx.Request.Context(), page, limit, availableBool, fields)
 	if err != nil {
 		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch cars", err)
 		return
 	}
 
+	updatedAt := make([]time.Time, len(cars))
+	for i := range cars {
+		updatedAt[i] = cars[i].UpdatedAt
+	}
+	utils.SetLastModified(ctx, updatedAt...)
+
 	response := responses.ToCarsListResponse(cars, total, page, limit)
-	ctx.JSON(http.StatusOK, response)
+	utils.SendListResponse(ctx, utils.SelectFields(response.Data, fields), response.Pagination)
+}
+
+// ExportCars godoc
+// @Summary Export cars
+// @Description Download the cars matching the filters of the car list as CSV, XLSX or newline-delimited JSON. The file is streamed as it is read from the database.
+// @Tags cars
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/x-ndjson
+// @Produce json
+// @Param format query string false "File format" Enums(csv, xlsx, ndjson) default(csv)
+// @Param columns query string false "Comma-separated columns to export, e.g., id,name,license_plate; all by default"
+// @Param available query bool false "Filter by availability"
+// @Success 200 {string} string "Exported file"
+// @Failure 400 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
+// @Router /cars/export [get]
+func (c *CarController) ExportCars(ctx *gin.Context) {
+	format := ctx.DefaultQuery("format", spreadsheet.FormatCSV)
+	if format != spreadsheet.FormatCSV && format != spreadsheet.FormatXLSX && format != spreadsheet.FormatNDJSON {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid export format", fmt.Errorf("unsupported format %q (use csv, xlsx or ndjson)", format))
+		return
+	}
+
+	columns := utils.JSONFieldNames(responses.CarResponse{})
+	if selected := ctx.Query("columns"); selected != "" {
+		var err error
+		if columns, err = selectColumns(selected, columns); err != nil {
+			utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid export columns", err)
+			return
+		}
+	}
+
+	writer, err := spreadsheet.NewWriter(ctx.Writer, format, columns)
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to export cars", err)
+		return
+	}
+
+	ctx.Header("Content-Type", spreadsheet.ContentType(format))
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="cars-%s.%s"`, time.Now().Format("20060102"), format))
+
+	// The download may outlast the server's write timeout and the query timeout
+	_ = http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{})
+
+	err = c.carService.ExportCars(middleware.WithoutQueryTimeout(ctx), parseAvailableFilter(ctx), func(cars []models.Car) error {
+		for i := range cars {
+			if err := writer.Write(utils.JSONFieldValues(responses.ToCarResponse(&cars[i]), columns)); err != nil {
+				return err
+			}
+		}
+		return writer.Flush()
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		if !ctx.Writer.Written() {
+			ctx.Writer.Header().Del("Content-Disposition")
+			utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to export cars", err)
+			return
+		}
+		// The status is already sent, so all that's left is to cut the file short
+		slog.ErrorContext(ctx.Request.Context(), "car export failed", slog.String("error", err.Error()))
+		ctx.Abort()
+	}
+}
+
+// GetCarStats godoc
+// @Summary Get fleet statistics
+// @Description Get car counts by brand, category, transmission, availability and year, and daily price ranges per category
+// @Tags cars
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.Envelope{data=responses.CarStatsResponse}
+// @Failure 500 {object} utils.Envelope
+// @Router /cars/stats [get]
+func (c *CarController) GetCarStats(ctx *gin.Context) {
+	stats, err := c.carService.GetCarStats(ctx.Request.Context())
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch car statistics", err)
+		return
+	}
+
+	response := responses.ToCarStatsResponse(stats)
+	utils.SendDataResponse(ctx, http.StatusOK, response)
 }
 
 // GetCar godoc
 // @Summary Get a car by ID
-// @Description Get a single car by its ID
+// @Description Get a single car by its ID. The ETag header holds the car's version, to send back in If-Match when updating or deleting it; with If-None-Match, or If-Modified-Since and the Last-Modified header, an unchanged car returns 304.
 // @Tags cars
 // @Accept json
 // @Produce json
+// @Produce application/msgpack
+// @Produce text/csv
 // @Param id path int true "Car ID"
-// @Success 200 {object} responses.CarResponse
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 404 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Param fields query string false "Comma-separated fields to return, e.g., id,name,price_per_day,is_available; all by default"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Param If-Modified-Since header string false "Last-Modified of a cached copy"
+// @Success 200 {object} utils.Envelope{data=responses.CarResponse}
+// @Header 200 {string} ETag "Version of the car"
+// @Header 200 {string} Last-Modified "Time the car was last updated"
+// @Header 200 {string} Cache-Control "How long the car may be reused"
+// @Success 304 "Not modified"
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 406 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
 // @Router /cars/{id} [get]
 func (c *CarController) GetCar(ctx *gin.Context) {
 	idParam := ctx.Param("id")
@@ -111,7 +233,20 @@ func (c *CarController) GetCar(ctx *gin.Context) {
 		return
 	}
 
-	car, err := c.carService.GetCarByID(uint(id))
+	fields, err := utils.ParseFields(ctx.Query("fields"), responses.CarResponse{})
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid fields", err)
+		return
+	}
+
+	// The version and update time are loaded for the ETag and Last-Modified
+	// even when they aren't selected fields
+	loadFields := fields
+	if len(fields) > 0 {
+		loadFields = append(fields[:len(fields):len(fields)], "version", "updated_at")
+	}
+
+	car, err := c.carService.GetCarByID(ctx.Request.Context(), uint(id), loadFields)
 	if err != nil {
 		if err.Error() == "car not found" {
 			utils.SendErrorResponse(ctx, http.StatusNotFound, "Car not found", err)
@@ -121,24 +256,39 @@ func (c *CarController) GetCar(ctx *gin.Context) {
 		return
 	}
 
+	etag := utils.ETag(car.Version)
+	ctx.Header("ETag", etag)
+	utils.SetLastModified(ctx, car.UpdatedAt)
+	if ifNoneMatch := ctx.GetHeader("If-None-Match"); utils.MatchesIfNoneMatch(ifNoneMatch, etag) ||
+		(ifNoneMatch == "" && utils.NotModifiedSince(ctx.GetHeader("If-Modified-Since"), car.UpdatedAt)) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
 	response := responses.ToCarResponse(car)
-	ctx.JSON(http.StatusOK, response)
+	utils.SendDataResponse(ctx, http.StatusOK, utils.SelectFields(response, fields))
 }
 
-// UpdateCar godoc
-// @Summary Update a car
-// @Description Update an existing car with the provided information
+// ReplaceCar godoc
+// @Summary Replace a car
+// @Description Replace all fields of an existing car. The body is validated like a car creation; use PATCH to change some fields only. The updated car is returned as data, unless the request sends Prefer: return=minimal. With If-Match, the car is only replaced if nobody changed it since it was read. If-Match is required when the server sets REQUIRE_IF_MATCH.
 // @Tags cars
 // @Accept json
 // @Produce json
 // @Param id path int true "Car ID"
-// @Param car body requests.UpdateCarRequest true "Car update request"
-// @Success 200 {object} utils.SuccessResponse
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 404 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Param If-Match header string false "ETag the car must still have, from GET /cars/{id}"
+// @Param car body requests.CreateCarRequest true "Car replacement request"
+// @Param Prefer header string false "return=minimal to omit the car from the response"
+// @Success 200 {object} utils.Envelope{data=responses.CarResponse}
+// @Header 200 {string} ETag "New version of the car"
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 409 {object} utils.Envelope
+// @Failure 412 {object} utils.Envelope
+// @Failure 428 {object} utils.Envelope "If-Match is missing while REQUIRE_IF_MATCH is set"
+// @Failure 500 {object} utils.Envelope
 // @Router /cars/{id} [put]
-func (c *CarController) UpdateCar(ctx *gin.Context) {
+func (c *CarController) ReplaceCar(ctx *gin.Context) {
 	idParam := ctx.Param("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
@@ -146,39 +296,142 @@ func (c *CarController) UpdateCar(ctx *gin.Context) {
 		return
 	}
 
-	var req requests.UpdateCarRequest
+	version, ok := parseIfMatch(ctx, "Car")
+	if !ok {
+		return
+	}
+
+	var req requests.CreateCarRequest
 	if !utils.BindAndValidate(ctx, &req) {
 		return
 	}
 
-	_, err = c.carService.UpdateCar(uint(id), &req)
+	car, err := c.carService.ReplaceCar(ctx.Request.Context(), uint(id), version, &req)
+	if err != nil {
+		switch err.Error() {
+		case "car not found":
+			utils.SendErrorResponse(ctx, http.StatusNotFound, "Car not found", err)
+		case "car version mismatch":
+			utils.SendErrorResponse(ctx, http.StatusPreconditionFailed, "Car was modified by another request", err)
+		case "license plate already in use":
+			utils.SendErrorResponse(ctx, http.StatusConflict, "License plate already in use", err)
+		default:
+			utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to update car", err)
+		}
+		return
+	}
+
+	ctx.Header("ETag", utils.ETag(car.Version))
+	utils.SendWriteResponse(ctx, http.StatusOK, "Car updated successfully", responses.ToCarResponse(car))
+}
+
+// PatchCar godoc
+// @Summary Update some fields of a car
+// @Description Update an existing car with a JSON Merge Patch (RFC 7396), where fields present replace the current values and null clears them, or with a JSON Patch (RFC 6902) sent as application/json-patch+json. The patched car is validated like a car creation and returned as data, unless the request sends Prefer: return=minimal. With If-Match, the car is only updated if nobody changed it since it was read. If-Match is required when the server sets REQUIRE_IF_MATCH.
+// @Tags cars
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Accept json
+// @Produce json
+// @Param id path int true "Car ID"
+// @Param If-Match header string false "ETag the car must still have, from GET /cars/{id}"
+// @Param car body requests.PatchCarRequest true "Car merge patch"
+// @Param Prefer header string false "return=minimal to omit the car from the response"
+// @Success 200 {object} utils.Envelope{data=responses.CarResponse}
+// @Header 200 {string} ETag "New version of the car"
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 409 {object} utils.Envelope
+// @Failure 412 {object} utils.Envelope
+// @Failure 428 {object} utils.Envelope "If-Match is missing while REQUIRE_IF_MATCH is set"
+// @Failure 415 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
+// @Router /cars/{id} [patch]
+func (c *CarController) PatchCar(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid car ID", err)
+		return
+	}
+
+	contentType := ctx.ContentType()
+	if contentType != utils.MergePatchContentType && contentType != utils.JSONPatchContentType && contentType != "application/json" {
+		utils.SendErrorResponse(ctx, http.StatusUnsupportedMediaType, "Unsupported patch type",
+			fmt.Errorf("use %s or %s", utils.MergePatchContentType, utils.JSONPatchContentType))
+		return
+	}
+
+	version, ok := parseIfMatch(ctx, "Car")
+	if !ok {
+		return
+	}
+
+	patch, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Failed to read request body", err)
+		return
+	}
+
+	car, err := c.carService.GetCarByID(ctx.Request.Context(), uint(id), nil)
 	if err != nil {
 		if err.Error() == "car not found" {
 			utils.SendErrorResponse(ctx, http.StatusNotFound, "Car not found", err)
 			return
 		}
-		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to update car", err)
+		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch car", err)
+		return
+	}
+	if version != 0 && car.Version != version {
+		utils.SendErrorResponse(ctx, http.StatusPreconditionFailed, "Car was modified by another request", errors.New("car version mismatch"))
+		return
+	}
+
+	var req requests.CreateCarRequest
+	if err := utils.ApplyPatch(car, patch, contentType, &req); err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid patch", err)
+		return
+	}
+	if validationErrors := utils.ValidateStruct(&req); len(validationErrors) > 0 {
+		utils.SendValidationErrorResponse(ctx, validationErrors)
 		return
 	}
 
-	response := utils.SuccessResponse{
-		Success: true,
-		Message: "Car updated successfully",
+	// The patch applies to the version just read, so fail rather than
+	// overwrite a change made in between
+	car, err = c.carService.ReplaceCar(ctx.Request.Context(), uint(id), car.Version, &req)
+	if err != nil {
+		switch err.Error() {
+		case "car not found":
+			utils.SendErrorResponse(ctx, http.StatusNotFound, "Car not found", err)
+		case "license plate already in use":
+			utils.SendErrorResponse(ctx, http.StatusConflict, "License plate already in use", err)
+		case "car version mismatch":
+			utils.SendErrorResponse(ctx, http.StatusPreconditionFailed, "Car was modified by another request", err)
+		default:
+			utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to update car", err)
+		}
+		return
 	}
-	ctx.JSON(http.StatusOK, response)
+
+	ctx.Header("ETag", utils.ETag(car.Version))
+	utils.SendWriteResponse(ctx, http.StatusOK, "Car updated successfully", responses.ToCarResponse(car))
 }
 
 // DeleteCar godoc
 // @Summary Delete a car
-// @Description Delete a car by its ID
+// @Description Delete a car by its ID. With If-Match, the car is only deleted if nobody changed it since it was read. If-Match is required when the server sets REQUIRE_IF_MATCH.
 // @Tags cars
 // @Accept json
 // @Produce json
 // @Param id path int true "Car ID"
-// @Success 200 {object} utils.SuccessResponse
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 404 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Param If-Match header string false "ETag the car must still have, from GET /cars/{id}"
+// @Success 200 {object} utils.Envelope
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 412 {object} utils.Envelope
+// @Failure 428 {object} utils.Envelope "If-Match is missing while REQUIRE_IF_MATCH is set"
+// @Failure 500 {object} utils.Envelope
 // @Router /cars/{id} [delete]
 func (c *CarController) DeleteCar(ctx *gin.Context) {
 	idParam := ctx.Param("id")
@@ -188,19 +441,188 @@ func (c *CarController) DeleteCar(ctx *gin.Context) {
 		return
 	}
 
-	err = c.carService.DeleteCar(uint(id))
+	version, ok := parseIfMatch(ctx, "Car")
+	if !ok {
+		return
+	}
+
+	err = c.carService.DeleteCar(ctx.Request.Context(), uint(id), version)
 	if err != nil {
-		if err.Error() == "car not found" {
+		switch err.Error() {
+		case "car not found":
 			utils.SendErrorResponse(ctx, http.StatusNotFound, "Car not found", err)
-			return
+		case "car version mismatch":
+			utils.SendErrorResponse(ctx, http.StatusPreconditionFailed, "Car was modified by another request", err)
+		default:
+			utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete car", err)
+		}
+		return
+	}
+
+	utils.SendSuccessResponse(ctx, http.StatusOK, "Car deleted successfully", nil)
+}
+
+// BulkCreateCars godoc
+// @Summary Create several cars
+// @Description Create several cars in a single transaction. Every item is validated like a single car creation and errors are reported per index. In atomic mode (default) no car is created if any item fails; in partial mode the valid cars are created.
+// @Tags cars
+// @Accept json
+// @Produce json
+// @Param cars body requests.BulkCreateCarsRequest true "Bulk car creation request"
+// @Param Idempotency-Key header string false "Unique key that makes retries of the request return the first response"
+// @Success 201 {object} utils.Envelope{data=responses.BulkCarsResponse}
+// @Success 207 {object} utils.Envelope{data=responses.BulkCarsResponse} "Some items failed in partial mode"
+// @Failure 400 {object} utils.Envelope "Invalid items in atomic mode, with their errors"
+// @Failure 409 {object} utils.Envelope
+// @Failure 422 {object} utils.Envelope "Items failed and atomic mode rolled back, with their errors"
+// @Failure 500 {object} utils.Envelope
+// @Router /cars/bulk [post]
+func (c *CarController) BulkCreateCars(ctx *gin.Context) {
+	var req requests.BulkCreateCarsRequest
+	if !utils.BindAndValidate(ctx, &req) {
+		return
+	}
+
+	results, err := c.carService.BulkCreateCars(ctx.Request.Context(), &req)
+	sendBulkResponse(ctx, results, req.Mode, responses.BulkStatusCreated, http.StatusCreated, err)
+}
+
+// BulkUpdateCars godoc
+// @Summary Update several cars
+// @Description Update several cars in a single transaction. Every item is validated like a single car update and errors are reported per index. In atomic mode (default) no car is updated if any item fails; in partial mode the other cars are updated.
+// @Tags cars
+// @Accept json
+// @Produce json
+// @Param cars body requests.BulkUpdateCarsRequest true "Bulk car update request"
+// @Success 200 {object} utils.Envelope{data=responses.BulkCarsResponse}
+// @Success 207 {object} utils.Envelope{data=responses.BulkCarsResponse} "Some items failed in partial mode"
+// @Failure 400 {object} utils.Envelope "Invalid items in atomic mode, with their errors"
+// @Failure 422 {object} utils.Envelope "Items failed and atomic mode rolled back, with their errors"
+// @Failure 500 {object} utils.Envelope
+// @Router /cars/bulk [patch]
+func (c *CarController) BulkUpdateCars(ctx *gin.Context) {
+	var req requests.BulkUpdateCarsRequest
+	if !utils.BindAndValidate(ctx, &req) {
+		return
+	}
+
+	results, err := c.carService.BulkUpdateCars(ctx.Request.Context(), &req)
+	sendBulkResponse(ctx, results, req.Mode, responses.BulkStatusUpdated, http.StatusOK, err)
+}
+
+// BulkDeleteCars godoc
+// @Summary Delete several cars
+// @Description Delete several cars by ID in a single transaction, with errors reported per index. In atomic mode (default) no car is deleted if any of them doesn't exist; in partial mode the existing cars are deleted.
+// @Tags cars
+// @Accept json
+// @Produce json
+// @Param cars body requests.BulkDeleteCarsRequest true "Bulk car deletion request"
+// @Success 200 {object} utils.Envelope{data=responses.BulkCarsResponse}
+// @Success 207 {object} utils.Envelope{data=responses.BulkCarsResponse} "Some items failed in partial mode"
+// @Failure 400 {object} utils.Envelope "Invalid items in atomic mode, with their errors"
+// @Failure 422 {object} utils.Envelope "Items failed and atomic mode rolled back, with their errors"
+// @Failure 500 {object} utils.Envelope
+// @Router /cars/bulk [delete]
+func (c *CarController) BulkDeleteCars(ctx *gin.Context) {
+	var req requests.BulkDeleteCarsRequest
+	if !utils.BindAndValidate(ctx, &req) {
+		return
+	}
+
+	results, err := c.carService.BulkDeleteCars(ctx.Request.Context(), &req)
+	sendBulkResponse(ctx, results, req.Mode, responses.BulkStatusDeleted, http.StatusOK, err)
+}
+
+// sendBulkResponse reports the outcome of a bulk operation: successStatus when
+// every item was applied and 207 when some failed in partial mode, with the
+// outcome of every item as data. An atomic request with invalid items gets
+// 400 and one rolled back gets 422, with the errors of the failed items.
+func sendBulkResponse(ctx *gin.Context, results []models.BulkCarResult, mode, status string, successStatus int, err error) {
+	if mode == "" {
+		mode = requests.BulkModeAtomic
+	}
+
+	if err != nil {
+		switch err.Error() {
+		case "bulk validation failed":
+			utils.SendErrorsResponse(ctx, http.StatusBadRequest, "Validation failed, no changes were made", bulkItemErrors(results))
+		case "bulk operation rolled back":
+			utils.SendErrorsResponse(ctx, http.StatusUnprocessableEntity, "Some items failed, no changes were made", bulkItemErrors(results))
+		default:
+			utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Bulk operation failed", err)
 		}
-		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete car", err)
 		return
 	}
 
-	response := utils.SuccessResponse{
-		Success: true,
-		Message: "Car deleted successfully",
+	response := responses.ToBulkCarsResponse(results, mode, status)
+	if response.Failed > 0 {
+		utils.SendSuccessResponse(ctx, http.StatusMultiStatus, "Some items failed", response)
+		return
+	}
+	utils.SendSuccessResponse(ctx, successStatus, "Cars "+status+" successfully", response)
+}
+
+// bulkItemErrors returns the errors of the failed items of a bulk request,
+// each with the index of its item
+func bulkItemErrors(results []models.BulkCarResult) []utils.APIError {
+	var apiErrors []utils.APIError
+	for _, result := range results {
+		index := result.Index
+		for _, itemError := range result.Errors {
+			apiErrors = append(apiErrors, utils.APIError{
+				Message: fmt.Sprintf("Item %d failed", index),
+				Detail:  itemError,
+				Index:   &index,
+			})
+		}
+	}
+	return apiErrors
+}
+
+// parseIfMatch returns the version the If-Match header requires of a resource,
+// e.g., "Car", 0 for any. On an invalid header it sends the error response and
+// returns false.
+func parseIfMatch(ctx *gin.Context, resource string) (uint, bool) {
+	version, err := utils.ParseIfMatch(ctx.GetHeader("If-Match"))
+	switch {
+	case errors.Is(err, utils.ErrWeakETag):
+		utils.SendErrorResponse(ctx, http.StatusPreconditionFailed, resource+" was modified by another request", err)
+		return 0, false
+	case err != nil:
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid If-Match header", err)
+		return 0, false
+	}
+	return version, true
+}
+
+// parseAvailableFilter reads the optional availability filter of car lists
+func parseAvailableFilter(ctx *gin.Context) *bool {
+	var available *bool
+	switch ctx.Query("available") {
+	case "true":
+		available = &[]bool{true}[0]
+	case "false":
+		available = &[]bool{false}[0]
+	}
+	return available
+}
+
+// selectColumns parses a comma-separated list of columns, each of which must
+// be one of the allowed columns
+func selectColumns(selected string, allowed []string) ([]string, error) {
+	var columns []string
+	for _, column := range strings.Split(selected, ",") {
+		column = strings.TrimSpace(column)
+		if column == "" {
+			continue
+		}
+		if !slices.Contains(allowed, column) {
+			return nil, fmt.Errorf("unknown column %q (use %s)", column, strings.Join(allowed, ", "))
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("no columns selected")
 	}
-	ctx.JSON(http.StatusOK, response)
+	return columns, nil
 }
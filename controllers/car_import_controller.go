@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"api-rentcar/models"
+	"api-rentcar/responses"
+	"api-rentcar/services"
+	"api-rentcar/spreadsheet"
+	"api-rentcar/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize limits the size of uploaded fleet spreadsheets
+const maxImportFileSize = 20 << 20 // 20 MB
+
+// CarImportController handles fleet import requests
+type CarImportController struct {
+	importService services.CarImportServiceInterface
+}
+
+// NewCarImportController creates a new car import controller
+func NewCarImportController(importService services.CarImportServiceInterface) *CarImportController {
+	return &CarImportController{
+		importService: importService,
+	}
+}
+
+// ImportCars godoc
+// @Summary Import cars from a spreadsheet
+// @Description Create or update cars, matched by license plate, from a CSV or XLSX file whose first row holds the column headers. Every row is validated like a car creation; rows that fail are listed with their errors and skipped. Columns named after the car fields, e.g., "license_plate" or "License Plate", are mapped automatically, others through the mapping field. With dry_run nothing is written. Large files, or any file when async is set, are imported in the background: the response is 202 with a job to poll.
+// @Tags cars
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or XLSX file"
+// @Param mapping formData string false "JSON object mapping car fields to column headers, e.g., {\"license_plate\": \"Plate No\"}"
+// @Param dry_run formData bool false "Only validate the file" default(false)
+// @Param async formData bool false "Import in the background regardless of the file size" default(false)
+// @Param Idempotency-Key header string false "Unique key that makes retries of the request return the first response"
+// @Success 200 {object} utils.Envelope{data=responses.ImportJobResponse}
+// @Success 202 {object} utils.Envelope{data=responses.ImportJobResponse}
+// @Failure 400 {object} utils.Envelope
+// @Failure 409 {object} utils.Envelope
+// @Failure 413 {object} utils.Envelope
+// @Failure 422 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
+// @Router /cars/import [post]
+func (c *CarImportController) ImportCars(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportFileSize)
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.SendErrorResponse(ctx, http.StatusRequestEntityTooLarge, "File too large", err)
+			return
+		}
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "File is required", err)
+		return
+	}
+
+	format, err := spreadsheet.DetectFormat(fileHeader.Filename)
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Unsupported file type", err)
+		return
+	}
+
+	imp := &services.CarImport{}
+	if mapping := ctx.PostForm("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &imp.Mapping); err != nil {
+			utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid column mapping", err)
+			return
+		}
+	}
+	if imp.DryRun, err = parseFormBool(ctx, "dry_run"); err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid dry_run value", err)
+		return
+	}
+	if imp.Async, err = parseFormBool(ctx, "async"); err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid async value", err)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Failed to read file", err)
+		return
+	}
+	defer file.Close()
+
+	imp.Header, imp.Rows, err = spreadsheet.Read(file, format)
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Failed to read file", err)
+		return
+	}
+
+	job, err := c.importService.ImportCars(ctx.Request.Context(), imp)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidImportMapping) {
+			utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid column mapping", err)
+			return
+		}
+		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to import cars", err)
+		return
+	}
+
+	response := responses.ToImportJobResponse(job)
+	switch job.Status {
+	case models.ImportStatusCompleted:
+		utils.SendDataResponse(ctx, http.StatusOK, response)
+	case models.ImportStatusFailed:
+		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to import cars", errors.New(job.Error))
+	default:
+		ctx.Header("Location", ctx.Request.URL.Path+"/"+job.ID)
+		utils.SendDataResponse(ctx, http.StatusAccepted, response)
+	}
+}
+
+// GetImportJob godoc
+// @Summary Get a fleet import job
+// @Description Get the progress and per-row error report of a fleet import
+// @Tags cars
+// @Accept json
+// @Produce json
+// @Param id path string true "Import job ID"
+// @Success 200 {object} utils.Envelope{data=responses.ImportJobResponse}
+// @Failure 404 {object} utils.Envelope
+// @Router /cars/import/{id} [get]
+func (c *CarImportController) GetImportJob(ctx *gin.Context) {
+	job, err := c.importService.GetImportJob(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusNotFound, "Import job not found", err)
+		return
+	}
+
+	response := responses.ToImportJobResponse(job)
+	utils.SendDataResponse(ctx, http.StatusOK, response)
+}
+
+// parseFormBool parses an optional boolean form field
+func parseFormBool(ctx *gin.Context, key string) (bool, error) {
+	value := ctx.PostForm(key)
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
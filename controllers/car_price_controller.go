@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	requests "api-rentcar/requests"
+	"api-rentcar/responses"
+	"api-rentcar/services"
+	"api-rentcar/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CarPriceController handles car price history requests
+type CarPriceController struct {
+	priceService services.CarPriceServiceInterface
+}
+
+// NewCarPriceController creates a new car price controller
+func NewCarPriceController(priceService services.CarPriceServiceInterface) *CarPriceController {
+	return &CarPriceController{
+		priceService: priceService,
+	}
+}
+
+// GetCarPrices godoc
+// @Summary Get the price history of a car
+// @Description Get every change of a car's price tiers with the time it took effect, and the scheduled changes, latest effective first. With at, only the prices in effect at that time are returned, e.g., to find the rate of a past booking.
+// @Tags cars
+// @Accept json
+// @Produce json
+// @Produce application/msgpack
+// @Produce text/csv
+// @Param id path int true "Car ID"
+// @Param at query string false "RFC 3339 time or date (YYYY-MM-DD, start of the day in UTC) to get the prices in effect at"
+// @Success 200 {object} utils.Envelope{data=[]responses.CarPriceResponse}
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 406 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
+// @Router /cars/{id}/prices [get]
+func (c *CarPriceController) GetCarPrices(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid car ID", err)
+		return
+	}
+
+	if at := ctx.Query("at"); at != "" {
+		atTime, err := parseTimeOrDate(at)
+		if err != nil {
+			utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid at", err)
+			return
+		}
+		price, err := c.priceService.GetCarPriceAt(ctx.Request.Context(), uint(id), atTime)
+		if err != nil {
+			sendCarPriceError(ctx, err, "Failed to fetch car prices")
+			return
+		}
+		utils.SendDataResponse(ctx, http.StatusOK, []responses.CarPriceResponse{responses.ToCarPriceResponse(price)})
+		return
+	}
+
+	prices, err := c.priceService.GetCarPrices(ctx.Request.Context(), uint(id))
+	if err != nil {
+		sendCarPriceError(ctx, err, "Failed to fetch car prices")
+		return
+	}
+
+	utils.SendDataResponse(ctx, http.StatusOK, responses.ToCarPriceResponses(prices))
+}
+
+// ScheduleCarPrice godoc
+// @Summary Schedule a change of a car's prices
+// @Description Schedule new price tiers for a car from a future time. They are applied to the car automatically once effective_from passes, which updates its version like any change, and are then part of its price history.
+// @Tags cars
+// @Accept json
+// @Produce json
+// @Param id path int true "Car ID"
+// @Param price body requests.ScheduleCarPriceRequest true "Scheduled price change"
+// @Param Idempotency-Key header string false "Unique key that makes retries of the request return the first response"
+// @Success 201 {object} utils.Envelope{data=responses.CarPriceResponse}
+// @Header 201 {string} Location "URL of the scheduled price change"
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 409 {object} utils.Envelope
+// @Failure 422 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
+// @Router /cars/{id}/prices [post]
+func (c *CarPriceController) ScheduleCarPrice(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid car ID", err)
+		return
+	}
+
+	var req requests.ScheduleCarPriceRequest
+	if !utils.BindAndValidate(ctx, &req) {
+		return
+	}
+
+	price, err := c.priceService.ScheduleCarPrice(ctx.Request.Context(), uint(id), &req)
+	if err != nil {
+		sendCarPriceError(ctx, err, "Failed to schedule car price")
+		return
+	}
+
+	utils.SendCreatedResponse(ctx, "Car price scheduled successfully", price.ID, responses.ToCarPriceResponse(price))
+}
+
+// CancelCarPrice godoc
+// @Summary Cancel a scheduled change of a car's prices
+// @Description Cancel a price change that hasn't been applied yet. Applied prices are history and can't be removed.
+// @Tags cars
+// @Accept json
+// @Produce json
+// @Param id path int true "Car ID"
+// @Param price_id path int true "Car price ID"
+// @Success 200 {object} utils.Envelope
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 409 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
+// @Router /cars/{id}/prices/{price_id} [delete]
+func (c *CarPriceController) CancelCarPrice(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid car ID", err)
+		return
+	}
+	priceID, err := strconv.ParseUint(ctx.Param("price_id"), 10, 32)
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid car price ID", err)
+		return
+	}
+
+	if err := c.priceService.CancelCarPrice(ctx.Request.Context(), uint(id), uint(priceID)); err != nil {
+		sendCarPriceError(ctx, err, "Failed to cancel car price")
+		return
+	}
+
+	utils.SendSuccessResponse(ctx, http.StatusOK, "Car price cancelled successfully", nil)
+}
+
+// sendCarPriceError sends the response for an error of the car price service
+func sendCarPriceError(ctx *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "car not found":
+		utils.SendErrorResponse(ctx, http.StatusNotFound, "Car not found", err)
+	case "car price not found", "no car price at that time":
+		utils.SendErrorResponse(ctx, http.StatusNotFound, "Car price not found", err)
+	case "car price already applied":
+		utils.SendErrorResponse(ctx, http.StatusConflict, "Car price already applied", err)
+	case "effective_from must be in the future":
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid effective_from", err)
+	default:
+		utils.SendErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
+
+// parseTimeOrDate parses an RFC 3339 time, or a date as the start of that day
+// in UTC
+func parseTimeOrDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errors.New("expected an RFC 3339 time or a YYYY-MM-DD date")
+}
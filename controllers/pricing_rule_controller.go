@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	requests "api-rentcar/requests"
+	"api-rentcar/responses"
+	"api-rentcar/services"
+	"api-rentcar/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PricingRuleController handles pricing rule requests
+type PricingRuleController struct {
+	ruleService services.PricingRuleServiceInterface
+}
+
+// NewPricingRuleController creates a new pricing rule controller
+func NewPricingRuleController(ruleService services.PricingRuleServiceInterface) *PricingRuleController {
+	return &PricingRuleController{
+		ruleService: ruleService,
+	}
+}
+
+// CreatePricingRule godoc
+// @Summary Create a pricing rule
+// @Description Create a rule adjusting the daily price of the cars in its category and brand scope on the days it covers, by a percentage or a fixed amount. Days are covered between start_date and end_date, inclusive, and on the listed weekdays; empty fields don't restrict the rule. When several rules cover a day, the one with the highest priority applies.
+// @Tags pricing-rules
+// @Accept json
+// @Produce json
+// @Param rule body requests.CreatePricingRuleRequest true "Pricing rule"
+// @Param Idempotency-Key header string false "Unique key that makes retries of the request return the first response"
+// @Success 201 {object} utils.Envelope{data=responses.PricingRuleResponse}
+// @Header 201 {string} Location "URL of the created pricing rule"
+// @Failure 400 {object} utils.Envelope
+// @Failure 409 {object} utils.Envelope
+// @Failure 422 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
+// @Router /pricing-rules [post]
+func (c *PricingRuleController) CreatePricingRule(ctx *gin.Context) {
+	var req requests.CreatePricingRuleRequest
+	if !utils.BindAndValidate(ctx, &req) {
+		return
+	}
+
+	rule, err := c.ruleService.CreatePricingRule(ctx.Request.Context(), &req)
+	if err != nil {
+		sendPricingRuleError(ctx, err, "Failed to create pricing rule")
+		return
+	}
+
+	utils.SendCreatedResponse(ctx, "Pricing rule created successfully", rule.ID, responses.ToPricingRuleResponse(rule))
+}
+
+// GetPricingRules godoc
+// @Summary Get all pricing rules
+// @Description Get a list of pricing rules, highest priority first, with optional pagination and filtering
+// @Tags pricing-rules
+// @Accept json
+// @Produce json
+// @Produce application/msgpack
+// @Produce text/csv
+// @Param active query bool false "Only active (true) or inactive (false) rules"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} utils.Envelope{data=[]responses.PricingRuleResponse}
+// @Failure 400 {object} utils.Envelope
+// @Failure 406 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
+// @Router /pricing-rules [get]
+func (c *PricingRuleController) GetPricingRules(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	page, limit = utils.NormalizePagination(page, limit)
+
+	var active *bool
+	if value := ctx.Query("active"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid active", err)
+			return
+		}
+		active = &parsed
+	}
+
+	rules, total, err := c.ruleService.GetPricingRules(ctx.Request.Context(), page, limit, active)
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch pricing rules", err)
+		return
+	}
+
+	utils.SendPaginatedResponse(ctx, "Pricing rules retrieved successfully", responses.ToPricingRuleResponses(rules), total, page, limit)
+}
+
+// GetPricingRule godoc
+// @Summary Get a pricing rule by ID
+// @Description Get a single pricing rule by its ID
+// @Tags pricing-rules
+// @Accept json
+// @Produce json
+// @Produce application/msgpack
+// @Produce text/csv
+// @Param id path int true "Pricing rule ID"
+// @Success 200 {object} utils.Envelope{data=responses.PricingRuleResponse}
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 406 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
+// @Router /pricing-rules/{id} [get]
+func (c *PricingRuleController) GetPricingRule(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid pricing rule ID", err)
+		return
+	}
+
+	rule, err := c.ruleService.GetPricingRuleByID(ctx.Request.Context(), uint(id))
+	if err != nil {
+		sendPricingRuleError(ctx, err, "Failed to fetch pricing rule")
+		return
+	}
+
+	utils.SendSuccessResponse(ctx, http.StatusOK, "Pricing rule retrieved successfully", responses.ToPricingRuleResponse(rule))
+}
+
+// ReplacePricingRule godoc
+// @Summary Replace a pricing rule
+// @Description Replace all fields of an existing pricing rule; omitted optional fields are cleared
+// @Tags pricing-rules
+// @Accept json
+// @Produce json
+// @Param id path int true "Pricing rule ID"
+// @Param rule body requests.CreatePricingRuleRequest true "Pricing rule"
+// @Success 200 {object} utils.Envelope{data=responses.PricingRuleResponse}
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 422 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
+// @Router /pricing-rules/{id} [put]
+func (c *PricingRuleController) ReplacePricingRule(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid pricing rule ID", err)
+		return
+	}
+
+	var req requests.CreatePricingRuleRequest
+	if !utils.BindAndValidate(ctx, &req) {
+		return
+	}
+
+	rule, err := c.ruleService.ReplacePricingRule(ctx.Request.Context(), uint(id), &req)
+	if err != nil {
+		sendPricingRuleError(ctx, err, "Failed to update pricing rule")
+		return
+	}
+
+	utils.SendWriteResponse(ctx, http.StatusOK, "Pricing rule updated successfully", responses.ToPricingRuleResponse(rule))
+}
+
+// DeletePricingRule godoc
+// @Summary Delete a pricing rule
+// @Description Delete a pricing rule by its ID
+// @Tags pricing-rules
+// @Accept json
+// @Produce json
+// @Param id path int true "Pricing rule ID"
+// @Success 200 {object} utils.Envelope
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
+// @Router /pricing-rules/{id} [delete]
+func (c *PricingRuleController) DeletePricingRule(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid pricing rule ID", err)
+		return
+	}
+
+	if err := c.ruleService.DeletePricingRule(ctx.Request.Context(), uint(id)); err != nil {
+		sendPricingRuleError(ctx, err, "Failed to delete pricing rule")
+		return
+	}
+
+	utils.SendSuccessResponse(ctx, http.StatusOK, "Pricing rule deleted successfully", nil)
+}
+
+// PreviewPricing godoc
+// @Summary Preview the price of renting a car
+// @Description Price renting a car from start_date, the pickup day, to end_date, the return day, with the active pricing rules. The days are priced with the car's monthly (30 days), weekly (7 days) and daily tiers, and the tiered price is spread evenly over them, each day's share using the car's prices in effect that day, scheduled price changes included; each share is then adjusted by the rule with the highest priority covering it. The breakdown shows the price of each day and the rule applied to it.
+// @Tags pricing-rules
+// @Accept json
+// @Produce json
+// @Produce application/msgpack
+// @Param car_id query int true "Car ID"
+// @Param start_date query string true "Pickup day (YYYY-MM-DD)"
+// @Param end_date query string true "Return day (YYYY-MM-DD), at most 366 days after start_date"
+// @Success 200 {object} utils.Envelope{data=responses.PriceQuoteResponse}
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 406 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
+// @Router /pricing-rules/preview [get]
+func (c *PricingRuleController) PreviewPricing(ctx *gin.Context) {
+	carID, err := strconv.ParseUint(ctx.Query("car_id"), 10, 32)
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid car_id", err)
+		return
+	}
+	startDate, err := time.Parse(time.DateOnly, ctx.Query("start_date"))
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid start_date", errors.New("expected a YYYY-MM-DD date"))
+		return
+	}
+	endDate, err := time.Parse(time.DateOnly, ctx.Query("end_date"))
+	if err != nil {
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid end_date", errors.New("expected a YYYY-MM-DD date"))
+		return
+	}
+
+	quote, err := c.ruleService.QuoteRental(ctx.Request.Context(), uint(carID), startDate, endDate)
+	if err != nil {
+		sendPricingRuleError(ctx, err, "Failed to price rental")
+		return
+	}
+
+	utils.SendDataResponse(ctx, http.StatusOK, responses.ToPriceQuoteResponse(quote))
+}
+
+// sendPricingRuleError sends the response for an error of the pricing rule
+// service
+func sendPricingRuleError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidPricingRule):
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid pricing rule", err)
+	case errors.Is(err, services.ErrInvalidRentalPeriod):
+		utils.SendErrorResponse(ctx, http.StatusBadRequest, "Invalid rental period", err)
+	case err.Error() == "pricing rule not found":
+		utils.SendErrorResponse(ctx, http.StatusNotFound, "Pricing rule not found", err)
+	case err.Error() == "car not found":
+		utils.SendErrorResponse(ctx, http.StatusNotFound, "Car not found", err)
+	default:
+		utils.SendErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
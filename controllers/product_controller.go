@@ -3,8 +3,10 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	requests "api-rentcar/requests"
+	"api-rentcar/responses"
 	"api-rentcar/services"
 	"api-rentcar/utils"
 	"github.com/gin-gonic/gin"
@@ -24,14 +26,20 @@ func NewProductController(productService services.ProductServiceInterface) *Prod
 
 // CreateProduct godoc
 // @Summary Create a new product
-// @Description Create a new product with the provided information
+// @Description Create a new product with the provided information. The created product is returned as data, unless the request sends Prefer: return=minimal.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param product body requests.CreateProductRequest true "Product creation request"
-// @Success 201 {object} utils.SuccessResponse
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Param Prefer header string false "return=minimal to omit the product from the response"
+// @Param Idempotency-Key header string false "Unique key that makes retries of the request return the first response"
+// @Success 201 {object} utils.Envelope{data=responses.ProductResponse}
+// @Header 201 {string} Location "URL of the created product"
+// @Header 201 {string} ETag "Version of the product"
+// @Failure 400 {object} utils.Envelope
+// @Failure 409 {object} utils.Envelope
+// @Failure 422 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
 // @Router /products [post]
 func (c *ProductController) CreateProduct(ctx *gin.Context) {
 	var req requests.CreateProductRequest
@@ -39,13 +47,14 @@ func (c *ProductController) CreateProduct(ctx *gin.Context) {
 		return
 	}
 
-	product, err := c.productService.CreateProduct(&req)
+	product, err := c.productService.CreateProduct(ctx.Request.Context(), &req)
 	if err != nil {
 		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to create product", err)
 		return
 	}
 
-	utils.SendSuccessResponse(ctx, http.StatusCreated, "Product created successfully", product)
+	ctx.Header("ETag", utils.ETag(product.Version))
+	utils.SendCreatedResponse(ctx, "Product created successfully", product.ID, responses.ToProductResponse(product))
 }
 
 // GetProducts godoc
@@ -54,35 +63,56 @@ func (c *ProductController) CreateProduct(ctx *gin.Context) {
 // @Tags products
 // @Accept json
 // @Produce json
+// @Produce application/msgpack
+// @Produce text/csv
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
-// @Success 200 {object} utils.SuccessResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Success 200 {object} utils.Envelope{data=[]responses.ProductResponse}
+// @Header 200 {string} Last-Modified "Latest update time of the listed products"
+// @Header 200 {string} Cache-Control "How long the list may be reused"
+// @Failure 406 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
 // @Router /products [get]
 func (c *ProductController) GetProducts(ctx *gin.Context) {
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
 
-	products, total, err := c.productService.GetProducts(page, limit)
+	products, total, err := c.productService.GetProducts(ctx.Request.Context(), page, limit)
 	if err != nil {
 		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch products", err)
 		return
 	}
 
-	utils.SendPaginatedResponse(ctx, "Products retrieved successfully", products, total, page, limit)
+	updatedAt := make([]time.Time, len(products))
+	for i := range products {
+		updatedAt[i] = products[i].UpdatedAt
+	}
+	utils.SetLastModified(ctx, updatedAt...)
+
+	response := responses.ToProductsListResponse(products, total, page, limit)
+	utils.SendPaginatedResponse(ctx, "Products retrieved successfully", response.Data, total, page, limit)
 }
 
 // GetProduct godoc
 // @Summary Get a product by ID
-// @Description Get a single product by its ID
+// @Description Get a single product by its ID; with If-None-Match and the ETag header, or If-Modified-Since and the Last-Modified header, an unchanged product returns 304.
 // @Tags products
 // @Accept json
 // @Produce json
+// @Produce application/msgpack
+// @Produce text/csv
 // @Param id path int true "Product ID"
-// @Success 200 {object} utils.SuccessResponse
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 404 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Param If-Modified-Since header string false "Last-Modified of a cached copy"
+// @Success 200 {object} utils.Envelope{data=responses.ProductResponse}
+// @Header 200 {string} ETag "Version of the product"
+// @Header 200 {string} Last-Modified "Time the product was last updated"
+// @Header 200 {string} Cache-Control "How long the product may be reused"
+// @Success 304 "Not modified"
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 406 {object} utils.Envelope
+// @Failure 500 {object} utils.Envelope
 // @Router /products/{id} [get]
 func (c *ProductController) GetProduct(ctx *gin.Context) {
 	idParam := ctx.Param("id")
@@ -92,7 +122,7 @@ func (c *ProductController) GetProduct(ctx *gin.Context) {
 		return
 	}
 
-	product, err := c.productService.GetProductByID(uint(id))
+	product, err := c.productService.GetProductByID(ctx.Request.Context(), uint(id))
 	if err != nil {
 		if err.Error() == "product not found" {
 			utils.SendErrorResponse(ctx, http.StatusNotFound, "Product not found", err)
@@ -102,21 +132,35 @@ func (c *ProductController) GetProduct(ctx *gin.Context) {
 		return
 	}
 
-	utils.SendSuccessResponse(ctx, http.StatusOK, "Product retrieved successfully", product)
+	etag := utils.ETag(product.Version)
+	ctx.Header("ETag", etag)
+	utils.SetLastModified(ctx, product.UpdatedAt)
+	if ifNoneMatch := ctx.GetHeader("If-None-Match"); utils.MatchesIfNoneMatch(ifNoneMatch, etag) ||
+		(ifNoneMatch == "" && utils.NotModifiedSince(ctx.GetHeader("If-Modified-Since"), product.UpdatedAt)) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	utils.SendSuccessResponse(ctx, http.StatusOK, "Product retrieved successfully", responses.ToProductResponse(product))
 }
 
 // UpdateProduct godoc
 // @Summary Update a product
-// @Description Update an existing product with the provided information
+// @Description Update an existing product with the provided information. The updated product is returned as data, unless the request sends Prefer: return=minimal. With If-Match, the product is only updated if nobody changed it since it was read. If-Match is required when the server sets REQUIRE_IF_MATCH.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param id path int true "Product ID"
+// @Param If-Match header string false "ETag the product must still have, from GET /products/{id}"
 // @Param product body requests.UpdateProductRequest true "Product update request"
-// @Success 200 {object} utils.SuccessResponse
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 404 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Param Prefer header string false "return=minimal to omit the product from the response"
+// @Success 200 {object} utils.Envelope{data=responses.ProductResponse}
+// @Header 200 {string} ETag "New version of the product"
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 412 {object} utils.Envelope
+// @Failure 428 {object} utils.Envelope "If-Match is missing while REQUIRE_IF_MATCH is set"
+// @Failure 500 {object} utils.Envelope
 // @Router /products/{id} [put]
 func (c *ProductController) UpdateProduct(ctx *gin.Context) {
 	idParam := ctx.Param("id")
@@ -126,35 +170,47 @@ func (c *ProductController) UpdateProduct(ctx *gin.Context) {
 		return
 	}
 
+	version, ok := parseIfMatch(ctx, "Product")
+	if !ok {
+		return
+	}
+
 	var req requests.UpdateProductRequest
 	if !utils.BindAndValidate(ctx, &req) {
 		return
 	}
 
-	product, err := c.productService.UpdateProduct(uint(id), &req)
+	product, err := c.productService.UpdateProduct(ctx.Request.Context(), uint(id), version, &req)
 	if err != nil {
-		if err.Error() == "product not found" {
+		switch err.Error() {
+		case "product not found":
 			utils.SendErrorResponse(ctx, http.StatusNotFound, "Product not found", err)
-			return
+		case "product version mismatch":
+			utils.SendErrorResponse(ctx, http.StatusPreconditionFailed, "Product was modified by another request", err)
+		default:
+			utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to update product", err)
 		}
-		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to update product", err)
 		return
 	}
 
-	utils.SendSuccessResponse(ctx, http.StatusOK, "Product updated successfully", product)
+	ctx.Header("ETag", utils.ETag(product.Version))
+	utils.SendWriteResponse(ctx, http.StatusOK, "Product updated successfully", responses.ToProductResponse(product))
 }
 
 // DeleteProduct godoc
 // @Summary Delete a product
-// @Description Delete a product by its ID
+// @Description Delete a product by its ID. With If-Match, the product is only deleted if nobody changed it since it was read. If-Match is required when the server sets REQUIRE_IF_MATCH.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param id path int true "Product ID"
-// @Success 200 {object} utils.SuccessResponse
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 404 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
+// @Param If-Match header string false "ETag the product must still have, from GET /products/{id}"
+// @Success 200 {object} utils.Envelope
+// @Failure 400 {object} utils.Envelope
+// @Failure 404 {object} utils.Envelope
+// @Failure 412 {object} utils.Envelope
+// @Failure 428 {object} utils.Envelope "If-Match is missing while REQUIRE_IF_MATCH is set"
+// @Failure 500 {object} utils.Envelope
 // @Router /products/{id} [delete]
 func (c *ProductController) DeleteProduct(ctx *gin.Context) {
 	idParam := ctx.Param("id")
@@ -164,13 +220,21 @@ func (c *ProductController) DeleteProduct(ctx *gin.Context) {
 		return
 	}
 
-	err = c.productService.DeleteProduct(uint(id))
+	version, ok := parseIfMatch(ctx, "Product")
+	if !ok {
+		return
+	}
+
+	err = c.productService.DeleteProduct(ctx.Request.Context(), uint(id), version)
 	if err != nil {
-		if err.Error() == "product not found" {
+		switch err.Error() {
+		case "product not found":
 			utils.SendErrorResponse(ctx, http.StatusNotFound, "Product not found", err)
-			return
+		case "product version mismatch":
+			utils.SendErrorResponse(ctx, http.StatusPreconditionFailed, "Product was modified by another request", err)
+		default:
+			utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete product", err)
 		}
-		utils.SendErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete product", err)
 		return
 	}
 
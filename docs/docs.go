@@ -24,6 +24,122 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/audit": {
+            "get": {
+                "description": "Get the changes made to entities of a type, e.g., car, or to one entity, newest first. Each entry has who made the change, from which IP and request, and the changed fields before and after. Requires the admin bearer token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Get the audit log of an entity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer followed by the admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity type, e.g., car or product",
+                        "name": "entity",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "create",
+                            "update",
+                            "delete"
+                        ],
+                        "type": "string",
+                        "description": "Only changes of this action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/responses.AuditLogResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            }
+        },
         "/cars": {
             "get": {
                 "description": "Get a list of cars with optional pagination and filtering",
@@ -31,7 +147,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/msgpack",
+                    "text/csv"
                 ],
                 "tags": [
                     "cars"
@@ -57,25 +175,68 @@ const docTemplate = `{
                         "description": "Filter by availability",
                         "name": "available",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields to return, e.g., id,name,price_per_day,is_available; all by default",
+                        "name": "fields",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/responses.CarsListResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/responses.CarResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "How long the list may be reused"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Latest update time of the listed cars"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a new car with the provided information",
+                "description": "Create a new car with the provided information. The created car is returned as data, unless the request sends Prefer: return=minimal.",
                 "consumes": [
                     "application/json"
                 ],
@@ -95,33 +256,79 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/requests.CreateCarRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "return=minimal to omit the car from the response",
+                        "name": "Prefer",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key that makes retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/utils.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.CarResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the car"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created car"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     }
                 }
             }
         },
-        "/cars/{id}": {
-            "get": {
-                "description": "Get a single car by its ID",
+        "/cars/bulk": {
+            "post": {
+                "description": "Create several cars in a single transaction. Every item is validated like a single car creation and errors are reported per index. In atomic mode (default) no car is created if any item fails; in partial mode the valid cars are created.",
                 "consumes": [
                     "application/json"
                 ],
@@ -131,45 +338,89 @@ const docTemplate = `{
                 "tags": [
                     "cars"
                 ],
-                "summary": "Get a car by ID",
+                "summary": "Create several cars",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Car ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Bulk car creation request",
+                        "name": "cars",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.BulkCreateCarsRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key that makes retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/responses.CarResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.BulkCarsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "207": {
+                        "description": "Some items failed in partial mode",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.BulkCarsResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid items in atomic mode, with their errors",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "422": {
+                        "description": "Items failed and atomic mode rolled back, with their errors",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Update an existing car with the provided information",
+            "delete": {
+                "description": "Delete several cars by ID in a single transaction, with errors reported per index. In atomic mode (default) no car is deleted if any of them doesn't exist; in partial mode the existing cars are deleted.",
                 "consumes": [
                     "application/json"
                 ],
@@ -179,22 +430,15 @@ const docTemplate = `{
                 "tags": [
                     "cars"
                 ],
-                "summary": "Update a car",
+                "summary": "Delete several cars",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Car ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Car update request",
-                        "name": "car",
+                        "description": "Bulk car deletion request",
+                        "name": "cars",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/requests.UpdateCarRequest"
+                            "$ref": "#/definitions/requests.BulkDeleteCarsRequest"
                         }
                     }
                 ],
@@ -202,31 +446,61 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/utils.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.BulkCarsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "207": {
+                        "description": "Some items failed in partial mode",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.BulkCarsResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid items in atomic mode, with their errors",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "422": {
+                        "description": "Items failed and atomic mode rolled back, with their errors",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     }
                 }
             },
-            "delete": {
-                "description": "Delete a car by its ID",
+            "patch": {
+                "description": "Update several cars in a single transaction. Every item is validated like a single car update and errors are reported per index. In atomic mode (default) no car is updated if any item fails; in partial mode the other cars are updated.",
                 "consumes": [
                     "application/json"
                 ],
@@ -236,136 +510,258 @@ const docTemplate = `{
                 "tags": [
                     "cars"
                 ],
-                "summary": "Delete a car",
+                "summary": "Update several cars",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Car ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Bulk car update request",
+                        "name": "cars",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.BulkUpdateCarsRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/utils.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.BulkCarsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "207": {
+                        "description": "Some items failed in partial mode",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.BulkCarsResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid items in atomic mode, with their errors",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "422": {
+                        "description": "Items failed and atomic mode rolled back, with their errors",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     }
                 }
             }
         },
-        "/products": {
+        "/cars/export": {
             "get": {
-                "description": "Get a list of products with optional pagination and filtering",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Download the cars matching the filters of the car list as CSV, XLSX or newline-delimited JSON. The file is streamed as it is read from the database.",
                 "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+                    "application/x-ndjson",
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "cars"
                 ],
-                "summary": "Get all products",
+                "summary": "Export cars",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
+                        "enum": [
+                            "csv",
+                            "xlsx",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "File format",
+                        "name": "format",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Items per page",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "Comma-separated columns to export, e.g., id,name,license_plate; all by default",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by availability",
+                        "name": "available",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Exported file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/utils.SuccessResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/cars/import": {
             "post": {
-                "description": "Create a new product with the provided information",
+                "description": "Create or update cars, matched by license plate, from a CSV or XLSX file whose first row holds the column headers. Every row is validated like a car creation; rows that fail are listed with their errors and skipped. Columns named after the car fields, e.g., \"license_plate\" or \"License Plate\", are mapped automatically, others through the mapping field. With dry_run nothing is written. Large files, or any file when async is set, are imported in the background: the response is 202 with a job to poll.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "cars"
                 ],
-                "summary": "Create a new product",
+                "summary": "Import cars from a spreadsheet",
                 "parameters": [
                     {
-                        "description": "Product creation request",
-                        "name": "product",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/requests.CreateProductRequest"
-                        }
+                        "type": "file",
+                        "description": "CSV or XLSX file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSON object mapping car fields to column headers, e.g., {\\",
+                        "name": "mapping",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Only validate the file",
+                        "name": "dry_run",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Import in the background regardless of the file size",
+                        "name": "async",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key that makes retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.ImportJobResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/utils.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.ImportJobResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     }
                 }
             }
         },
-        "/products/{id}": {
+        "/cars/import/{id}": {
             "get": {
-                "description": "Get a single product by its ID",
+                "description": "Get the progress and per-row error report of a fleet import",
                 "consumes": [
                     "application/json"
                 ],
@@ -373,13 +769,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "cars"
                 ],
-                "summary": "Get a product by ID",
+                "summary": "Get a fleet import job",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Product ID",
+                        "type": "string",
+                        "description": "Import job ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -389,88 +785,282 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/utils.SuccessResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.ImportJobResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/cars/stats": {
+            "get": {
+                "description": "Get car counts by brand, category, transmission, availability and year, and daily price ranges per category",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cars"
+                ],
+                "summary": "Get fleet statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.CarStatsResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update an existing product with the provided information",
+            }
+        },
+        "/cars/{id}": {
+            "get": {
+                "description": "Get a single car by its ID. The ETag header holds the car's version, to send back in If-Match when updating or deleting it; with If-None-Match, or If-Modified-Since and the Last-Modified header, an unchanged car returns 304.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/msgpack",
+                    "text/csv"
                 ],
                 "tags": [
-                    "products"
+                    "cars"
                 ],
-                "summary": "Update a product",
+                "summary": "Get a car by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "Car ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Product update request",
-                        "name": "product",
+                        "type": "string",
+                        "description": "Comma-separated fields to return, e.g., id,name,price_per_day,is_available; all by default",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of a cached copy",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.CarResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "How long the car may be reused"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the car"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time the car was last updated"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace all fields of an existing car. The body is validated like a car creation; use PATCH to change some fields only. The updated car is returned as data, unless the request sends Prefer: return=minimal. With If-Match, the car is only replaced if nobody changed it since it was read. If-Match is required when the server sets REQUIRE_IF_MATCH.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cars"
+                ],
+                "summary": "Replace a car",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Car ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the car must still have, from GET /cars/{id}",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Car replacement request",
+                        "name": "car",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/requests.UpdateProductRequest"
+                            "$ref": "#/definitions/requests.CreateCarRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "return=minimal to omit the car from the response",
+                        "name": "Prefer",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/utils.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.CarResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the car"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing while REQUIRE_IF_MATCH is set",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Delete a product by its ID",
+                "description": "Delete a car by its ID. With If-Match, the car is only deleted if nobody changed it since it was read. If-Match is required when the server sets REQUIRE_IF_MATCH.",
                 "consumes": [
                     "application/json"
                 ],
@@ -478,153 +1068,2190 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "cars"
                 ],
-                "summary": "Delete a product",
+                "summary": "Delete a car",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "Car ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the car must still have, from GET /cars/{id}",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing while REQUIRE_IF_MATCH is set",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update an existing car with a JSON Merge Patch (RFC 7396), where fields present replace the current values and null clears them, or with a JSON Patch (RFC 6902) sent as application/json-patch+json. The patched car is validated like a car creation and returned as data, unless the request sends Prefer: return=minimal. With If-Match, the car is only updated if nobody changed it since it was read. If-Match is required when the server sets REQUIRE_IF_MATCH.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cars"
+                ],
+                "summary": "Update some fields of a car",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Car ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the car must still have, from GET /cars/{id}",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Car merge patch",
+                        "name": "car",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.PatchCarRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "return=minimal to omit the car from the response",
+                        "name": "Prefer",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/utils.SuccessResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.CarResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the car"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing while REQUIRE_IF_MATCH is set",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/utils.ErrorResponse"
+                            "$ref": "#/definitions/utils.Envelope"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Brand": {
-            "type": "string",
-            "enum": [
-                "Toyota",
-                "Honda",
-                "Mercedes",
-                "Wuling",
-                "Mitsubishi",
-                "Volkswagen",
-                "Jeep",
-                "Subaru",
-                "Hyundai",
-                "Kia",
-                "Renault",
-                "Volvo",
-                "Chevrolet",
-                "Ford",
-                "BMW"
-            ],
-            "x-enum-varnames": [
-                "Toyota",
-                "Honda",
-                "Mercedes",
-                "Wuling",
-                "Mitsubishi",
-                "Volkswagen",
-                "Jeep",
-                "Subaru",
-                "Hyundai",
-                "Kia",
-                "Renault",
-                "Volvo",
-                "Chevrolet",
-                "Ford",
-                "BMW"
-            ]
-        },
-        "models.CarCategory": {
-            "type": "string",
-            "enum": [
-                "City Car",
-                "LCGC",
-                "Compact",
-                "MPV",
-                "SUV",
-                "Crossover"
-            ],
-            "x-enum-varnames": [
-                "CityCar",
-                "LCGC",
-                "Compact",
-                "MPV",
-                "SUV",
-                "Crossover"
-            ]
         },
-        "models.TransmissionType": {
-            "type": "string",
-            "enum": [
-                "Automatic",
-                "Manual"
-            ],
-            "x-enum-varnames": [
-                "Automatic",
-                "Manual"
-            ]
+        "/cars/{id}/prices": {
+            "get": {
+                "description": "Get every change of a car's price tiers with the time it took effect, and the scheduled changes, latest effective first. With at, only the prices in effect at that time are returned, e.g., to find the rate of a past booking.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "text/csv"
+                ],
+                "tags": [
+                    "cars"
+                ],
+                "summary": "Get the price history of a car",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Car ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time or date (YYYY-MM-DD, start of the day in UTC) to get the prices in effect at",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/responses.CarPriceResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Schedule new price tiers for a car from a future time. They are applied to the car automatically once effective_from passes, which updates its version like any change, and are then part of its price history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cars"
+                ],
+                "summary": "Schedule a change of a car's prices",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Car ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Scheduled price change",
+                        "name": "price",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.ScheduleCarPriceRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key that makes retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.CarPriceResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the scheduled price change"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/cars/{id}/prices/{price_id}": {
+            "delete": {
+                "description": "Cancel a price change that hasn't been applied yet. Applied prices are history and can't be removed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cars"
+                ],
+                "summary": "Cancel a scheduled change of a car's prices",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Car ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Car price ID",
+                        "name": "price_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing-rules": {
+            "get": {
+                "description": "Get a list of pricing rules, highest priority first, with optional pagination and filtering",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "text/csv"
+                ],
+                "tags": [
+                    "pricing-rules"
+                ],
+                "summary": "Get all pricing rules",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only active (true) or inactive (false) rules",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/responses.PricingRuleResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a rule adjusting the daily price of the cars in its category and brand scope on the days it covers, by a percentage or a fixed amount. Days are covered between start_date and end_date, inclusive, and on the listed weekdays; empty fields don't restrict the rule. When several rules cover a day, the one with the highest priority applies.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing-rules"
+                ],
+                "summary": "Create a pricing rule",
+                "parameters": [
+                    {
+                        "description": "Pricing rule",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.CreatePricingRuleRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key that makes retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.PricingRuleResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created pricing rule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing-rules/preview": {
+            "get": {
+                "description": "Price renting a car from start_date, the pickup day, to end_date, the return day, with the active pricing rules. The days are priced with the car's monthly (30 days), weekly (7 days) and daily tiers, and the tiered price is spread evenly over them, each day's share using the car's prices in effect that day, scheduled price changes included; each share is then adjusted by the rule with the highest priority covering it. The breakdown shows the price of each day and the rule applied to it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "pricing-rules"
+                ],
+                "summary": "Preview the price of renting a car",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Car ID",
+                        "name": "car_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pickup day (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return day (YYYY-MM-DD), at most 366 days after start_date",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.PriceQuoteResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/pricing-rules/{id}": {
+            "get": {
+                "description": "Get a single pricing rule by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "text/csv"
+                ],
+                "tags": [
+                    "pricing-rules"
+                ],
+                "summary": "Get a pricing rule by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Pricing rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.PricingRuleResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace all fields of an existing pricing rule; omitted optional fields are cleared",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing-rules"
+                ],
+                "summary": "Replace a pricing rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Pricing rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Pricing rule",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.CreatePricingRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.PricingRuleResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a pricing rule by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pricing-rules"
+                ],
+                "summary": "Delete a pricing rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Pricing rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Get a list of products with optional pagination and filtering",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "text/csv"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get all products",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/responses.ProductResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "How long the list may be reused"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Latest update time of the listed products"
+                            }
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new product with the provided information. The created product is returned as data, unless the request sends Prefer: return=minimal.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Create a new product",
+                "parameters": [
+                    {
+                        "description": "Product creation request",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.CreateProductRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "return=minimal to omit the product from the response",
+                        "name": "Prefer",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key that makes retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.ProductResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the product"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}": {
+            "get": {
+                "description": "Get a single product by its ID; with If-None-Match and the ETag header, or If-Modified-Since and the Last-Modified header, an unchanged product returns 304.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "text/csv"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Get a product by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified of a cached copy",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.ProductResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "How long the product may be reused"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the product"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time the product was last updated"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update an existing product with the provided information. The updated product is returned as data, unless the request sends Prefer: return=minimal. With If-Match, the product is only updated if nobody changed it since it was read. If-Match is required when the server sets REQUIRE_IF_MATCH.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Update a product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the product must still have, from GET /products/{id}",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Product update request",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.UpdateProductRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "return=minimal to omit the product from the response",
+                        "name": "Prefer",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/utils.Envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/responses.ProductResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing while REQUIRE_IF_MATCH is set",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a product by its ID. With If-Match, the product is only deleted if nobody changed it since it was read. If-Match is required when the server sets REQUIRE_IF_MATCH.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Delete a product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the product must still have, from GET /products/{id}",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match is missing while REQUIRE_IF_MATCH is set",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Envelope"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.Brand": {
+            "type": "string",
+            "enum": [
+                "Toyota",
+                "Honda",
+                "Mercedes",
+                "Wuling",
+                "Mitsubishi",
+                "Volkswagen",
+                "Jeep",
+                "Subaru",
+                "Hyundai",
+                "Kia",
+                "Renault",
+                "Volvo",
+                "Chevrolet",
+                "Ford",
+                "BMW"
+            ],
+            "x-enum-varnames": [
+                "Toyota",
+                "Honda",
+                "Mercedes",
+                "Wuling",
+                "Mitsubishi",
+                "Volkswagen",
+                "Jeep",
+                "Subaru",
+                "Hyundai",
+                "Kia",
+                "Renault",
+                "Volvo",
+                "Chevrolet",
+                "Ford",
+                "BMW"
+            ]
+        },
+        "models.CarCategory": {
+            "type": "string",
+            "enum": [
+                "City Car",
+                "LCGC",
+                "Compact",
+                "MPV",
+                "SUV",
+                "Crossover"
+            ],
+            "x-enum-varnames": [
+                "CityCar",
+                "LCGC",
+                "Compact",
+                "MPV",
+                "SUV",
+                "Crossover"
+            ]
+        },
+        "models.TransmissionType": {
+            "type": "string",
+            "enum": [
+                "Automatic",
+                "Manual"
+            ],
+            "x-enum-varnames": [
+                "Automatic",
+                "Manual"
+            ]
+        },
+        "requests.BulkCreateCarsRequest": {
+            "description": "Request payload for creating several cars at once",
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "items": {
+                    "description": "Cars to create, each validated like a single car creation\n@Description Cars to create",
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/requests.CreateCarRequest"
+                    }
+                },
+                "mode": {
+                    "description": "Bulk mode\n@Description \"atomic\" (default) creates all cars or none, \"partial\" creates the valid ones\n@Example \"atomic\"",
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "partial"
+                    ],
+                    "example": "atomic"
+                }
+            }
+        },
+        "requests.BulkDeleteCarsRequest": {
+            "description": "Request payload for deleting several cars at once",
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "description": "IDs of the cars to delete\n@Description IDs of the cars to delete\n@Example [1, 2, 3]",
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
+                },
+                "mode": {
+                    "description": "Bulk mode\n@Description \"atomic\" (default) deletes all cars or none, \"partial\" deletes the ones that exist\n@Example \"atomic\"",
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "partial"
+                    ],
+                    "example": "atomic"
+                }
+            }
+        },
+        "requests.BulkUpdateCarItem": {
+            "description": "Car update with the ID of the car to update",
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "brand": {
+                    "description": "Brand of the car\n@Description Brand of the car\n@Example \"Toyota\"",
+                    "enum": [
+                        "Toyota",
+                        "Honda",
+                        "Mercedes",
+                        "Wuling",
+                        "Mitsubishi",
+                        "Volkswagen",
+                        "Jeep",
+                        "Subaru",
+                        "Hyundai",
+                        "Kia",
+                        "Renault",
+                        "Volvo",
+                        "Chevrolet",
+                        "Ford",
+                        "BMW"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Brand"
+                        }
+                    ],
+                    "example": "Toyota"
+                },
+                "category": {
+                    "description": "Category of the car\n@Description Category of the car\n@Example \"SUV\"",
+                    "enum": [
+                        "City Car",
+                        "LCGC",
+                        "Compact",
+                        "MPV",
+                        "SUV",
+                        "Crossover"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CarCategory"
+                        }
+                    ],
+                    "example": "SUV"
+                },
+                "description": {
+                    "description": "Description of the car\n@Description Description of the car\n@Example \"This is an updated car description\"",
+                    "type": "string",
+                    "maxLength": 500,
+                    "minLength": 10,
+                    "example": "This is an updated car description"
+                },
+                "id": {
+                    "description": "ID of the car to update\n@Description ID of the car to update\n@Example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "is_available": {
+                    "description": "Availability status of the car\n@Description Availability status of the car\n@Example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "license_plate": {
+                    "description": "License plate of the car\n@Description License plate of the car\n@Example \"ABC123\"",
+                    "type": "string",
+                    "maxLength": 10,
+                    "minLength": 3,
+                    "example": "ABC123"
+                },
+                "machine_number": {
+                    "description": "Machine number of the car\n@Description Machine number of the car\n@Example \"123456\"",
+                    "type": "string",
+                    "maxLength": 10,
+                    "minLength": 3,
+                    "example": "123456"
+                },
+                "model": {
+                    "description": "Model of the car\n@Description Model of the car\n@Example \"Sample Model\"",
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3,
+                    "example": "Sample Model"
+                },
+                "name": {
+                    "description": "Name of the car\n@Description Name of the car\n@Example \"Updated Car\"",
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3,
+                    "example": "Updated Car"
+                },
+                "price_per_day": {
+                    "description": "Price Per Day of the car\n@Description Price Per Day of the car\n@Example 10000",
+                    "type": "number",
+                    "example": 10000
+                },
+                "price_per_month": {
+                    "description": "Price Per Month of the car\n@Description Price Per Month of the car\n@Example 40000",
+                    "type": "number",
+                    "example": 40000
+                },
+                "price_per_week": {
+                    "description": "Price Per Week of the car\n@Description Price Per Week of the car\n@Example 7000",
+                    "type": "number",
+                    "example": 7000
+                },
+                "transmission": {
+                    "description": "Transmission type of the car\n@Description Transmission type of the car\n@Example \"Automatic\"",
+                    "enum": [
+                        "Automatic",
+                        "Manual"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TransmissionType"
+                        }
+                    ],
+                    "example": "Automatic"
+                },
+                "year": {
+                    "description": "Year of the car\n@Description Year of the car\n@Example 2023",
+                    "type": "integer",
+                    "example": 2023
+                }
+            }
+        },
+        "requests.BulkUpdateCarsRequest": {
+            "description": "Request payload for updating several cars at once",
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "items": {
+                    "description": "Car updates, each validated like a single car update\n@Description Car updates",
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/requests.BulkUpdateCarItem"
+                    }
+                },
+                "mode": {
+                    "description": "Bulk mode\n@Description \"atomic\" (default) updates all cars or none, \"partial\" updates the ones that succeed\n@Example \"atomic\"",
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "partial"
+                    ],
+                    "example": "atomic"
+                }
+            }
+        },
+        "requests.CreateCarRequest": {
+            "description": "Request payload for creating a new car",
+            "type": "object",
+            "required": [
+                "brand",
+                "category",
+                "description",
+                "is_available",
+                "license_plate",
+                "machine_number",
+                "model",
+                "name",
+                "price_per_day",
+                "price_per_month",
+                "price_per_week",
+                "transmission",
+                "year"
+            ],
+            "properties": {
+                "brand": {
+                    "description": "Brand of the car\n@Description Brand of the car\n@Example \"Toyota\"",
+                    "enum": [
+                        "Toyota",
+                        "Honda",
+                        "Mercedes",
+                        "Wuling",
+                        "Mitsubishi",
+                        "Volkswagen",
+                        "Jeep",
+                        "Subaru",
+                        "Hyundai",
+                        "Kia",
+                        "Renault",
+                        "Volvo",
+                        "Chevrolet",
+                        "Ford",
+                        "BMW"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Brand"
+                        }
+                    ],
+                    "example": "Toyota"
+                },
+                "category": {
+                    "description": "Category of the car\n@Description Category of the car\n@Example \"SUV\"",
+                    "enum": [
+                        "City Car",
+                        "LCGC",
+                        "Compact",
+                        "MPV",
+                        "SUV",
+                        "Crossover"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CarCategory"
+                        }
+                    ],
+                    "example": "SUV"
+                },
+                "description": {
+                    "description": "Description of the car\n@Description Description of the car\n@Example \"This is a sample car description\"",
+                    "type": "string",
+                    "maxLength": 500,
+                    "minLength": 10,
+                    "example": "This is a sample car description"
+                },
+                "is_available": {
+                    "description": "Availability status of the car\n@Description Availability status of the car\n@Example true\nA pointer, so that false is told apart from a missing value",
+                    "type": "boolean",
+                    "example": true
+                },
+                "license_plate": {
+                    "description": "License plate of the car\n@Description License plate of the car\n@Example \"ABC123\"",
+                    "type": "string",
+                    "maxLength": 10,
+                    "minLength": 3,
+                    "example": "ABC123"
+                },
+                "machine_number": {
+                    "description": "Machine number of the car\n@Description Machine number of the car\n@Example \"123456\"",
+                    "type": "string",
+                    "maxLength": 10,
+                    "minLength": 3,
+                    "example": "123456"
+                },
+                "model": {
+                    "description": "Model of the car\n@Description Model of the car\n@Example \"Sample Model\"",
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3,
+                    "example": "Sample Model"
+                },
+                "name": {
+                    "description": "Name of the car\n@Description Name of the car\n@Example \"Sample Car\"",
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3,
+                    "example": "Sample Car"
+                },
+                "price_per_day": {
+                    "description": "Price Per Day of the car\n@Description Price Per Day of the car\n@Example 10000",
+                    "type": "number",
+                    "example": 10000
+                },
+                "price_per_month": {
+                    "description": "Price Per Month of the car\n@Description Price Per Month of the car\n@Example 40000",
+                    "type": "number",
+                    "example": 40000
+                },
+                "price_per_week": {
+                    "description": "Price Per Week of the car\n@Description Price Per Week of the car\n@Example 7000",
+                    "type": "number",
+                    "example": 7000
+                },
+                "transmission": {
+                    "description": "Transmission type of the car\n@Description Transmission type of the car\n@Example \"Automatic\"",
+                    "enum": [
+                        "Automatic",
+                        "Manual"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TransmissionType"
+                        }
+                    ],
+                    "example": "Automatic"
+                },
+                "year": {
+                    "description": "Year of the car\n@Description Year of the car\n@Example 2023",
+                    "type": "integer",
+                    "example": 2023
+                }
+            }
+        },
+        "requests.CreatePricingRuleRequest": {
+            "description": "Request payload for creating or replacing a pricing rule",
+            "type": "object",
+            "required": [
+                "adjustment_type",
+                "amount",
+                "name"
+            ],
+            "properties": {
+                "adjustment_type": {
+                    "description": "How the daily price is adjusted\n@Description percent to change the daily price by amount percent, fixed to change it by amount\n@Example \"percent\"",
+                    "type": "string",
+                    "enum": [
+                        "percent",
+                        "fixed"
+                    ],
+                    "example": "percent"
+                },
+                "amount": {
+                    "description": "Adjustment\n@Description Adjustment, negative for discounts; percentages can't be below -100\n@Example 25",
+                    "type": "number",
+                    "example": 25
+                },
+                "brand": {
+                    "description": "Brand of the cars the rule covers\n@Description Brand of the cars the rule covers, omitted for all\n@Example \"Toyota\"",
+                    "enum": [
+                        "Toyota",
+                        "Honda",
+                        "Mercedes",
+                        "Wuling",
+                        "Mitsubishi",
+                        "Volkswagen",
+                        "Jeep",
+                        "Subaru",
+                        "Hyundai",
+                        "Kia",
+                        "Renault",
+                        "Volvo",
+                        "Chevrolet",
+                        "Ford",
+                        "BMW"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Brand"
+                        }
+                    ],
+                    "example": "Toyota"
+                },
+                "category": {
+                    "description": "Category of the cars the rule covers\n@Description Category of the cars the rule covers, omitted for all\n@Example \"SUV\"",
+                    "enum": [
+                        "City Car",
+                        "LCGC",
+                        "Compact",
+                        "MPV",
+                        "SUV",
+                        "Crossover"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CarCategory"
+                        }
+                    ],
+                    "example": "SUV"
+                },
+                "end_date": {
+                    "description": "Last day the rule covers\n@Description Last day the rule covers (YYYY-MM-DD), inclusive, omitted for no bound\n@Example \"2024-04-15\"",
+                    "type": "string",
+                    "example": "2024-04-15"
+                },
+                "is_active": {
+                    "description": "Whether the rule is applied\n@Description Whether the rule is applied, true when omitted\n@Example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "description": "Name of the rule\n@Description Name of the rule\n@Example \"Lebaran surcharge\"",
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3,
+                    "example": "Lebaran surcharge"
+                },
+                "priority": {
+                    "description": "Priority among rules covering the same day\n@Description Rules with a higher priority win over others covering the same day\n@Example 10",
+                    "type": "integer",
+                    "example": 10
+                },
+                "start_date": {
+                    "description": "First day the rule covers\n@Description First day the rule covers (YYYY-MM-DD), omitted for no bound\n@Example \"2024-04-05\"",
+                    "type": "string",
+                    "example": "2024-04-05"
+                },
+                "weekdays": {
+                    "description": "Weekdays the rule covers\n@Description Weekdays the rule covers, omitted or empty for every day\n@Example [\"saturday\",\"sunday\"]",
+                    "type": "array",
+                    "maxItems": 7,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "saturday",
+                        "sunday"
+                    ]
+                }
+            }
+        },
+        "requests.CreateProductRequest": {
+            "description": "Request payload for creating a new product",
+            "type": "object",
+            "required": [
+                "description",
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "description": "Description of the product\n@Description Description of the product\n@Example \"This is a sample product description\"",
+                    "type": "string",
+                    "maxLength": 500,
+                    "minLength": 10,
+                    "example": "This is a sample product description"
+                },
+                "name": {
+                    "description": "Name of the product\n@Description Name of the product\n@Example \"Sample Product\"",
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3,
+                    "example": "Sample Product"
+                }
+            }
+        },
+        "requests.PatchCarRequest": {
+            "description": "JSON Merge Patch (RFC 7396) of a car; null clears a field",
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "description": "Brand of the car\n@Description Brand of the car\n@Example \"Toyota\"",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Brand"
+                        }
+                    ],
+                    "x-nullable": true,
+                    "example": "Toyota"
+                },
+                "category": {
+                    "description": "Category of the car\n@Description Category of the car\n@Example \"SUV\"",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CarCategory"
+                        }
+                    ],
+                    "x-nullable": true,
+                    "example": "SUV"
+                },
+                "description": {
+                    "description": "Description of the car\n@Description Description of the car\n@Example \"This is an updated car description\"",
+                    "type": "string",
+                    "x-nullable": true,
+                    "example": "This is an updated car description"
+                },
+                "is_available": {
+                    "description": "Availability status of the car\n@Description Availability status of the car\n@Example true",
+                    "type": "boolean",
+                    "x-nullable": true,
+                    "example": true
+                },
+                "license_plate": {
+                    "description": "License plate of the car\n@Description License plate of the car\n@Example \"ABC123\"",
+                    "type": "string",
+                    "x-nullable": true,
+                    "example": "ABC123"
+                },
+                "machine_number": {
+                    "description": "Machine number of the car\n@Description Machine number of the car\n@Example \"123456\"",
+                    "type": "string",
+                    "x-nullable": true,
+                    "example": "123456"
+                },
+                "model": {
+                    "description": "Model of the car\n@Description Model of the car\n@Example \"Sample Model\"",
+                    "type": "string",
+                    "x-nullable": true,
+                    "example": "Sample Model"
+                },
+                "name": {
+                    "description": "Name of the car\n@Description Name of the car\n@Example \"Updated Car\"",
+                    "type": "string",
+                    "x-nullable": true,
+                    "example": "Updated Car"
+                },
+                "price_per_day": {
+                    "description": "Price Per Day of the car\n@Description Price Per Day of the car\n@Example 10000",
+                    "type": "number",
+                    "x-nullable": true,
+                    "example": 10000
+                },
+                "price_per_month": {
+                    "description": "Price Per Month of the car\n@Description Price Per Month of the car\n@Example 40000",
+                    "type": "number",
+                    "x-nullable": true,
+                    "example": 40000
+                },
+                "price_per_week": {
+                    "description": "Price Per Week of the car\n@Description Price Per Week of the car\n@Example 7000",
+                    "type": "number",
+                    "x-nullable": true,
+                    "example": 7000
+                },
+                "transmission": {
+                    "description": "Transmission type of the car\n@Description Transmission type of the car\n@Example \"Automatic\"",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TransmissionType"
+                        }
+                    ],
+                    "x-nullable": true,
+                    "example": "Automatic"
+                },
+                "year": {
+                    "description": "Year of the car\n@Description Year of the car\n@Example 2023",
+                    "type": "integer",
+                    "x-nullable": true,
+                    "example": 2023
+                }
+            }
+        },
+        "requests.ScheduleCarPriceRequest": {
+            "description": "Request payload for scheduling a change of a car's prices",
+            "type": "object",
+            "required": [
+                "effective_from",
+                "price_per_day",
+                "price_per_month",
+                "price_per_week"
+            ],
+            "properties": {
+                "effective_from": {
+                    "description": "Time the prices take effect\n@Description Time the prices take effect, in the future\n@Example \"2024-04-01T00:00:00+07:00\"",
+                    "type": "string",
+                    "example": "2024-04-01T00:00:00+07:00"
+                },
+                "price_per_day": {
+                    "description": "Price Per Day of the car\n@Description Price Per Day of the car from effective_from\n@Example 12000",
+                    "type": "number",
+                    "example": 12000
+                },
+                "price_per_month": {
+                    "description": "Price Per Month of the car\n@Description Price Per Month of the car from effective_from\n@Example 280000",
+                    "type": "number",
+                    "example": 280000
+                },
+                "price_per_week": {
+                    "description": "Price Per Week of the car\n@Description Price Per Week of the car from effective_from\n@Example 75000",
+                    "type": "number",
+                    "example": 75000
+                }
+            }
+        },
+        "requests.UpdateProductRequest": {
+            "description": "Request payload for updating a product",
+            "type": "object",
+            "properties": {
+                "description": {
+                    "description": "Description of the product\n@Description Description of the product\n@Example \"This is an updated product description\"",
+                    "type": "string",
+                    "maxLength": 500,
+                    "minLength": 10,
+                    "example": "This is an updated product description"
+                },
+                "name": {
+                    "description": "Name of the product\n@Description Name of the product\n@Example \"Updated Product\"",
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3,
+                    "example": "Updated Product"
+                }
+            }
+        },
+        "responses.AuditFieldChange": {
+            "description": "Value of a field before and after a change",
+            "type": "object",
+            "properties": {
+                "after": {
+                    "description": "Value after the change\n@Description Value after the change, of the field's type"
+                },
+                "before": {
+                    "description": "Value before the change\n@Description Value before the change, of the field's type"
+                }
+            }
+        },
+        "responses.AuditLogResponse": {
+            "description": "Audit log entry",
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Action of the change\n@Description create, update or delete\n@Example \"update\"",
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ],
+                    "example": "update"
+                },
+                "actor": {
+                    "description": "Who made the change\n@Description Who made the change: admin, bearer:\u003ctoken hash prefix\u003e or anonymous\n@Example \"admin\"",
+                    "type": "string",
+                    "example": "admin"
+                },
+                "changes": {
+                    "description": "Changed fields\n@Description Changed fields by name, each with its value before and after the change",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/responses.AuditFieldChange"
+                    }
+                },
+                "created_at": {
+                    "description": "Time of the change\n@Description Time of the change\n@Example \"2023-01-01T00:00:00Z\"",
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "entity_id": {
+                    "description": "ID of the changed entity\n@Description ID of the changed entity\n@Example \"1\"",
+                    "type": "string",
+                    "example": "1"
+                },
+                "entity_type": {
+                    "description": "Type of the changed entity\n@Description Type of the changed entity\n@Example \"car\"",
+                    "type": "string",
+                    "example": "car"
+                },
+                "id": {
+                    "description": "Primary key\n@Description Unique identifier\n@Example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "ip": {
+                    "description": "IP of the request\n@Description IP the request came from\n@Example \"203.0.113.7\"",
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "request_id": {
+                    "description": "Request that made the change\n@Description ID of the request that made the change\n@Example \"3f8c2a9e-6b1d-4c7a-9f2e-1a2b3c4d5e6f\"",
+                    "type": "string",
+                    "example": "3f8c2a9e-6b1d-4c7a-9f2e-1a2b3c4d5e6f"
+                }
+            }
+        },
+        "responses.AvailabilityStats": {
+            "description": "Car counts by availability",
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "integer",
+                    "example": 9
+                },
+                "unavailable": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "responses.BulkCarItemResponse": {
+            "description": "Outcome of one item of a bulk car operation",
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Car after the operation\n@Description Created or updated car",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/responses.CarResponse"
+                        }
+                    ]
+                },
+                "errors": {
+                    "description": "Errors of the item\n@Description Why the item failed",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "description": "ID of the car\n@Description ID of the car, when known\n@Example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "index": {
+                    "description": "Index of the item in the request\n@Description Index of the item in the request\n@Example 0",
+                    "type": "integer",
+                    "example": 0
+                },
+                "status": {
+                    "description": "Item status\n@Description created, updated or deleted when applied; failed when the item has errors\n@Example \"created\"",
+                    "type": "string",
+                    "example": "created"
+                }
+            }
+        },
+        "responses.BulkCarsResponse": {
+            "description": "Outcome of a bulk car operation, item by item",
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "description": "Number of items that failed\n@Description Number of items that failed\n@Example 0",
+                    "type": "integer",
+                    "example": 0
+                },
+                "mode": {
+                    "description": "Bulk mode\n@Description Bulk mode the request ran in\n@Example \"atomic\"",
+                    "type": "string",
+                    "example": "atomic"
+                },
+                "results": {
+                    "description": "Outcome of every item, in request order\n@Description Outcome of every item, in request order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/responses.BulkCarItemResponse"
+                    }
+                },
+                "succeeded": {
+                    "description": "Number of items applied\n@Description Number of items applied\n@Example 2",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "responses.CarPriceResponse": {
+            "description": "Car price tiers effective from a point in time",
+            "type": "object",
+            "properties": {
+                "applied_at": {
+                    "description": "Time the prices were applied\n@Description Time the prices were applied to the car, absent while scheduled\n@Example \"2023-01-01T00:00:00Z\"",
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "car_id": {
+                    "description": "Car the prices are for\n@Description ID of the car\n@Example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "description": "Creation timestamp\n@Description Creation timestamp\n@Example \"2023-01-01T00:00:00Z\"",
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "effective_from": {
+                    "description": "Time the prices take effect\n@Description Time the prices take effect\n@Example \"2023-01-01T00:00:00Z\"",
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "id": {
+                    "description": "Primary key\n@Description Unique identifier\n@Example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "price_per_day": {
+                    "description": "Price per day\n@Description Price per day in IDR\n@Example 300000",
+                    "type": "number",
+                    "example": 300000
+                },
+                "price_per_month": {
+                    "description": "Price per month\n@Description Price per month in IDR\n@Example 7000000",
+                    "type": "number",
+                    "example": 7000000
+                },
+                "price_per_week": {
+                    "description": "Price per week\n@Description Price per week in IDR\n@Example 1800000",
+                    "type": "number",
+                    "example": 1800000
+                },
+                "status": {
+                    "description": "Status of the prices\n@Description applied, or scheduled until they take effect\n@Example \"applied\"",
+                    "type": "string",
+                    "enum": [
+                        "applied",
+                        "scheduled"
+                    ],
+                    "example": "applied"
+                }
+            }
         },
-        "requests.CreateCarRequest": {
-            "description": "Request payload for creating a new car",
+        "responses.CarResponse": {
+            "description": "Car response structure",
             "type": "object",
-            "required": [
-                "brand",
-                "category",
-                "description",
-                "is_available",
-                "license_plate",
-                "machine_number",
-                "model",
-                "name",
-                "price_per_day",
-                "price_per_month",
-                "price_per_week",
-                "transmission",
-                "year"
-            ],
             "properties": {
                 "brand": {
                     "description": "Brand of the car\n@Description Brand of the car\n@Example \"Toyota\"",
-                    "enum": [
-                        "Toyota",
-                        "Honda",
-                        "Mercedes",
-                        "Wuling",
-                        "Mitsubishi",
-                        "Volkswagen",
-                        "Jeep",
-                        "Subaru",
-                        "Hyundai",
-                        "Kia",
-                        "Renault",
-                        "Volvo",
-                        "Chevrolet",
-                        "Ford",
-                        "BMW"
-                    ],
                     "allOf": [
                         {
                             "$ref": "#/definitions/models.Brand"
@@ -633,83 +3260,71 @@ const docTemplate = `{
                     "example": "Toyota"
                 },
                 "category": {
-                    "description": "Category of the car\n@Description Category of the car\n@Example \"SUV\"",
-                    "enum": [
-                        "CityCar",
-                        "LCGC",
-                        "Compact",
-                        "MPV",
-                        "SUV",
-                        "Crossover"
-                    ],
+                    "description": "Category of the car\n@Description Category of the car\n@Example \"City Car\"",
                     "allOf": [
                         {
                             "$ref": "#/definitions/models.CarCategory"
                         }
                     ],
-                    "example": "SUV"
+                    "example": "City Car"
+                },
+                "created_at": {
+                    "description": "Creation timestamp\n@Description Creation timestamp\n@Example \"2023-01-01T00:00:00Z\"",
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
                 },
                 "description": {
-                    "description": "Description of the car\n@Description Description of the car\n@Example \"This is a sample car description\"",
+                    "description": "Description of the car\n@Description Description of the car\n@Example \"Comfortable family car with spacious interior\"",
                     "type": "string",
-                    "maxLength": 500,
-                    "minLength": 10,
-                    "example": "This is a sample car description"
+                    "example": "Comfortable family car with spacious interior"
+                },
+                "id": {
+                    "description": "Primary key\n@Description Unique identifier\n@Example 1",
+                    "type": "integer",
+                    "example": 1
                 },
                 "is_available": {
-                    "description": "Availability status of the car\n@Description Availability status of the car\n@Example true",
+                    "description": "Availability status\n@Description Whether the car is available for rent\n@Example true",
                     "type": "boolean",
                     "example": true
                 },
                 "license_plate": {
-                    "description": "License plate of the car\n@Description License plate of the car\n@Example \"ABC123\"",
+                    "description": "License plate\n@Description License plate number\n@Example \"B 1234 ABC\"",
                     "type": "string",
-                    "maxLength": 10,
-                    "minLength": 3,
-                    "example": "ABC123"
+                    "example": "B 1234 ABC"
                 },
                 "machine_number": {
-                    "description": "Machine number of the car\n@Description Machine number of the car\n@Example \"123456\"",
+                    "description": "Machine number\n@Description Machine/engine number\n@Example \"ABC123456789\"",
                     "type": "string",
-                    "maxLength": 10,
-                    "minLength": 3,
-                    "example": "123456"
+                    "example": "ABC123456789"
                 },
                 "model": {
-                    "description": "Model of the car\n@Description Model of the car\n@Example \"Sample Model\"",
+                    "description": "Model of the car\n@Description Model of the car\n@Example \"Avanza\"",
                     "type": "string",
-                    "maxLength": 100,
-                    "minLength": 3,
-                    "example": "Sample Model"
+                    "example": "Avanza"
                 },
                 "name": {
-                    "description": "Name of the car\n@Description Name of the car\n@Example \"Sample Car\"",
+                    "description": "Name of the car\n@Description Name of the car\n@Example \"Toyota Avanza\"",
                     "type": "string",
-                    "maxLength": 100,
-                    "minLength": 3,
-                    "example": "Sample Car"
+                    "example": "Toyota Avanza"
                 },
                 "price_per_day": {
-                    "description": "Price Per Day of the car\n@Description Price Per Day of the car\n@Example 10000",
+                    "description": "Price per day\n@Description Price per day in IDR\n@Example 300000",
                     "type": "number",
-                    "example": 10000
+                    "example": 300000
                 },
                 "price_per_month": {
-                    "description": "Price Per Month of the car\n@Description Price Per Month of the car\n@Example 40000",
+                    "description": "Price per month\n@Description Price per month in IDR\n@Example 7000000",
                     "type": "number",
-                    "example": 40000
+                    "example": 7000000
                 },
                 "price_per_week": {
-                    "description": "Price Per Week of the car\n@Description Price Per Week of the car\n@Example 7000",
+                    "description": "Price per week\n@Description Price per week in IDR\n@Example 1800000",
                     "type": "number",
-                    "example": 7000
+                    "example": 1800000
                 },
                 "transmission": {
-                    "description": "Transmission type of the car\n@Description Transmission type of the car\n@Example \"Automatic\"",
-                    "enum": [
-                        "Automatic",
-                        "Manual"
-                    ],
+                    "description": "Transmission type\n@Description Transmission type\n@Example \"Automatic\"",
                     "allOf": [
                         {
                             "$ref": "#/definitions/models.TransmissionType"
@@ -717,77 +3332,94 @@ const docTemplate = `{
                     ],
                     "example": "Automatic"
                 },
+                "updated_at": {
+                    "description": "Last update timestamp\n@Description Last update timestamp\n@Example \"2023-01-01T00:00:00Z\"",
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
+                },
+                "version": {
+                    "description": "Version\n@Description Version of the car, also sent as its ETag\n@Example 1",
+                    "type": "integer",
+                    "example": 1
+                },
                 "year": {
-                    "description": "Year of the car\n@Description Year of the car\n@Example 2023",
+                    "description": "Year of the car\n@Description Year of the car\n@Example 2022",
                     "type": "integer",
-                    "example": 2023
+                    "example": 2022
                 }
             }
         },
-        "requests.CreateProductRequest": {
-            "description": "Request payload for creating a new product",
+        "responses.CarStatsResponse": {
+            "description": "Fleet statistics aggregated over all cars",
             "type": "object",
-            "required": [
-                "description",
-                "name"
-            ],
             "properties": {
-                "description": {
-                    "description": "Description of the product\n@Description Description of the product\n@Example \"This is a sample product description\"",
-                    "type": "string",
-                    "maxLength": 500,
-                    "minLength": 10,
-                    "example": "This is a sample product description"
+                "available_cars": {
+                    "description": "Number of cars available for rent\n@Description Number of cars available for rent\n@Example 9",
+                    "type": "integer",
+                    "example": 9
                 },
-                "name": {
-                    "description": "Name of the product\n@Description Name of the product\n@Example \"Sample Product\"",
-                    "type": "string",
-                    "maxLength": 100,
-                    "minLength": 3,
-                    "example": "Sample Product"
+                "by_availability": {
+                    "description": "Car counts by availability\n@Description Number of available and unavailable cars",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/responses.AvailabilityStats"
+                        }
+                    ]
+                },
+                "by_brand": {
+                    "description": "Car counts by brand\n@Description Number of cars per brand",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "by_category": {
+                    "description": "Car counts by category\n@Description Number of cars per category",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "by_transmission": {
+                    "description": "Car counts by transmission\n@Description Number of cars per transmission type",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "by_year": {
+                    "description": "Car counts by year\n@Description Number of cars per production year, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/responses.YearCountResponse"
+                    }
+                },
+                "price_by_category": {
+                    "description": "Daily price statistics by category\n@Description Average, minimum and maximum daily price per category",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/responses.CategoryPriceStatsResponse"
+                    }
+                },
+                "total_cars": {
+                    "description": "Total number of cars\n@Description Total number of cars\n@Example 12",
+                    "type": "integer",
+                    "example": 12
                 }
             }
         },
-        "requests.UpdateCarRequest": {
-            "description": "Request payload for updating a car",
+        "responses.CategoryPriceStatsResponse": {
+            "description": "Daily price statistics of a category",
             "type": "object",
             "properties": {
-                "brand": {
-                    "description": "Brand of the car\n@Description Brand of the car\n@Example \"Toyota\"",
-                    "enum": [
-                        "Toyota",
-                        "Honda",
-                        "Mercedes",
-                        "Wuling",
-                        "Mitsubishi",
-                        "Volkswagen",
-                        "Jeep",
-                        "Subaru",
-                        "Hyundai",
-                        "Kia",
-                        "Renault",
-                        "Volvo",
-                        "Chevrolet",
-                        "Ford",
-                        "BMW"
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.Brand"
-                        }
-                    ],
-                    "example": "Toyota"
+                "avg_price": {
+                    "type": "number",
+                    "example": 450000
                 },
                 "category": {
-                    "description": "Category of the car\n@Description Category of the car\n@Example \"SUV\"",
-                    "enum": [
-                        "CityCar",
-                        "LCGC",
-                        "Compact",
-                        "MPV",
-                        "SUV",
-                        "Crossover"
-                    ],
                     "allOf": [
                         {
                             "$ref": "#/definitions/models.CarCategory"
@@ -795,235 +3427,420 @@ const docTemplate = `{
                     ],
                     "example": "SUV"
                 },
-                "description": {
-                    "description": "Description of the car\n@Description Description of the car\n@Example \"This is an updated car description\"",
+                "count": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "max_price": {
+                    "type": "number",
+                    "example": 600000
+                },
+                "min_price": {
+                    "type": "number",
+                    "example": 350000
+                }
+            }
+        },
+        "responses.ImportJobResponse": {
+            "description": "Fleet import job and its per-row error report",
+            "type": "object",
+            "properties": {
+                "created": {
+                    "description": "@Description Cars created, or that would be created in a dry run\n@Example 100",
+                    "type": "integer",
+                    "example": 100
+                },
+                "created_at": {
+                    "description": "@Example \"2023-01-01T00:00:00Z\"",
                     "type": "string",
-                    "maxLength": 500,
-                    "minLength": 10,
-                    "example": "This is an updated car description"
+                    "example": "2023-01-01T00:00:00Z"
                 },
-                "is_available": {
-                    "description": "Availability status of the car\n@Description Availability status of the car\n@Example true",
+                "dry_run": {
+                    "description": "Dry run\n@Description Whether the import only validated the file\n@Example false",
                     "type": "boolean",
-                    "example": true
+                    "example": false
+                },
+                "error": {
+                    "description": "@Description Why the job stopped, when it failed",
+                    "type": "string",
+                    "example": ""
+                },
+                "failed": {
+                    "description": "@Description Rows that weren't imported\n@Example 2",
+                    "type": "integer",
+                    "example": 2
+                },
+                "finished_at": {
+                    "description": "@Example \"2023-01-01T00:00:05Z\"",
+                    "type": "string",
+                    "example": "2023-01-01T00:00:05Z"
+                },
+                "id": {
+                    "description": "Job ID\n@Description ID to poll the job with\n@Example \"3f8c2a9e-6b1d-4c7a-9f2e-1a2b3c4d5e6f\"",
+                    "type": "string",
+                    "example": "3f8c2a9e-6b1d-4c7a-9f2e-1a2b3c4d5e6f"
+                },
+                "mapping": {
+                    "description": "Column mapping\n@Description Column header read for every car field",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "processed_rows": {
+                    "description": "@Description Number of rows processed so far\n@Example 120",
+                    "type": "integer",
+                    "example": 120
+                },
+                "progress": {
+                    "description": "@Description Percentage of rows processed\n@Example 100",
+                    "type": "integer",
+                    "example": 100
+                },
+                "row_errors": {
+                    "description": "@Description Why each failed row wasn't imported",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/responses.ImportRowErrorResponse"
+                    }
                 },
-                "license_plate": {
-                    "description": "License plate of the car\n@Description License plate of the car\n@Example \"ABC123\"",
+                "status": {
+                    "description": "Job status\n@Description pending, running, completed or failed\n@Example \"completed\"",
                     "type": "string",
-                    "maxLength": 10,
-                    "minLength": 3,
-                    "example": "ABC123"
+                    "example": "completed"
                 },
-                "machine_number": {
-                    "description": "Machine number of the car\n@Description Machine number of the car\n@Example \"123456\"",
-                    "type": "string",
-                    "maxLength": 10,
-                    "minLength": 3,
-                    "example": "123456"
+                "total_rows": {
+                    "description": "@Description Number of data rows in the file\n@Example 120",
+                    "type": "integer",
+                    "example": 120
                 },
-                "model": {
-                    "description": "Model of the car\n@Description Model of the car\n@Example \"Sample Model\"",
-                    "type": "string",
-                    "maxLength": 100,
-                    "minLength": 3,
-                    "example": "Sample Model"
+                "updated": {
+                    "description": "@Description Cars updated by license plate, or that would be updated in a dry run\n@Example 18",
+                    "type": "integer",
+                    "example": 18
+                }
+            }
+        },
+        "responses.ImportRowErrorResponse": {
+            "description": "Errors of a spreadsheet row",
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "description": "@Description Validation or database errors of the row",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "name": {
-                    "description": "Name of the car\n@Description Name of the car\n@Example \"Updated Car\"",
+                "license_plate": {
+                    "description": "@Description License plate of the row, when present\n@Example \"B 1234 ABC\"",
                     "type": "string",
-                    "maxLength": 100,
-                    "minLength": 3,
-                    "example": "Updated Car"
+                    "example": "B 1234 ABC"
                 },
-                "price_per_day": {
-                    "description": "Price Per Day of the car\n@Description Price Per Day of the car\n@Example 10000",
+                "row": {
+                    "description": "@Description Row number in the file, the header being row 1\n@Example 7",
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "responses.PriceQuoteDayResponse": {
+            "description": "Price of one day of a rental",
+            "type": "object",
+            "properties": {
+                "base_price": {
+                    "description": "Day's share of the tiered price\n@Description Day's share of the tiered price, in IDR\n@Example 257142.86",
                     "type": "number",
-                    "example": 10000
+                    "example": 257142.86
                 },
-                "price_per_month": {
-                    "description": "Price Per Month of the car\n@Description Price Per Month of the car\n@Example 40000",
+                "date": {
+                    "description": "Day\n@Description Day (YYYY-MM-DD)\n@Example \"2024-04-08\"",
+                    "type": "string",
+                    "example": "2024-04-08"
+                },
+                "price": {
+                    "description": "Price of the day\n@Description Price of the day with the pricing rule applied, in IDR\n@Example 321428.57",
                     "type": "number",
-                    "example": 40000
+                    "example": 321428.57
                 },
-                "price_per_week": {
-                    "description": "Price Per Week of the car\n@Description Price Per Week of the car\n@Example 7000",
+                "rule_id": {
+                    "description": "Rule applied to the day\n@Description ID of the pricing rule applied to the day, absent when none covers it\n@Example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "rule_name": {
+                    "description": "Name of the rule applied to the day\n@Description Name of the pricing rule applied to the day\n@Example \"Lebaran surcharge\"",
+                    "type": "string",
+                    "example": "Lebaran surcharge"
+                }
+            }
+        },
+        "responses.PriceQuoteResponse": {
+            "description": "Price of renting a car for a period, day by day",
+            "type": "object",
+            "properties": {
+                "base_price": {
+                    "description": "Price without pricing rules\n@Description Price with the car's tiers alone, in IDR\n@Example 1800000",
                     "type": "number",
-                    "example": 7000
+                    "example": 1800000
                 },
-                "transmission": {
-                    "description": "Transmission type of the car\n@Description Transmission type of the car\n@Example \"Automatic\"",
-                    "enum": [
-                        "Automatic",
-                        "Manual"
-                    ],
+                "breakdown": {
+                    "description": "Price of each day\n@Description Price of each day and the rule applied to it",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/responses.PriceQuoteDayResponse"
+                    }
+                },
+                "car_id": {
+                    "description": "Car the price is for\n@Description ID of the car\n@Example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "days": {
+                    "description": "Number of days\n@Description Number of days rented\n@Example 7",
+                    "type": "integer",
+                    "example": 7
+                },
+                "end_date": {
+                    "description": "Return day\n@Description Return day (YYYY-MM-DD)\n@Example \"2024-04-15\"",
+                    "type": "string",
+                    "example": "2024-04-15"
+                },
+                "start_date": {
+                    "description": "Pickup day\n@Description Pickup day (YYYY-MM-DD)\n@Example \"2024-04-08\"",
+                    "type": "string",
+                    "example": "2024-04-08"
+                },
+                "tiers": {
+                    "description": "Tier breakdown\n@Description Days priced as 30-day months, 7-day weeks and single days",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/models.TransmissionType"
+                            "$ref": "#/definitions/responses.PriceQuoteTiers"
                         }
-                    ],
-                    "example": "Automatic"
+                    ]
                 },
-                "year": {
-                    "description": "Year of the car\n@Description Year of the car\n@Example 2023",
-                    "type": "integer",
-                    "example": 2023
+                "total": {
+                    "description": "Price with pricing rules\n@Description Price with the pricing rules applied, in IDR\n@Example 2057142.86",
+                    "type": "number",
+                    "example": 2057142.86
                 }
             }
         },
-        "requests.UpdateProductRequest": {
-            "description": "Request payload for updating a product",
+        "responses.PriceQuoteTiers": {
+            "description": "Days priced as 30-day months, 7-day weeks and single days",
             "type": "object",
             "properties": {
-                "description": {
-                    "description": "Description of the product\n@Description Description of the product\n@Example \"This is an updated product description\"",
-                    "type": "string",
-                    "maxLength": 500,
-                    "minLength": 10,
-                    "example": "This is an updated product description"
+                "days": {
+                    "description": "Number of remaining days\n@Description Number of remaining days, at the daily price\n@Example 0",
+                    "type": "integer",
+                    "example": 0
                 },
-                "name": {
-                    "description": "Name of the product\n@Description Name of the product\n@Example \"Updated Product\"",
-                    "type": "string",
-                    "maxLength": 100,
-                    "minLength": 3,
-                    "example": "Updated Product"
+                "months": {
+                    "description": "Number of 30-day months\n@Description Number of 30-day months, at the monthly price\n@Example 0",
+                    "type": "integer",
+                    "example": 0
+                },
+                "weeks": {
+                    "description": "Number of 7-day weeks\n@Description Number of 7-day weeks, at the weekly price\n@Example 1",
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
-        "responses.CarResponse": {
-            "description": "Car response structure",
+        "responses.PricingRuleResponse": {
+            "description": "Pricing rule response structure",
             "type": "object",
             "properties": {
-                "brand": {
-                    "description": "Brand of the car\n@Description Brand of the car\n@Example \"Toyota\"",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.Brand"
-                        }
+                "adjustment_type": {
+                    "description": "How the daily price is adjusted\n@Description percent or fixed\n@Example \"percent\"",
+                    "type": "string",
+                    "enum": [
+                        "percent",
+                        "fixed"
                     ],
+                    "example": "percent"
+                },
+                "amount": {
+                    "description": "Adjustment\n@Description Adjustment, negative for discounts\n@Example 25",
+                    "type": "number",
+                    "example": 25
+                },
+                "brand": {
+                    "description": "Brand of the cars the rule covers\n@Description Brand of the cars the rule covers, absent for all\n@Example \"Toyota\"",
+                    "type": "string",
                     "example": "Toyota"
                 },
                 "category": {
-                    "description": "Category of the car\n@Description Category of the car\n@Example \"CityCar\"",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.CarCategory"
-                        }
-                    ],
-                    "example": "CityCar"
+                    "description": "Category of the cars the rule covers\n@Description Category of the cars the rule covers, absent for all\n@Example \"SUV\"",
+                    "type": "string",
+                    "example": "SUV"
                 },
                 "created_at": {
                     "description": "Creation timestamp\n@Description Creation timestamp\n@Example \"2023-01-01T00:00:00Z\"",
                     "type": "string",
                     "example": "2023-01-01T00:00:00Z"
                 },
-                "description": {
-                    "description": "Description of the car\n@Description Description of the car\n@Example \"Comfortable family car with spacious interior\"",
+                "end_date": {
+                    "description": "Last day the rule covers\n@Description Last day the rule covers (YYYY-MM-DD), inclusive, absent for no bound\n@Example \"2024-04-15\"",
                     "type": "string",
-                    "example": "Comfortable family car with spacious interior"
+                    "example": "2024-04-15"
                 },
                 "id": {
                     "description": "Primary key\n@Description Unique identifier\n@Example 1",
                     "type": "integer",
                     "example": 1
                 },
-                "is_available": {
-                    "description": "Availability status\n@Description Whether the car is available for rent\n@Example true",
+                "is_active": {
+                    "description": "Whether the rule is applied\n@Description Whether the rule is applied\n@Example true",
                     "type": "boolean",
                     "example": true
                 },
-                "license_plate": {
-                    "description": "License plate\n@Description License plate number\n@Example \"B 1234 ABC\"",
+                "name": {
+                    "description": "Name of the rule\n@Description Name of the rule\n@Example \"Lebaran surcharge\"",
                     "type": "string",
-                    "example": "B 1234 ABC"
+                    "example": "Lebaran surcharge"
                 },
-                "machine_number": {
-                    "description": "Machine number\n@Description Machine/engine number\n@Example \"ABC123456789\"",
-                    "type": "string",
-                    "example": "ABC123456789"
+                "priority": {
+                    "description": "Priority among rules covering the same day\n@Description Rules with a higher priority win over others covering the same day\n@Example 10",
+                    "type": "integer",
+                    "example": 10
                 },
-                "model": {
-                    "description": "Model of the car\n@Description Model of the car\n@Example \"Avanza\"",
+                "start_date": {
+                    "description": "First day the rule covers\n@Description First day the rule covers (YYYY-MM-DD), absent for no bound\n@Example \"2024-04-05\"",
                     "type": "string",
-                    "example": "Avanza"
+                    "example": "2024-04-05"
                 },
-                "name": {
-                    "description": "Name of the car\n@Description Name of the car\n@Example \"Toyota Avanza\"",
+                "updated_at": {
+                    "description": "Last update timestamp\n@Description Last update timestamp\n@Example \"2023-01-01T00:00:00Z\"",
                     "type": "string",
-                    "example": "Toyota Avanza"
+                    "example": "2023-01-01T00:00:00Z"
                 },
-                "price_per_day": {
-                    "description": "Price per day\n@Description Price per day in IDR\n@Example 300000",
-                    "type": "number",
-                    "example": 300000
+                "weekdays": {
+                    "description": "Weekdays the rule covers\n@Description Weekdays the rule covers, empty for every day\n@Example [\"saturday\",\"sunday\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "saturday",
+                        "sunday"
+                    ]
+                }
+            }
+        },
+        "responses.ProductResponse": {
+            "description": "Product response structure",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Creation timestamp\n@Description Creation timestamp\n@Example \"2023-01-01T00:00:00Z\"",
+                    "type": "string",
+                    "example": "2023-01-01T00:00:00Z"
                 },
-                "price_per_month": {
-                    "description": "Price per month\n@Description Price per month in IDR\n@Example 7000000",
-                    "type": "number",
-                    "example": 7000000
+                "description": {
+                    "description": "Description of the product\n@Description Description of the product\n@Example \"This is a sample product description\"",
+                    "type": "string",
+                    "example": "This is a sample product description"
                 },
-                "price_per_week": {
-                    "description": "Price per week\n@Description Price per week in IDR\n@Example 1800000",
-                    "type": "number",
-                    "example": 1800000
+                "id": {
+                    "description": "Primary key\n@Description Unique identifier\n@Example 1",
+                    "type": "integer",
+                    "example": 1
                 },
-                "transmission": {
-                    "description": "Transmission type\n@Description Transmission type\n@Example \"Automatic\"",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.TransmissionType"
-                        }
-                    ],
-                    "example": "Automatic"
+                "name": {
+                    "description": "Name of the product\n@Description Name of the product\n@Example \"Sample Product\"",
+                    "type": "string",
+                    "example": "Sample Product"
                 },
                 "updated_at": {
                     "description": "Last update timestamp\n@Description Last update timestamp\n@Example \"2023-01-01T00:00:00Z\"",
                     "type": "string",
                     "example": "2023-01-01T00:00:00Z"
                 },
+                "version": {
+                    "description": "Version\n@Description Version of the product, also sent as its ETag\n@Example 1",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "responses.YearCountResponse": {
+            "description": "Number of cars built in a year",
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 5
+                },
                 "year": {
-                    "description": "Year of the car\n@Description Year of the car\n@Example 2022",
                     "type": "integer",
                     "example": 2022
                 }
             }
         },
-        "responses.CarsListResponse": {
-            "description": "Paginated list response for cars",
+        "utils.APIError": {
+            "description": "Error of a failed request",
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string",
+                    "example": "Detailed error information"
+                },
+                "index": {
+                    "description": "Index of the item the error is about, in requests with several items",
+                    "type": "integer",
+                    "example": 0
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Error message"
+                }
+            }
+        },
+        "utils.Envelope": {
+            "description": "Response envelope: the resource in data, or what went wrong in errors",
             "type": "object",
             "properties": {
                 "data": {
-                    "description": "List of cars\n@Description Array of car data",
+                    "description": "The requested, created or updated resource"
+                },
+                "errors": {
+                    "description": "Why the request failed",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/responses.CarResponse"
+                        "$ref": "#/definitions/utils.APIError"
                     }
                 },
-                "pagination": {
-                    "description": "Pagination metadata\n@Description Pagination information",
+                "meta": {
+                    "description": "Message, request ID and pagination",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/utils.PaginationMeta"
+                            "$ref": "#/definitions/utils.Meta"
                         }
                     ]
                 }
             }
         },
-        "utils.ErrorResponse": {
-            "description": "Error response format",
+        "utils.Meta": {
+            "description": "Response metadata",
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "Detailed error information"
-                },
                 "message": {
                     "type": "string",
-                    "example": "Error message"
+                    "example": "Operation completed successfully"
                 },
-                "success": {
-                    "type": "boolean",
-                    "example": false
+                "pagination": {
+                    "description": "Pagination of lists",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/utils.PaginationMeta"
+                        }
+                    ]
+                },
+                "request_id": {
+                    "description": "ID of the request, to quote when reporting an error",
+                    "type": "string",
+                    "example": "3f8c2a9e-6b1d-4c7a-9f2e-1a2b3c4d5e6f"
                 }
             }
         },
@@ -1062,20 +3879,6 @@ const docTemplate = `{
                     "example": 10
                 }
             }
-        },
-        "utils.SuccessResponse": {
-            "description": "Success response format",
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string",
-                    "example": "Operation completed successfully"
-                },
-                "success": {
-                    "type": "boolean",
-                    "example": true
-                }
-            }
         }
     }
 }`
@@ -0,0 +1,13 @@
+package docs
+
+import _ "embed"
+
+// OpenAPIJSON is the OpenAPI 3 conversion of the Swagger 2.0 spec, generated by cmd/docs
+//
+//go:embed openapi.json
+var OpenAPIJSON []byte
+
+// OpenAPIYAML is the YAML form of OpenAPIJSON
+//
+//go:embed openapi.yaml
+var OpenAPIYAML []byte
@@ -0,0 +1,211 @@
+package metrics
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric the application exports
+const namespace = "rentcar"
+
+// Registry holds the application's metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by method and route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests currently being handled.",
+	})
+
+	rateLimitRejections = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Requests rejected by the rate limiter.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		httpInFlight,
+		rateLimitRejections,
+	)
+}
+
+// Middleware records request counts, latencies and in-flight requests. Requests
+// are labelled by route template, e.g., /api/v1/cars/:id, so IDs don't create new
+// series; requests matching no route are labelled "unmatched".
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		httpInFlight.Inc()
+		defer httpInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RateLimitRejected counts a request rejected by the rate limiter
+func RateLimitRejected() {
+	rateLimitRejections.Inc()
+}
+
+// RegisterDBStats exports the connection pool statistics of a database
+func RegisterDBStats(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the metrics in the Prometheus text format. When token is set,
+// requests must carry it as "Authorization: Bearer <token>".
+func Handler(token string) http.Handler {
+	handler := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{
+		// Serve what can be collected when, e.g., a business gauge query fails
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+	if token == "" {
+		return handler
+	}
+
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// StatsFunc returns business statistics such as ProductService.GetProductStats
+type StatsFunc func(ctx context.Context) (map[string]interface{}, error)
+
+// StatGauge exports one numeric value of a stats map as a gauge
+type StatGauge struct {
+	Key  string // key in the stats map, e.g., "total_cars"
+	Name string // metric name without the namespace, e.g., "cars"
+	Help string
+}
+
+// statsTimeout bounds the queries a scrape runs to compute business gauges
+const statsTimeout = 5 * time.Second
+
+var (
+	statsMu         sync.Mutex
+	statsCollectors = make(map[string]prometheus.Collector)
+)
+
+// RegisterStats exports business gauges computed from a stats function on each
+// scrape. Registering the same name again replaces the previous collector.
+func RegisterStats(name string, stats StatsFunc, gauges ...StatGauge) {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	if existing, ok := statsCollectors[name]; ok {
+		Registry.Unregister(existing)
+	}
+
+	collector := &statsCollector{name: name, stats: stats}
+	for _, gauge := range gauges {
+		collector.gauges = append(collector.gauges, statGaugeDesc{
+			key:  gauge.Key,
+			desc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", gauge.Name), gauge.Help, nil, nil),
+		})
+	}
+
+	Registry.MustRegister(collector)
+	statsCollectors[name] = collector
+}
+
+type statGaugeDesc struct {
+	key  string
+	desc *prometheus.Desc
+}
+
+// statsCollector computes gauges from a stats function when scraped
+type statsCollector struct {
+	name   string
+	stats  StatsFunc
+	gauges []statGaugeDesc
+}
+
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, gauge := range c.gauges {
+		ch <- gauge.desc
+	}
+}
+
+func (c *statsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), statsTimeout)
+	defer cancel()
+
+	stats, err := c.stats(ctx)
+	if err != nil {
+		slog.Error("failed to collect business metrics", slog.String("stats", c.name), slog.String("error", err.Error()))
+		for _, gauge := range c.gauges {
+			ch <- prometheus.NewInvalidMetric(gauge.desc, err)
+		}
+		return
+	}
+
+	for _, gauge := range c.gauges {
+		value, err := toFloat(stats[gauge.key])
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(gauge.desc, fmt.Errorf("stat %s: %v", gauge.key, err))
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(gauge.desc, prometheus.GaugeValue, value)
+	}
+}
+
+// toFloat converts a numeric stats value to a float64
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case nil:
+		return 0, fmt.Errorf("missing")
+	default:
+		return 0, fmt.Errorf("unsupported type %T", value)
+	}
+}
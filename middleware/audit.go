@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"api-rentcar/audit"
+	"api-rentcar/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Actors changes are attributed to, besides callers identified by their
+// bearer token
+const (
+	ActorAdmin     = "admin"
+	ActorAnonymous = "anonymous"
+)
+
+// AuditCaller middleware carries the caller of a request in its context, so
+// the changes it makes are attributed to them in the audit log. Callers that
+// send adminToken are the admin, others with an Authorization header are
+// "bearer:" and a prefix of its hash, and the rest anonymous.
+func AuditCaller(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := ActorAnonymous
+		if authorization := c.GetHeader("Authorization"); authorization != "" {
+			if isAdmin(authorization, adminToken) {
+				actor = ActorAdmin
+			} else {
+				sum := sha256.Sum256([]byte(authorization))
+				actor = "bearer:" + hex.EncodeToString(sum[:6])
+			}
+		}
+
+		ctx := audit.WithCaller(c.Request.Context(), audit.Caller{Actor: actor, IP: c.ClientIP()})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// RequireAdmin middleware answers requests that don't carry adminToken as
+// "Authorization: Bearer <token>" with 401, and all requests with 403 while
+// adminToken is empty
+func RequireAdmin(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if adminToken == "" {
+			utils.SendErrorResponse(c, http.StatusForbidden, "Admin access is disabled", errors.New("no admin token is configured"))
+			c.Abort()
+			return
+		}
+		if !isAdmin(c.GetHeader("Authorization"), adminToken) {
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			utils.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized", errors.New("an admin bearer token is required"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// isAdmin reports whether an Authorization header carries adminToken
+func isAdmin(authorization, adminToken string) bool {
+	return adminToken != "" && subtle.ConstantTimeCompare([]byte(authorization), []byte("Bearer "+adminToken)) == 1
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"api-rentcar/utils"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// Content codings Compress can apply, in order of preference
+const (
+	EncodingBrotli = "br"
+	EncodingGzip   = "gzip"
+)
+
+// Compress middleware compresses response bodies with brotli or gzip, as the
+// request's Accept-Encoding prefers. Only bodies of at least minSize bytes
+// whose content type is one of contentTypes are compressed; smaller bodies
+// aren't worth the CPU. Bodies are buffered up to minSize, so streamed
+// responses that flush earlier are compressed as they go.
+func Compress(minSize int, contentTypes []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := utils.NegotiateEncoding(c.GetHeader("Accept-Encoding"), []string{EncodingBrotli, EncodingGzip})
+		if encoding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			minSize:        minSize,
+			contentTypes:   contentTypes,
+		}
+		c.Writer = writer
+		defer func() {
+			writer.close()
+			c.Writer = writer.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// compressWriter buffers the start of a body until it knows whether to
+// compress it, then writes it through an encoder or as is
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding     string
+	minSize      int
+	contentTypes []string
+
+	buf     bytes.Buffer
+	decided bool
+	encoder io.WriteCloser
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buf.Write(data)
+		if w.buf.Len() < w.minSize {
+			return len(data), nil
+		}
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written reports whether the response was started, including bodies still
+// being buffered
+func (w *compressWriter) Written() bool {
+	return w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// Unwrap returns the underlying writer, so that http.ResponseController can
+// reach it, e.g., to lift the write deadline of a streamed export
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush sends what has been written so far; a streamed body is compressed
+// regardless of its size so far, as more is likely to follow
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.minSize = 0
+		_ = w.decide()
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide compresses the body when its status, content type and size allow,
+// and writes out the buffered start of it
+func (w *compressWriter) decide() error {
+	w.decided = true
+
+	header := w.Header()
+	if w.compressible() {
+		header.Add("Vary", "Accept-Encoding")
+		if w.buf.Len() >= w.minSize {
+			header.Set("Content-Encoding", w.encoding)
+			header.Del("Content-Length")
+			if w.encoding == EncodingBrotli {
+				w.encoder = brotli.NewWriterLevel(w.ResponseWriter, brotli.DefaultCompression)
+			} else {
+				w.encoder = gzip.NewWriter(w.ResponseWriter)
+			}
+		}
+	}
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+// compressible reports whether the response may be compressed: it has a body,
+// isn't encoded already, e.g., by a wrapped handler, and has one of the
+// allowed content types
+func (w *compressWriter) compressible() bool {
+	status := w.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, contentType := range w.contentTypes {
+		if strings.EqualFold(contentType, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// close writes out a body that stayed below the threshold and finishes the
+// compressed stream
+func (w *compressWriter) close() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+	}
+}
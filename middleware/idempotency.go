@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"api-rentcar/cache"
+	"api-rentcar/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Idempotency headers
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+const (
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBodySize limits the request bodies read to fingerprint
+	// them, as large as the biggest request the API takes, a fleet import
+	maxIdempotentBodySize = 20 << 20 // 20 MB
+	// idempotencyInProgressTTL bounds how long a key stays claimed by a
+	// request that never completes, e.g., when the server stops
+	idempotencyInProgressTTL = time.Minute
+)
+
+// Response headers that are set afresh for every response rather than replayed
+var unreplayedHeaders = map[string]bool{
+	"Content-Encoding":                       true,
+	"Content-Length":                         true,
+	"Vary":                                   true,
+	http.CanonicalHeaderKey(RequestIDHeader): true,
+}
+
+// idempotencyRecord is what is stored for a key: the fingerprint of the
+// request, and its response once it has completed
+type idempotencyRecord struct {
+	Fingerprint string              `json:"fingerprint"`
+	Completed   bool                `json:"completed"`
+	Status      int                 `json:"status,omitempty"`
+	Header      map[string][]string `json:"header,omitempty"`
+	Body        []byte              `json:"body,omitempty"`
+}
+
+// Idempotency middleware makes POST requests that carry an Idempotency-Key
+// header safe to retry. The first response for a key and caller is stored in
+// store for window and replayed, with an Idempotent-Replayed header, to
+// retries with the same method, path and body. A key reused with a different
+// request is answered with 422, and a retry while the first request is still
+// running with 409. Server errors and panics aren't stored, so the request can
+// be retried. Bodies over 20 MB are answered with 413.
+// Callers are told apart by their Authorization header, or else their IP.
+func Idempotency(store cache.Cache, window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength || !printable(key) {
+			utils.SendErrorResponse(c, http.StatusBadRequest, "Invalid Idempotency-Key", errors.New("the key must be 1 to 255 printable ASCII characters"))
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodySize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				utils.SendErrorResponse(c, http.StatusRequestEntityTooLarge, "Request body too large", err)
+			} else {
+				utils.SendErrorResponse(c, http.StatusBadRequest, "Failed to read request body", err)
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		storeKey := "idempotency:" + callerID(c) + ":" + key
+		fingerprint := requestFingerprint(c.Request, body)
+
+		// Claim the key, or find the request that claimed it first
+		claim := idempotencyRecord{Fingerprint: fingerprint}
+		claimed, err := addRecord(c, store, storeKey, claim)
+		if err != nil {
+			// Without the store the request is handled as if it had no key
+			slog.WarnContext(ctx, "idempotency store unavailable", slog.String("error", err.Error()))
+			c.Next()
+			return
+		}
+		if !claimed {
+			var record idempotencyRecord
+			found, err := cache.GetJSON(ctx, store, storeKey, &record)
+			if err != nil || !found {
+				utils.SendErrorResponse(c, http.StatusConflict, "Request with this Idempotency-Key is being processed", err)
+				c.Abort()
+				return
+			}
+			switch {
+			case record.Fingerprint != fingerprint:
+				utils.SendErrorResponse(c, http.StatusUnprocessableEntity, "Idempotency-Key reused with a different request", errors.New("the key was first used with a different method, path or body"))
+			case !record.Completed:
+				utils.SendErrorResponse(c, http.StatusConflict, "Request with this Idempotency-Key is being processed", errors.New("retry once the first request has completed"))
+			default:
+				replay(c, record)
+			}
+			c.Abort()
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		// A handler that panics leaves the key to be retried, as a server
+		// error would, before the panic reaches the recovery middleware
+		defer func() {
+			if r := recover(); r != nil {
+				c.Writer = recorder.ResponseWriter
+				releaseKey(context.WithoutCancel(ctx), store, storeKey)
+				panic(r)
+			}
+		}()
+		c.Next()
+		c.Writer = recorder.ResponseWriter
+
+		// The request has been handled, so the outcome is stored even when the
+		// client has gone away
+		ctx = context.WithoutCancel(ctx)
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			releaseKey(ctx, store, storeKey)
+			return
+		}
+
+		record := idempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      status,
+			Header:      make(map[string][]string),
+			Body:        recorder.body.Bytes(),
+		}
+		for name, values := range recorder.Header() {
+			if !unreplayedHeaders[name] {
+				record.Header[name] = values
+			}
+		}
+		if err := cache.SetJSON(ctx, store, storeKey, record, window); err != nil {
+			slog.WarnContext(ctx, "idempotency response not stored", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// addRecord claims storeKey for a request in progress
+func addRecord(c *gin.Context, store cache.Cache, storeKey string, record idempotencyRecord) (bool, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return false, err
+	}
+	return store.Add(c.Request.Context(), storeKey, data, idempotencyInProgressTTL)
+}
+
+// releaseKey forgets the claim on storeKey, so the request can be retried
+func releaseKey(ctx context.Context, store cache.Cache, storeKey string) {
+	if err := store.Delete(ctx, storeKey); err != nil {
+		slog.WarnContext(ctx, "idempotency key release failed", slog.String("error", err.Error()))
+	}
+}
+
+// replay sends a stored response
+func replay(c *gin.Context, record idempotencyRecord) {
+	header := c.Writer.Header()
+	for name, values := range record.Header {
+		header[name] = values
+	}
+	header.Set(IdempotentReplayedHeader, "true")
+	c.Status(record.Status)
+	_, _ = c.Writer.Write(record.Body)
+}
+
+// callerID identifies the caller of a request by a hash of its Authorization
+// header, or else by its IP
+func callerID(c *gin.Context) string {
+	if authorization := c.GetHeader("Authorization"); authorization != "" {
+		sum := sha256.Sum256([]byte(authorization))
+		return "auth-" + hex.EncodeToString(sum[:])
+	}
+	return "ip-" + c.ClientIP()
+}
+
+// requestFingerprint hashes the method, path, query and body of a request
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	_, _ = io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+	_, _ = hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// printable reports whether s is made of printable ASCII characters
+func printable(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"api-rentcar/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotentRouter returns a router whose POST /cars counts the cars it
+// creates, answering 500 while fail is set
+func idempotentRouter(store cache.Cache, fail *bool) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Idempotency(store, time.Hour))
+
+	created := 0
+	router.POST("/cars", func(c *gin.Context) {
+		if *fail {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		created++
+		c.Header("Location", fmt.Sprintf("/cars/%d", created))
+		c.String(http.StatusCreated, "car %d", created)
+	})
+	return router, &created
+}
+
+func postWithKey(router http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/cars", strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysFirstResponse(t *testing.T) {
+	fail := false
+	router, created := idempotentRouter(cache.NewMemory(100), &fail)
+
+	first := postWithKey(router, "key-1", `{"name":"Avanza"}`)
+	retry := postWithKey(router, "key-1", `{"name":"Avanza"}`)
+
+	if *created != 1 {
+		t.Fatalf("handler ran %d times, want once", *created)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry got %d %q, want %d %q", retry.Code, retry.Body, first.Code, first.Body)
+	}
+	if retry.Header().Get("Location") != "/cars/1" || retry.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("retry headers = %v", retry.Header())
+	}
+	if first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Error("first response marked as replayed")
+	}
+
+	if w := postWithKey(router, "key-2", `{"name":"Avanza"}`); w.Code != http.StatusCreated || *created != 2 {
+		t.Errorf("another key got %d after %d creations, want a new car", w.Code, *created)
+	}
+}
+
+func TestIdempotencyRejectsKeyReuseWithAnotherRequest(t *testing.T) {
+	fail := false
+	router, created := idempotentRouter(cache.NewMemory(100), &fail)
+
+	postWithKey(router, "key-1", `{"name":"Avanza"}`)
+	w := postWithKey(router, "key-1", `{"name":"Xenia"}`)
+	if w.Code != http.StatusUnprocessableEntity || *created != 1 {
+		t.Errorf("reused key got %d after %d creations, want 422 and 1", w.Code, *created)
+	}
+}
+
+func TestIdempotencyReleasesKeyAfterServerError(t *testing.T) {
+	fail := true
+	router, created := idempotentRouter(cache.NewMemory(100), &fail)
+
+	if w := postWithKey(router, "key-1", `{}`); w.Code != http.StatusInternalServerError {
+		t.Fatalf("first attempt got %d, want 500", w.Code)
+	}
+	fail = false
+	w := postWithKey(router, "key-1", `{}`)
+	if w.Code != http.StatusCreated || *created != 1 || w.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("retry got %d after %d creations, want the request handled again", w.Code, *created)
+	}
+}
+
+func TestIdempotencyConflictWhileInProgress(t *testing.T) {
+	store := cache.NewMemory(100)
+	fail := false
+	router, _ := idempotentRouter(store, &fail)
+
+	// A request holding the key that hasn't completed
+	req := httptest.NewRequest(http.MethodPost, "/cars", strings.NewReader(`{}`))
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = req
+	claim := idempotencyRecord{Fingerprint: requestFingerprint(req, []byte(`{}`))}
+	if claimed, err := addRecord(c, store, "idempotency:"+callerID(c)+":key-1", claim); err != nil || !claimed {
+		t.Fatalf("claim = %v, %v", claimed, err)
+	}
+
+	if w := postWithKey(router, "key-1", `{}`); w.Code != http.StatusConflict {
+		t.Errorf("retry while in progress got %d, want 409", w.Code)
+	}
+}
+
+func TestIdempotencyReleasesKeyAfterPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := cache.NewMemory(100)
+	router := gin.New()
+	router.Use(Recovery())
+	router.Use(Idempotency(store, time.Hour))
+	panics := true
+	router.POST("/cars", func(c *gin.Context) {
+		if panics {
+			panic("handler failed")
+		}
+		c.String(http.StatusCreated, "car")
+	})
+
+	if w := postWithKey(router, "key-1", `{}`); w.Code != http.StatusInternalServerError {
+		t.Fatalf("panicking request got %d, want 500", w.Code)
+	}
+	panics = false
+	if w := postWithKey(router, "key-1", `{}`); w.Code != http.StatusCreated {
+		t.Errorf("retry after a panic got %d, want the request handled again", w.Code)
+	}
+}
+
+func TestIdempotencyRejectsOversizedBody(t *testing.T) {
+	fail := false
+	router, created := idempotentRouter(cache.NewMemory(100), &fail)
+
+	w := postWithKey(router, "key-1", strings.Repeat("x", maxIdempotentBodySize+1))
+	if w.Code != http.StatusRequestEntityTooLarge || *created != 0 {
+		t.Errorf("oversized body got %d after %d creations, want 413 and none", w.Code, *created)
+	}
+}
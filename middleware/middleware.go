@@ -1,37 +1,63 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
+	"api-rentcar/metrics"
 	"api-rentcar/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Logger middleware for request logging
+// Logger middleware logs each request as a structured line, at warn level for
+// client errors and error level for server errors
 func Logger() gin.HandlerFunc {
-	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		return fmt.Sprintf("%s - [%s] \"%s %s %s %d %s \"%s\" %s\"\n",
-			param.ClientIP,
-			param.TimeStamp.Format(time.RFC1123),
-			param.Method,
-			param.Path,
-			param.Request.Proto,
-			param.StatusCode,
-			param.Latency,
-			param.Request.UserAgent(),
-			param.ErrorMessage,
-		)
-	})
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+		query := c.Request.URL.RawQuery
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", path),
+			slog.String("query", query),
+			slog.String("route", c.FullPath()),
+			slog.String("proto", c.Request.Proto),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		}
+		if errors := c.Errors.ByType(gin.ErrorTypePrivate).String(); errors != "" {
+			attrs = append(attrs, slog.String("errors", errors))
+		}
+
+		// c.Request carries the request ID set by RequestID
+		slog.LogAttrs(c.Request.Context(), level, "http request", attrs...)
+	}
 }
 
 // Recovery middleware for panic recovery
 func Recovery() gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		log.Printf("Panic recovered: %v", recovered)
+		slog.ErrorContext(c.Request.Context(), "panic recovered", slog.Any("panic", recovered))
 		utils.SendErrorResponse(c, http.StatusInternalServerError, "Internal server error", nil)
 		c.Abort()
 	})
@@ -41,9 +67,9 @@ func Recovery() gin.HandlerFunc {
 func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
-		c.Header("Access-Control-Expose-Headers", "Content-Length")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Request-ID, traceparent, If-Match, If-None-Match, If-Modified-Since, Prefer, Idempotency-Key")
+		c.Header("Access-Control-Expose-Headers", "Content-Length, X-Request-ID, ETag, Location, Preference-Applied, Idempotent-Replayed")
 		c.Header("Access-Control-Allow-Credentials", "true")
 
 		if c.Request.Method == "OPTIONS" {
@@ -67,7 +93,7 @@ func RateLimiter() gin.HandlerFunc {
 		now := time.Now()
 
 		// Clean old requests
-	if requests, exists := clients[clientIP]; exists {
+		if requests, exists := clients[clientIP]; exists {
 			var validRequests []time.Time
 			for _, reqTime := range requests {
 				if now.Sub(reqTime) < window {
@@ -79,6 +105,7 @@ func RateLimiter() gin.HandlerFunc {
 
 		// Check rate limit
 		if len(clients[clientIP]) >= maxRequests {
+			metrics.RateLimitRejected()
 			utils.SendErrorResponse(c, http.StatusTooManyRequests, "Rate limit exceeded", nil)
 			c.Abort()
 			return
@@ -90,19 +117,66 @@ func RateLimiter() gin.HandlerFunc {
 	}
 }
 
-// RequestID middleware adds a unique request ID to each request
-func RequestID() gin.HandlerFunc {
+// QueryTimeout middleware puts a deadline on the request context. Repositories
+// run their queries with that context, so queries still running when the
+// deadline passes, or when the client disconnects, are cancelled.
+func QueryTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		c.Set(untimedContextKey, c.Request.Context())
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// untimedContextKey stores the request context as it was before QueryTimeout
+const untimedContextKey = "UntimedContext"
+
+// WithoutQueryTimeout returns the request context without the deadline set by
+// QueryTimeout, for handlers that stream responses for longer, e.g., exports.
+// It is still cancelled when the client disconnects.
+func WithoutQueryTimeout(c *gin.Context) context.Context {
+	if ctx, ok := c.Value(untimedContextKey).(context.Context); ok {
+		return ctx
+	}
+	return c.Request.Context()
+}
+
+// RequireIfMatch middleware answers requests without an If-Match header with
+// 428 Precondition Required when required is set
+func RequireIfMatch(required bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		requestID := generateRequestID()
-		c.Header("X-Request-ID", requestID)
-		c.Set("RequestID", requestID)
+		if required && c.GetHeader("If-Match") == "" {
+			utils.SendErrorResponse(c, http.StatusPreconditionRequired, "If-Match header is required",
+				errors.New("send the ETag of the resource, from a GET, in If-Match"))
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
 
-// generateRequestID generates a simple request ID
-func generateRequestID() string {
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+// CacheControl middleware lets clients and proxies reuse responses for
+// maxAge, or, when it is zero, has them revalidate first with the ETag or
+// Last-Modified of the response. Error responses drop the header.
+func CacheControl(maxAge time.Duration) gin.HandlerFunc {
+	value := "no-cache"
+	if maxAge > 0 {
+		value = fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", value)
+		c.Next()
+	}
 }
 
 // SecurityHeaders middleware adds security headers
@@ -115,4 +189,4 @@ func SecurityHeaders() gin.HandlerFunc {
 		c.Header("Content-Security-Policy", "default-src 'self'; style-src 'self' 'unsafe-inline'; script-src 'self' 'unsafe-inline'; img-src 'self' data:")
 		c.Next()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"api-rentcar/docs"
+	"api-rentcar/spreadsheet"
+	"api-rentcar/utils"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPI validation modes
+const (
+	OpenAPIValidationOff    = "off"
+	OpenAPIValidationLog    = "log"
+	OpenAPIValidationReject = "reject"
+)
+
+// OpenAPIValidator middleware checks requests against the published OpenAPI
+// document. In log mode mismatches are logged and the request proceeds, in
+// reject mode they are answered with 400. When validateResponses is set,
+// responses are checked too and mismatches logged, since the response has
+// already been sent by then. Routes missing from the document are skipped.
+func OpenAPIValidator(mode string, validateResponses bool) (gin.HandlerFunc, error) {
+	if mode != OpenAPIValidationLog && mode != OpenAPIValidationReject {
+		return nil, fmt.Errorf("invalid OpenAPI validation mode %q (use off, log or reject)", mode)
+	}
+
+	router, err := newOpenAPIRouter()
+	if err != nil {
+		return nil, err
+	}
+
+	// Exports are checked as opaque files and merge patches as JSON
+	openapi3filter.RegisterBodyDecoder(spreadsheet.ContentType(spreadsheet.FormatXLSX), openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder(spreadsheet.ContentType(spreadsheet.FormatNDJSON), openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder(utils.MergePatchContentType, openapi3filter.RegisteredBodyDecoder("application/json"))
+
+	options := &openapi3filter.Options{
+		MultiError:            true,
+		IncludeResponseStatus: true,
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+	}
+	// Report where the body mismatches without dumping the schema and value
+	options.WithCustomSchemaErrorFunc(func(err *openapi3.SchemaError) string {
+		if pointer := err.JSONPointer(); len(pointer) > 0 {
+			return fmt.Sprintf("%s: %s", strings.Join(pointer, "."), err.Reason)
+		}
+		return err.Reason
+	})
+
+	uncheckedBodyOptions := *options
+	uncheckedBodyOptions.ExcludeRequestBody = true
+
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		}
+		// Uploads are checked by their handlers rather than buffered here, as
+		// form values arrive as strings the schema types don't match. JSON
+		// Patch bodies are operations the documented schema doesn't describe.
+		if strings.HasPrefix(c.ContentType(), "multipart/") || c.ContentType() == utils.JSONPatchContentType {
+			input.Options = &uncheckedBodyOptions
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			slog.WarnContext(c.Request.Context(), "openapi request mismatch",
+				slog.String("method", c.Request.Method),
+				slog.String("path", c.Request.URL.Path),
+				slog.String("error", err.Error()),
+			)
+			if mode == OpenAPIValidationReject {
+				utils.SendErrorResponse(c, http.StatusBadRequest, "Request does not match the API specification", err)
+				c.Abort()
+				return
+			}
+		}
+
+		if !validateResponses {
+			c.Next()
+			return
+		}
+
+		writer := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		response := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 writer.Status(),
+			Header:                 writer.Header(),
+			Options:                options,
+		}
+		// MessagePack and CSV bodies negotiated from the documented JSON can't
+		// be decoded against its schema, so only their status is checked
+		if negotiatedBody(route, writer.Status(), writer.Header().Get("Content-Type")) {
+			response.Options = &openapi3filter.Options{ExcludeResponseBody: true, IncludeResponseStatus: true}
+		}
+		response.SetBodyBytes(writer.body.Bytes())
+		if err := openapi3filter.ValidateResponse(c.Request.Context(), response); err != nil {
+			slog.WarnContext(c.Request.Context(), "openapi response mismatch",
+				slog.String("method", c.Request.Method),
+				slog.String("path", c.Request.URL.Path),
+				slog.Int("status", writer.Status()),
+				slog.String("error", err.Error()),
+			)
+		}
+	}, nil
+}
+
+// newOpenAPIRouter loads the embedded OpenAPI document and builds a router for it.
+// Servers are reduced to their paths so requests match whatever host serves them.
+func newOpenAPIRouter() (routers.Router, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(docs.OpenAPIJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load OpenAPI document: %v", err)
+	}
+
+	servers := openapi3.Servers{}
+	seen := make(map[string]bool)
+	for _, server := range doc.Servers {
+		u, err := url.Parse(server.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid server URL %q in OpenAPI document: %v", server.URL, err)
+		}
+		if !seen[u.Path] {
+			seen[u.Path] = true
+			servers = append(servers, &openapi3.Server{URL: u.Path})
+		}
+	}
+	doc.Servers = servers
+
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %v", err)
+	}
+
+	return gorillamux.NewRouter(doc)
+}
+
+// negotiatedBody reports whether a response is MessagePack, or CSV where the
+// route documents a JSON body for it rather than a file
+func negotiatedBody(route *routers.Route, status int, contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case utils.MediaTypeMsgPack, "application/x-msgpack":
+		return true
+	case utils.MediaTypeCSV:
+		response := route.Operation.Responses.Status(status)
+		if response == nil || response.Value == nil {
+			return true
+		}
+		media := response.Value.Content.Get(mediaType)
+		return media == nil || media.Schema == nil || media.Schema.Value == nil || media.Schema.Value.Type != openapi3.TypeString
+	}
+	return false
+}
+
+// bodyRecorder keeps a copy of the response body while writing it through
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController
+func (w *bodyRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
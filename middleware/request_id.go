@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"regexp"
+	"strings"
+
+	"api-rentcar/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Request ID headers
+const (
+	RequestIDHeader   = "X-Request-ID"
+	TraceparentHeader = "traceparent"
+)
+
+// RequestIDKey is the gin context key the request ID is stored under
+const RequestIDKey = "RequestID"
+
+// validRequestID matches inbound request IDs we are willing to reuse: short,
+// printable tokens that are safe to echo in headers and logs
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:/+=-]{1,128}$`)
+
+// traceparentPattern matches a W3C traceparent header:
+// version-trace_id-parent_id-flags, e.g., 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+var traceparentPattern = regexp.MustCompile(`^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})(-.*)?$`)
+
+// RequestID middleware assigns each request an ID, returns it in the
+// X-Request-ID header and carries it in the request context for logging.
+// When trustInbound is set, e.g., behind a gateway that assigns IDs, a valid
+// inbound X-Request-ID is reused, or else the trace ID of a valid W3C
+// traceparent header, whose trace is also added to the request's log lines.
+// Otherwise a new UUID is generated and inbound headers are ignored.
+func RequestID(trustInbound bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// Inbound IDs end up in logs, so they are only read from trusted callers
+		requestID := ""
+		if trustInbound {
+			traceID, parentID, traced := ParseTraceparent(c.GetHeader(TraceparentHeader))
+			if traced {
+				ctx = logger.WithTrace(ctx, traceID, parentID)
+			}
+			if inbound := strings.TrimSpace(c.GetHeader(RequestIDHeader)); validRequestID.MatchString(inbound) {
+				requestID = inbound
+			} else if traced {
+				requestID = traceID
+			}
+		}
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+
+		c.Header(RequestIDHeader, requestID)
+		c.Set(RequestIDKey, requestID)
+		c.Request = c.Request.WithContext(logger.WithRequestID(ctx, requestID))
+		c.Next()
+	}
+}
+
+// ParseTraceparent extracts the trace ID and parent span ID from a W3C
+// traceparent header, reporting whether the header is valid
+func ParseTraceparent(header string) (traceID, parentID string, ok bool) {
+	match := traceparentPattern.FindStringSubmatch(strings.TrimSpace(header))
+	if match == nil {
+		return "", "", false
+	}
+
+	version, traceID, parentID := match[1], match[2], match[3]
+	// Version ff is invalid, version 00 has no trailing fields, and all-zero IDs are invalid
+	if version == "ff" || (version == "00" && match[5] != "") {
+		return "", "", false
+	}
+	if traceID == strings.Repeat("0", 32) || parentID == strings.Repeat("0", 16) {
+		return "", "", false
+	}
+
+	return traceID, parentID, true
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"api-rentcar/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// serveRequestID sends a request with headers through RequestID and returns
+// the response and the line the handler logged
+func serveRequestID(t *testing.T, trustInbound bool, headers map[string]string) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+
+	var logs bytes.Buffer
+	log, err := logger.New(&logs, "info", logger.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID(trustInbound))
+	router.GET("/", func(c *gin.Context) {
+		log.InfoContext(c.Request.Context(), "handled")
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w, logs.String()
+}
+
+func TestRequestIDReusesTrustedInboundHeaders(t *testing.T) {
+	w, logs := serveRequestID(t, true, map[string]string{RequestIDHeader: "gateway-123"})
+	if got := w.Header().Get(RequestIDHeader); got != "gateway-123" {
+		t.Errorf("request ID = %q, want the inbound one", got)
+	}
+	if !strings.Contains(logs, `"request_id":"gateway-123"`) {
+		t.Errorf("log line %q lacks the request ID", logs)
+	}
+
+	w, logs = serveRequestID(t, true, map[string]string{TraceparentHeader: testTraceparent})
+	if got := w.Header().Get(RequestIDHeader); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("request ID = %q, want the trace ID", got)
+	}
+	if !strings.Contains(logs, `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`) {
+		t.Errorf("log line %q lacks the trace ID", logs)
+	}
+}
+
+func TestRequestIDIgnoresUntrustedInboundHeaders(t *testing.T) {
+	w, logs := serveRequestID(t, false, map[string]string{
+		RequestIDHeader:   "gateway-123",
+		TraceparentHeader: testTraceparent,
+	})
+	if got := w.Header().Get(RequestIDHeader); got == "gateway-123" || got == "" {
+		t.Errorf("request ID = %q, want a new one", got)
+	}
+	if strings.Contains(logs, "trace_id") || strings.Contains(logs, "gateway-123") {
+		t.Errorf("log line %q carries inbound IDs", logs)
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		header string
+		ok     bool
+	}{
+		{testTraceparent, true},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"not a traceparent", false},
+	}
+	for _, tt := range tests {
+		if _, _, ok := ParseTraceparent(tt.header); ok != tt.ok {
+			t.Errorf("ParseTraceparent(%q) ok = %v, want %v", tt.header, ok, tt.ok)
+		}
+	}
+}
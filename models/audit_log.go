@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Audit actions
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditLog records a change to an entity: who made it, from where, and the
+// fields it changed
+// @Description Audit log entry
+type AuditLog struct {
+	// Primary key
+	// @Description Unique identifier
+	// @Example 1
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id" example:"1"`
+
+	// Who made the change: admin, a caller identified by its bearer token, or anonymous
+	Actor string `gorm:"type:varchar(100);not null;index" json:"actor" example:"admin"`
+
+	// create, update or delete
+	Action string `gorm:"type:varchar(10);not null" json:"action" example:"update"`
+
+	// Changed entity, e.g., car, and its ID
+	EntityType string `gorm:"type:varchar(50);not null;index:idx_audit_logs_entity" json:"entity_type" example:"car"`
+	EntityID   string `gorm:"type:varchar(64);not null;index:idx_audit_logs_entity" json:"entity_id" example:"1"`
+
+	// Changed fields as a JSON object of {"before": ..., "after": ...} by
+	// field name; creations have no before and deletions no after
+	Changes string `gorm:"type:text" json:"changes"`
+
+	// Request that made the change and the IP it came from
+	RequestID string `gorm:"type:varchar(128);index" json:"request_id" example:"3f8c2a9e-6b1d-4c7a-9f2e-1a2b3c4d5e6f"`
+	IP        string `gorm:"type:varchar(45)" json:"ip" example:"203.0.113.7"`
+
+	// @Description Time of the change
+	// @Example "2023-01-01T00:00:00Z"
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
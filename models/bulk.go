@@ -0,0 +1,9 @@
+package models
+
+// BulkCarResult is the outcome of one item of a bulk car operation
+type BulkCarResult struct {
+	Index  int
+	ID     uint
+	Car    *Car     // created or updated car, nil for deletes
+	Errors []string // why the item failed, empty when it succeeded
+}
@@ -68,7 +68,7 @@ type Car struct {
 	// Category of the car
 	// @Description Category of the car
 	// @Example "SUV"
-	Category CarCategory `gorm:"type:enum('City Car','LCGC','Compact','MPV','SUV','Crossover');not null;index" json:"category" validate:"required,oneof=CityCar LCGC Compact MPV SUV Crossover" example:"SUV"`
+	Category CarCategory `gorm:"type:enum('City Car','LCGC','Compact','MPV','SUV','Crossover');not null;index" json:"category" validate:"required,oneof='City Car' LCGC Compact MPV SUV Crossover" example:"SUV"`
 
 	// Price Per Day of the car
 	// @Description Price Per Day of the car
@@ -108,7 +108,7 @@ type Car struct {
 	// License plate of the car
 	// @Description License plate of the car
 	// @Example "ABC123"
-	LicensePlate string `gorm:"type:varchar(10);not null" json:"license_plate" validate:"required,min=3,max=10" example:"ABC123"`
+	LicensePlate string `gorm:"type:varchar(10);not null;uniqueIndex" json:"license_plate" validate:"required,min=3,max=10" example:"ABC123"`
 
 	// Machine number of the car
 	// @Description Machine number of the car
@@ -120,6 +120,11 @@ type Car struct {
 	// @Example true
 	IsAvailable bool `gorm:"type:boolean;not null;index" json:"is_available" validate:"required" example:"true"`
 
+	// Version, incremented on every update for optimistic concurrency control
+	// @Description Version of the car, incremented on every update
+	// @Example 1
+	Version uint `gorm:"not null;default:1" json:"version" example:"1"`
+
 	// Timestamps
 	// @Description Creation timestamp
 	// @Example "2023-01-01T00:00:00Z"
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// Car price statuses
+const (
+	CarPriceApplied   = "applied"
+	CarPriceScheduled = "scheduled"
+)
+
+// CarPrice is a set of price tiers of a car and the time they take effect.
+// Every change of a car's tiers is recorded as an applied CarPrice; a
+// scheduled one is applied to the car once its effective time comes.
+// @Description Car price tiers effective from a point in time
+type CarPrice struct {
+	// Primary key
+	// @Description Unique identifier
+	// @Example 1
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id" example:"1"`
+
+	// Car the prices are for
+	// @Description ID of the car
+	// @Example 1
+	CarID uint `gorm:"not null;index:idx_car_prices_car_effective" json:"car_id" example:"1"`
+
+	// Price Per Day of the car
+	// @Description Price Per Day of the car
+	// @Example 10000
+	PricePerDay float64 `gorm:"type:decimal(10,2);not null" json:"price_per_day" example:"10000"`
+
+	// Price Per Week of the car
+	// @Description Price Per Week of the car
+	// @Example 7000
+	PricePerWeek float64 `gorm:"type:decimal(10,2);not null" json:"price_per_week" example:"7000"`
+
+	// Price Per Month of the car
+	// @Description Price Per Month of the car
+	// @Example 40000
+	PricePerMonth float64 `gorm:"type:decimal(10,2);not null" json:"price_per_month" example:"40000"`
+
+	// Time the prices take effect
+	// @Description Time the prices take effect
+	// @Example "2023-01-01T00:00:00Z"
+	EffectiveFrom time.Time `gorm:"not null;index:idx_car_prices_car_effective" json:"effective_from" example:"2023-01-01T00:00:00Z"`
+
+	// Time the prices were applied to the car, nil while scheduled
+	// @Description Time the prices were applied to the car
+	// @Example "2023-01-01T00:00:00Z"
+	AppliedAt *time.Time `gorm:"index" json:"applied_at,omitempty" example:"2023-01-01T00:00:00Z"`
+
+	// @Description Creation timestamp
+	// @Example "2023-01-01T00:00:00Z"
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// TableName returns the table name for the CarPrice model
+func (CarPrice) TableName() string {
+	return "car_prices"
+}
+
+// Status returns whether the prices have been applied or are scheduled
+func (p *CarPrice) Status() string {
+	if p.AppliedAt == nil {
+		return CarPriceScheduled
+	}
+	return CarPriceApplied
+}
+
+// SamePrices reports whether car has the price tiers of p
+func (p *CarPrice) SamePrices(car *Car) bool {
+	return p.PricePerDay == car.PricePerDay && p.PricePerWeek == car.PricePerWeek && p.PricePerMonth == car.PricePerMonth
+}
@@ -0,0 +1,33 @@
+package models
+
+// CarStats holds fleet statistics aggregated from the cars table
+type CarStats struct {
+	Total           int64
+	Available       int64
+	ByBrand         []GroupCount
+	ByCategory      []GroupCount
+	ByTransmission  []GroupCount
+	ByYear          []YearCount
+	PriceByCategory []CategoryPriceStats
+}
+
+// GroupCount is the number of cars sharing a column value
+type GroupCount struct {
+	Value string
+	Count int64
+}
+
+// YearCount is the number of cars built in a year
+type YearCount struct {
+	Year  int
+	Count int64
+}
+
+// CategoryPriceStats summarizes the daily prices of a category
+type CategoryPriceStats struct {
+	Category CarCategory
+	Count    int64
+	AvgPrice float64
+	MinPrice float64
+	MaxPrice float64
+}
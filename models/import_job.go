@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Import job statuses
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+// ImportJob tracks the progress of a fleet import
+type ImportJob struct {
+	ID            string
+	Status        string
+	DryRun        bool
+	Mapping       map[string]string // car field -> column header
+	TotalRows     int
+	ProcessedRows int
+	Created       int // cars created, or that would be created in a dry run
+	Updated       int // cars updated, or that would be updated in a dry run
+	Failed        int
+	RowErrors     []ImportRowError
+	Error         string // why the job stopped, when it failed
+	CreatedAt     time.Time
+	FinishedAt    *time.Time
+}
+
+// ImportRowError reports why a spreadsheet row wasn't imported
+type ImportRowError struct {
+	Row          int // row number in the spreadsheet, the header being row 1
+	LicensePlate string
+	Errors       []string
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Pricing rule adjustment types
+const (
+	// AdjustmentPercent changes the daily price by Amount percent
+	AdjustmentPercent = "percent"
+	// AdjustmentFixed changes the daily price by Amount
+	AdjustmentFixed = "fixed"
+)
+
+// PricingRule adjusts the daily price of the cars in its scope on the days it
+// covers, e.g., +25% for SUVs during Lebaran or -10% on weekdays. When several
+// rules cover a day, the one with the highest priority applies.
+// @Description Pricing rule adjusting daily car prices
+type PricingRule struct {
+	// Primary key
+	// @Description Unique identifier
+	// @Example 1
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id" example:"1"`
+
+	// Name of the rule
+	// @Description Name of the rule
+	// @Example "Lebaran surcharge"
+	Name string `gorm:"type:varchar(100);not null" json:"name" example:"Lebaran surcharge"`
+
+	// First and last day the rule covers, inclusive; nil for no bound
+	// @Description First day the rule covers
+	// @Example "2024-04-05T00:00:00Z"
+	StartDate *time.Time `gorm:"type:date;index" json:"start_date,omitempty" example:"2024-04-05T00:00:00Z"`
+	// @Description Last day the rule covers
+	// @Example "2024-04-15T00:00:00Z"
+	EndDate *time.Time `gorm:"type:date;index" json:"end_date,omitempty" example:"2024-04-15T00:00:00Z"`
+
+	// Comma-separated lowercase weekdays the rule covers, e.g.,
+	// "saturday,sunday"; empty for every day
+	// @Description Weekdays the rule covers
+	// @Example "saturday,sunday"
+	Weekdays string `gorm:"type:varchar(70)" json:"weekdays" example:"saturday,sunday"`
+
+	// Category and brand of the cars the rule covers; empty for all
+	// @Description Category of the cars the rule covers
+	// @Example "SUV"
+	Category CarCategory `gorm:"type:varchar(20);index" json:"category" example:"SUV"`
+	// @Description Brand of the cars the rule covers
+	// @Example "Toyota"
+	Brand Brand `gorm:"type:varchar(20);index" json:"brand" example:"Toyota"`
+
+	// How the daily price is adjusted: by Amount percent or by Amount
+	// @Description percent or fixed
+	// @Example "percent"
+	AdjustmentType string `gorm:"type:varchar(10);not null" json:"adjustment_type" example:"percent"`
+	// @Description Adjustment, negative for discounts
+	// @Example 25
+	Amount float64 `gorm:"type:decimal(10,2);not null" json:"amount" example:"25"`
+
+	// Rules with a higher priority win over others covering the same day
+	// @Description Priority among rules covering the same day
+	// @Example 10
+	Priority int `gorm:"not null;default:0" json:"priority" example:"10"`
+
+	// @Description Whether the rule is applied
+	// @Example true
+	IsActive bool `gorm:"not null;index" json:"is_active" example:"true"`
+
+	// Timestamps
+	// @Description Creation timestamp
+	// @Example "2023-01-01T00:00:00Z"
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at" example:"2023-01-01T00:00:00Z"`
+
+	// @Description Last update timestamp
+	// @Example "2023-01-01T00:00:00Z"
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// TableName returns the table name for the PricingRule model
+func (PricingRule) TableName() string {
+	return "pricing_rules"
+}
+
+// WeekdayList returns the weekdays the rule covers, empty for every day
+func (r *PricingRule) WeekdayList() []string {
+	if r.Weekdays == "" {
+		return []string{}
+	}
+	return strings.Split(r.Weekdays, ",")
+}
+
+// Covers reports whether the rule covers a day for a car. Days compare as
+// calendar dates, each in its own location, as DATE columns load as midnight
+// in the connection's time zone rather than UTC.
+func (r *PricingRule) Covers(car *Car, day time.Time) bool {
+	if r.Category != "" && r.Category != car.Category {
+		return false
+	}
+	if r.Brand != "" && r.Brand != car.Brand {
+		return false
+	}
+
+	date := day.Format(time.DateOnly)
+	if r.StartDate != nil && date < r.StartDate.Format(time.DateOnly) {
+		return false
+	}
+	if r.EndDate != nil && date > r.EndDate.Format(time.DateOnly) {
+		return false
+	}
+
+	if r.Weekdays == "" {
+		return true
+	}
+	weekday := strings.ToLower(day.Weekday().String())
+	for _, name := range r.WeekdayList() {
+		if name == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// Adjust returns a daily price adjusted by the rule, never below zero
+func (r *PricingRule) Adjust(price float64) float64 {
+	if r.AdjustmentType == AdjustmentPercent {
+		price += price * r.Amount / 100
+	} else {
+		price += r.Amount
+	}
+	if price < 0 {
+		return 0
+	}
+	return price
+}
+
+// PriceQuote is the price of renting a car for a period: the car's tiered
+// price spread over the days, with the tiers in effect on each day, and each
+// day adjusted by the pricing rule covering it
+type PriceQuote struct {
+	CarID     uint
+	StartDate time.Time
+	EndDate   time.Time
+	// Days of the period, split into 30-day months, 7-day weeks and days
+	// priced with the car's monthly, weekly and daily tiers
+	Days      int
+	Months    int
+	Weeks     int
+	ExtraDays int
+	// BasePrice is the tiered price without pricing rules
+	BasePrice float64
+	Total     float64
+	Lines     []PriceQuoteDay
+}
+
+// PriceQuoteDay is the price of one day of a PriceQuote
+type PriceQuoteDay struct {
+	Date time.Time
+	// BasePrice is the day's share of the tiered price
+	BasePrice float64
+	Price     float64
+	// Rule is the pricing rule applied to the day, nil when none covers it
+	Rule *PricingRule
+}
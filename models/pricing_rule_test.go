@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPricingRuleCovers(t *testing.T) {
+	// DATE columns load as midnight in the connection's time zone, east of
+	// UTC here, so in UTC they fall on the day before
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	start := time.Date(2024, 4, 5, 0, 0, 0, 0, jakarta)
+	end := time.Date(2024, 4, 15, 0, 0, 0, 0, jakarta)
+	rule := PricingRule{StartDate: &start, EndDate: &end, Category: SUV}
+	suv := &Car{Category: SUV, Brand: Toyota}
+
+	tests := []struct {
+		name string
+		car  *Car
+		day  time.Time
+		want bool
+	}{
+		{"first day", suv, time.Date(2024, 4, 5, 0, 0, 0, 0, time.UTC), true},
+		{"last day", suv, time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC), true},
+		{"day before", suv, time.Date(2024, 4, 4, 0, 0, 0, 0, time.UTC), false},
+		{"day after", suv, time.Date(2024, 4, 16, 0, 0, 0, 0, time.UTC), false},
+		{"other category", &Car{Category: MPV, Brand: Toyota}, time.Date(2024, 4, 8, 0, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		if got := rule.Covers(tt.car, tt.day); got != tt.want {
+			t.Errorf("%s: Covers = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	weekend := PricingRule{Weekdays: "saturday,sunday"}
+	if !weekend.Covers(suv, time.Date(2024, 4, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Error("weekend rule doesn't cover a Saturday")
+	}
+	if weekend.Covers(suv, time.Date(2024, 4, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Error("weekend rule covers a Monday")
+	}
+}
+
+func TestPricingRuleAdjust(t *testing.T) {
+	tests := []struct {
+		rule  PricingRule
+		price float64
+		want  float64
+	}{
+		{PricingRule{AdjustmentType: AdjustmentPercent, Amount: 25}, 200, 250},
+		{PricingRule{AdjustmentType: AdjustmentPercent, Amount: -10}, 200, 180},
+		{PricingRule{AdjustmentType: AdjustmentFixed, Amount: 50}, 200, 250},
+		{PricingRule{AdjustmentType: AdjustmentFixed, Amount: -300}, 200, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Adjust(tt.price); got != tt.want {
+			t.Errorf("%s %v: Adjust(%v) = %v, want %v", tt.rule.AdjustmentType, tt.rule.Amount, tt.price, got, tt.want)
+		}
+	}
+}
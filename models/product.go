@@ -23,6 +23,11 @@ type Product struct {
 	// @Example "This is a sample product description"
 	Description string `gorm:"type:text" json:"description" validate:"required,min=10,max=500" example:"This is a sample product description"`
 
+	// Version, incremented on every update for optimistic concurrency control
+	// @Description Version of the product, incremented on every update
+	// @Example 1
+	Version uint `gorm:"not null;default:1" json:"version" example:"1"`
+
 	// Timestamps
 	// @Description Creation timestamp
 	// @Example "2023-01-01T00:00:00Z"
@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"context"
+
+	"api-rentcar/models"
+	"gorm.io/gorm"
+)
+
+// AuditRepository implements AuditRepositoryInterface
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new audit log repository
+func NewAuditRepository(db *gorm.DB) AuditRepositoryInterface {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+// GetAll retrieves the audit log entries matching filter with pagination,
+// newest first
+func (r *AuditRepository) GetAll(ctx context.Context, filter AuditLogFilter, page, limit int) ([]models.AuditLog, int64, error) {
+	var entries []models.AuditLog
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.AuditLog{})
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+
+	// Count total records
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	// Get paginated results
+	err := query.Order("created_at DESC").Order("id DESC").Offset(offset).Limit(limit).Find(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,19 @@
+package audit
+
+import (
+	"context"
+
+	"api-rentcar/models"
+)
+
+// AuditLogFilter selects audit log entries; empty fields match every entry
+type AuditLogFilter struct {
+	EntityType string
+	EntityID   string
+	Action     string
+}
+
+// AuditRepositoryInterface defines the contract for audit log data operations
+type AuditRepositoryInterface interface {
+	GetAll(ctx context.Context, filter AuditLogFilter, page, limit int) ([]models.AuditLog, int64, error)
+}
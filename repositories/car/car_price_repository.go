@@ -0,0 +1,127 @@
+package car
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"api-rentcar/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrPriceApplied is returned for a scheduled price change that has already
+// been applied or cancelled
+var ErrPriceApplied = errors.New("car price already applied")
+
+// recordPrice adds the price tiers of car to its price history, effective
+// from its last update, unless they are the tiers last applied
+func recordPrice(tx *gorm.DB, car *models.Car) error {
+	var latest models.CarPrice
+	err := tx.Where("car_id = ? AND applied_at IS NOT NULL", car.ID).
+		Order("effective_from DESC").Order("id DESC").
+		Limit(1).Find(&latest).Error
+	if err != nil {
+		return err
+	}
+	if latest.ID != 0 && latest.SamePrices(car) {
+		return nil
+	}
+
+	effectiveFrom := car.UpdatedAt
+	if effectiveFrom.IsZero() {
+		effectiveFrom = time.Now()
+	}
+	return tx.Create(&models.CarPrice{
+		CarID:         car.ID,
+		PricePerDay:   car.PricePerDay,
+		PricePerWeek:  car.PricePerWeek,
+		PricePerMonth: car.PricePerMonth,
+		EffectiveFrom: effectiveFrom,
+		AppliedAt:     &effectiveFrom,
+	}).Error
+}
+
+// GetPrices retrieves the price history and scheduled price changes of a car,
+// latest effective first
+func (r *CarRepository) GetPrices(ctx context.Context, carID uint) ([]models.CarPrice, error) {
+	var prices []models.CarPrice
+	err := r.db.WithContext(ctx).Where("car_id = ?", carID).
+		Order("effective_from DESC").Order("id DESC").
+		Find(&prices).Error
+	return prices, err
+}
+
+// GetPriceAt retrieves the prices of a car that were in effect at a time
+func (r *CarRepository) GetPriceAt(ctx context.Context, carID uint, at time.Time) (*models.CarPrice, error) {
+	var price models.CarPrice
+	err := r.db.WithContext(ctx).
+		Where("car_id = ? AND applied_at IS NOT NULL AND effective_from <= ?", carID, at).
+		Order("effective_from DESC").Order("id DESC").
+		First(&price).Error
+	if err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
+
+// SchedulePrice saves a price change to apply once it takes effect
+func (r *CarRepository) SchedulePrice(ctx context.Context, price *models.CarPrice) error {
+	price.AppliedAt = nil
+	return r.db.WithContext(ctx).Create(price).Error
+}
+
+// DeleteScheduledPrice cancels a scheduled price change of a car. It returns
+// gorm.ErrRecordNotFound when the car has no such change, and ErrPriceApplied
+// when it has already been applied.
+func (r *CarRepository) DeleteScheduledPrice(ctx context.Context, carID, priceID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var price models.CarPrice
+		if err := tx.Where("car_id = ?", carID).First(&price, priceID).Error; err != nil {
+			return err
+		}
+		result := tx.Where("applied_at IS NULL").Delete(&price)
+		if result.Error == nil && result.RowsAffected == 0 {
+			return ErrPriceApplied
+		}
+		return result.Error
+	})
+}
+
+// GetDuePrices retrieves up to limit scheduled price changes that have taken
+// effect by now, earliest first
+func (r *CarRepository) GetDuePrices(ctx context.Context, now time.Time, limit int) ([]models.CarPrice, error) {
+	var prices []models.CarPrice
+	err := r.db.WithContext(ctx).
+		Where("applied_at IS NULL AND effective_from <= ?", now).
+		Order("effective_from").Order("id").
+		Limit(limit).Find(&prices).Error
+	return prices, err
+}
+
+// ApplyPrice sets the price tiers of a scheduled price change on its car and
+// marks it applied, in one transaction. ErrPriceApplied is returned when the
+// change was applied or cancelled in the meantime, e.g., by another instance.
+func (r *CarRepository) ApplyPrice(ctx context.Context, price *models.CarPrice) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repo := &CarRepository{db: tx}
+
+		appliedAt := time.Now()
+		result := tx.Model(price).Where("applied_at IS NULL").Update("applied_at", appliedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPriceApplied
+		}
+
+		car, err := repo.GetByID(ctx, price.CarID, nil)
+		if err != nil {
+			return err
+		}
+		car.PricePerDay = price.PricePerDay
+		car.PricePerWeek = price.PricePerWeek
+		car.PricePerMonth = price.PricePerMonth
+		return repo.Update(ctx, car)
+	})
+}
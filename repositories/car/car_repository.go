@@ -1,11 +1,22 @@
 package car
 
 import (
+	"context"
+	"errors"
+
 	"api-rentcar/models"
+	"api-rentcar/repositories"
 
 	"gorm.io/gorm"
 )
 
+// ErrDuplicateLicensePlate is returned when a car would share its license
+// plate with another car
+var ErrDuplicateLicensePlate = errors.New("license plate already in use")
+
+// ErrVersionConflict is returned when a car changed since it was read
+var ErrVersionConflict = errors.New("car version conflict")
+
 // CarRepository implements CarRepositoryInterface
 type CarRepository struct {
 	db *gorm.DB
@@ -18,28 +29,45 @@ func NewCarRepository(db *gorm.DB) CarRepositoryInterface {
 	}
 }
 
-// Create creates a new car in the database
-func (r *CarRepository) Create(car *models.Car) error {
-	return r.db.Create(car).Error
+// Create creates a new car in the database, starting its price history
+func (r *CarRepository) Create(ctx context.Context, car *models.Car) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(car).Error; err != nil {
+			return translateError(err)
+		}
+		return recordPrice(tx, car)
+	})
 }
 
-// GetByID retrieves a car by its ID
-func (r *CarRepository) GetByID(id uint) (*models.Car, error) {
+// GetByID retrieves a car by its ID, loading only the given JSON fields
+// unless fields is empty
+func (r *CarRepository) GetByID(ctx context.Context, id uint, fields []string) (*models.Car, error) {
 	var car models.Car
-	err := r.db.First(&car, id).Error
+	err := r.db.WithContext(ctx).Scopes(repositories.SelectFields(fields)).First(&car, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &car, nil
 }
 
-// GetAll retrieves all cars with pagination
-func (r *CarRepository) GetAll(page, limit int, available *bool) ([]models.Car, int64, error) {
+// GetByLicensePlate retrieves a car by its license plate
+func (r *CarRepository) GetByLicensePlate(ctx context.Context, licensePlate string) (*models.Car, error) {
+	var car models.Car
+	err := r.db.WithContext(ctx).Where("license_plate = ?", licensePlate).First(&car).Error
+	if err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+// GetAll retrieves all cars with pagination, loading only the given JSON
+// fields unless fields is empty
+func (r *CarRepository) GetAll(ctx context.Context, page, limit int, available *bool, fields []string) ([]models.Car, int64, error) {
 	var cars []models.Car
 	var total int64
 
 	// Initialize query
-	query := r.db.Model(&models.Car{})
+	query := r.db.WithContext(ctx).Model(&models.Car{})
 
 	// Apply availability filter if provided
 	if available != nil {
@@ -55,7 +83,7 @@ func (r *CarRepository) GetAll(page, limit int, available *bool) ([]models.Car,
 	offset := (page - 1) * limit
 
 	// Get paginated results with filter applied
-	err := query.Offset(offset).Limit(limit).Find(&cars).Error
+	err := query.Scopes(repositories.SelectFields(fields)).Order("id").Offset(offset).Limit(limit).Find(&cars).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -63,26 +91,154 @@ func (r *CarRepository) GetAll(page, limit int, available *bool) ([]models.Car,
 	return cars, total, nil
 }
 
-// Update updates an existing car
-func (r *CarRepository) Update(car *models.Car) error {
-	return r.db.Save(car).Error
+// FindInBatches passes all cars matching the filter to fn, batchSize at a time
+// in ID order, so large result sets aren't loaded at once
+func (r *CarRepository) FindInBatches(ctx context.Context, available *bool, batchSize int, fn func(cars []models.Car) error) error {
+	var cars []models.Car
+
+	query := r.db.WithContext(ctx).Model(&models.Car{})
+	if available != nil {
+		query = query.Where("is_available = ?", *available)
+	}
+
+	return query.FindInBatches(&cars, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(cars)
+	}).Error
 }
 
-// Delete deletes a car by its ID
-func (r *CarRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Car{}, id).Error
+// Update saves an existing car and increments its version, provided the
+// version is still the one the car was read with. Otherwise the car changed
+// in the meantime and ErrVersionConflict is returned. Changed price tiers are
+// added to the car's price history.
+func (r *CarRepository) Update(ctx context.Context, car *models.Car) error {
+	version := car.Version
+	car.Version++
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(car).
+			Where("version = ?", version).
+			Select("*").
+			Omit("id", "created_at").
+			Updates(car)
+		if result.Error == nil && result.RowsAffected == 0 {
+			result.Error = ErrVersionConflict
+		}
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+		return recordPrice(tx, car)
+	})
+	if err != nil {
+		car.Version = version
+	}
+	return err
+}
+
+// Delete deletes a car by its ID along with its scheduled price changes; its
+// price history is kept. When version isn't zero, the car is only deleted at
+// that version, and ErrVersionConflict is returned otherwise.
+func (r *CarRepository) Delete(ctx context.Context, id uint, version uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx
+		if version != 0 {
+			query = query.Where("version = ?", version)
+		}
+
+		result := query.Delete(&models.Car{}, id)
+		if result.Error == nil && version != 0 && result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		return tx.Where("car_id = ? AND applied_at IS NULL", id).Delete(&models.CarPrice{}).Error
+	})
 }
 
 // Count returns the total number of cars
-func (r *CarRepository) Count() (int64, error) {
+func (r *CarRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Car{}).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&models.Car{}).Count(&count).Error
 	return count, err
 }
 
+// GetStats aggregates fleet statistics in the database
+func (r *CarRepository) GetStats(ctx context.Context) (*models.CarStats, error) {
+	db := r.db.WithContext(ctx)
+	stats := &models.CarStats{}
+
+	var availability []struct {
+		IsAvailable bool
+		Count       int64
+	}
+	if err := db.Model(&models.Car{}).
+		Select("is_available, COUNT(*) AS count").
+		Group("is_available").
+		Scan(&availability).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range availability {
+		stats.Total += row.Count
+		if row.IsAvailable {
+			stats.Available += row.Count
+		}
+	}
+
+	groups := map[string]*[]models.GroupCount{
+		"brand":        &stats.ByBrand,
+		"category":     &stats.ByCategory,
+		"transmission": &stats.ByTransmission,
+	}
+	for column, counts := range groups {
+		if err := db.Model(&models.Car{}).
+			Select(column + " AS value, COUNT(*) AS count").
+			Group(column).
+			Order(column).
+			Scan(counts).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.Model(&models.Car{}).
+		Select("year, COUNT(*) AS count").
+		Group("year").
+		Order("year").
+		Scan(&stats.ByYear).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Model(&models.Car{}).
+		Select("category, COUNT(*) AS count, AVG(price_per_day) AS avg_price, MIN(price_per_day) AS min_price, MAX(price_per_day) AS max_price").
+		Group("category").
+		Order("category").
+		Scan(&stats.PriceByCategory).Error; err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
 // ExistsByID checks if a car exists by its ID
-func (r *CarRepository) ExistsByID(id uint) (bool, error) {
+func (r *CarRepository) ExistsByID(ctx context.Context, id uint) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.Car{}).Where("id = ?", id).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&models.Car{}).Where("id = ?", id).Count(&count).Error
 	return count > 0, err
 }
+
+// Transaction runs fn with a repository bound to a database transaction, which
+// is committed when fn returns nil and rolled back otherwise. Transactions
+// started on that repository are nested as savepoints.
+func (r *CarRepository) Transaction(ctx context.Context, fn func(repo CarRepositoryInterface) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&CarRepository{db: tx})
+	})
+}
+
+// translateError returns ErrDuplicateLicensePlate for unique index violations,
+// the license plate being the only unique column besides the ID
+func translateError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicateLicensePlate
+	}
+	return err
+}
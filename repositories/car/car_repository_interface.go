@@ -1,16 +1,29 @@
 package car
 
 import (
+	"context"
+	"time"
+
 	"api-rentcar/models"
 )
 
 // CarRepositoryInterface defines the contract for car data operations
 type CarRepositoryInterface interface {
-	Create(car *models.Car) error
-	GetByID(id uint) (*models.Car, error)
-	GetAll(page, limit int, available *bool) ([]models.Car, int64, error)
-	Update(car *models.Car) error
-	Delete(id uint) error
-	Count() (int64, error)
-	ExistsByID(id uint) (bool, error)
+	Create(ctx context.Context, car *models.Car) error
+	GetByID(ctx context.Context, id uint, fields []string) (*models.Car, error)
+	GetByLicensePlate(ctx context.Context, licensePlate string) (*models.Car, error)
+	GetAll(ctx context.Context, page, limit int, available *bool, fields []string) ([]models.Car, int64, error)
+	FindInBatches(ctx context.Context, available *bool, batchSize int, fn func(cars []models.Car) error) error
+	Update(ctx context.Context, car *models.Car) error
+	Delete(ctx context.Context, id uint, version uint) error
+	Count(ctx context.Context) (int64, error)
+	GetStats(ctx context.Context) (*models.CarStats, error)
+	ExistsByID(ctx context.Context, id uint) (bool, error)
+	Transaction(ctx context.Context, fn func(repo CarRepositoryInterface) error) error
+	GetPrices(ctx context.Context, carID uint) ([]models.CarPrice, error)
+	GetPriceAt(ctx context.Context, carID uint, at time.Time) (*models.CarPrice, error)
+	SchedulePrice(ctx context.Context, price *models.CarPrice) error
+	DeleteScheduledPrice(ctx context.Context, carID, priceID uint) error
+	GetDuePrices(ctx context.Context, now time.Time, limit int) ([]models.CarPrice, error)
+	ApplyPrice(ctx context.Context, price *models.CarPrice) error
 }
@@ -0,0 +1,70 @@
+// Package repositories holds query helpers shared by the entity repositories
+package repositories
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SelectFields is a scope that loads only the columns behind the given JSON
+// fields of the model, e.g., "price_per_day" for PricePerDay. The primary key
+// and the foreign keys of associations are always loaded, so records can
+// still be referenced and their associations preloaded. Fields that aren't
+// columns are ignored; with no fields every column is loaded.
+func SelectFields(fields []string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(fields) == 0 {
+			return db
+		}
+
+		model := db.Statement.Model
+		if model == nil {
+			model = db.Statement.Dest
+		}
+		if err := db.Statement.Parse(model); err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		s := db.Statement.Schema
+
+		var columns []string
+		addColumn := func(column string) {
+			for _, existing := range columns {
+				if existing == column {
+					return
+				}
+			}
+			columns = append(columns, column)
+		}
+
+		for _, field := range s.PrimaryFields {
+			addColumn(field.DBName)
+		}
+		for _, relationship := range s.Relationships.BelongsTo {
+			for _, reference := range relationship.References {
+				if reference.OwnPrimaryKey {
+					continue
+				}
+				addColumn(reference.ForeignKey.DBName)
+			}
+		}
+		for _, field := range s.Fields {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if field.DBName != "" && containsField(fields, name) {
+				addColumn(field.DBName)
+			}
+		}
+
+		return db.Select(columns)
+	}
+}
+
+func containsField(fields []string, name string) bool {
+	for _, field := range fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package pricingrule
+
+import (
+	"context"
+	"time"
+
+	"api-rentcar/models"
+
+	"gorm.io/gorm"
+)
+
+// PricingRuleRepository implements PricingRuleRepositoryInterface
+type PricingRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewPricingRuleRepository creates a new pricing rule repository
+func NewPricingRuleRepository(db *gorm.DB) PricingRuleRepositoryInterface {
+	return &PricingRuleRepository{
+		db: db,
+	}
+}
+
+// Create creates a new pricing rule in the database
+func (r *PricingRuleRepository) Create(ctx context.Context, rule *models.PricingRule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+// GetByID retrieves a pricing rule by its ID
+func (r *PricingRuleRepository) GetByID(ctx context.Context, id uint) (*models.PricingRule, error) {
+	var rule models.PricingRule
+	err := r.db.WithContext(ctx).First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetAll retrieves pricing rules with pagination, highest priority first
+func (r *PricingRuleRepository) GetAll(ctx context.Context, page, limit int, active *bool) ([]models.PricingRule, int64, error) {
+	var rules []models.PricingRule
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.PricingRule{})
+	if active != nil {
+		query = query.Where("is_active = ?", *active)
+	}
+
+	// Count total records with filter applied
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	err := query.Order("priority DESC").Order("id").Offset(offset).Limit(limit).Find(&rules).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return rules, total, nil
+}
+
+// Update saves all fields of an existing pricing rule
+func (r *PricingRuleRepository) Update(ctx context.Context, rule *models.PricingRule) error {
+	return r.db.WithContext(ctx).Model(rule).Select("*").Omit("id", "created_at").Updates(rule).Error
+}
+
+// Delete deletes a pricing rule by its ID
+func (r *PricingRuleRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.PricingRule{}, id).Error
+}
+
+// FindActive retrieves the active pricing rules that may cover cars of a
+// category and brand on some day from from to to, inclusive, highest priority
+// first. Their weekdays aren't checked.
+func (r *PricingRuleRepository) FindActive(ctx context.Context, category models.CarCategory, brand models.Brand, from, to time.Time) ([]models.PricingRule, error) {
+	// DATE columns are stored as midnight in the local time zone, so the days
+	// are compared at that time too
+	from = localDay(from)
+	to = localDay(to)
+
+	var rules []models.PricingRule
+	err := r.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Where("category = '' OR category = ?", category).
+		Where("brand = '' OR brand = ?", brand).
+		Where("start_date IS NULL OR start_date <= ?", to).
+		Where("end_date IS NULL OR end_date >= ?", from).
+		Order("priority DESC").Order("id").
+		Find(&rules).Error
+	return rules, err
+}
+
+// localDay returns midnight in the local time zone of the date of t, in its
+// own location
+func localDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
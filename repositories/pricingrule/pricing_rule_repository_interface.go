@@ -0,0 +1,18 @@
+package pricingrule
+
+import (
+	"context"
+	"time"
+
+	"api-rentcar/models"
+)
+
+// PricingRuleRepositoryInterface defines the contract for pricing rule data operations
+type PricingRuleRepositoryInterface interface {
+	Create(ctx context.Context, rule *models.PricingRule) error
+	GetByID(ctx context.Context, id uint) (*models.PricingRule, error)
+	GetAll(ctx context.Context, page, limit int, active *bool) ([]models.PricingRule, int64, error)
+	Update(ctx context.Context, rule *models.PricingRule) error
+	Delete(ctx context.Context, id uint) error
+	FindActive(ctx context.Context, category models.CarCategory, brand models.Brand, from, to time.Time) ([]models.PricingRule, error)
+}
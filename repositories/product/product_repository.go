@@ -1,10 +1,16 @@
 package product
 
 import (
+	"context"
+	"errors"
+
 	"api-rentcar/models"
 	"gorm.io/gorm"
 )
 
+// ErrVersionConflict is returned when a product changed since it was read
+var ErrVersionConflict = errors.New("product version conflict")
+
 // ProductRepository implements ProductRepositoryInterface
 type ProductRepository struct {
 	db *gorm.DB
@@ -18,14 +24,14 @@ func NewProductRepository(db *gorm.DB) ProductRepositoryInterface {
 }
 
 // Create creates a new product in the database
-func (r *ProductRepository) Create(product *models.Product) error {
-	return r.db.Create(product).Error
+func (r *ProductRepository) Create(ctx context.Context, product *models.Product) error {
+	return r.db.WithContext(ctx).Create(product).Error
 }
 
 // GetByID retrieves a product by its ID
-func (r *ProductRepository) GetByID(id uint) (*models.Product, error) {
+func (r *ProductRepository) GetByID(ctx context.Context, id uint) (*models.Product, error) {
 	var product models.Product
-	err := r.db.First(&product, id).Error
+	err := r.db.WithContext(ctx).First(&product, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -33,12 +39,12 @@ func (r *ProductRepository) GetByID(id uint) (*models.Product, error) {
 }
 
 // GetAll retrieves all products with pagination
-func (r *ProductRepository) GetAll(page, limit int) ([]models.Product, int64, error) {
+func (r *ProductRepository) GetAll(ctx context.Context, page, limit int) ([]models.Product, int64, error) {
 	var products []models.Product
 	var total int64
 
 	// Count total records
-	if err := r.db.Model(&models.Product{}).Count(&total).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&models.Product{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -46,7 +52,7 @@ func (r *ProductRepository) GetAll(page, limit int) ([]models.Product, int64, er
 	offset := (page - 1) * limit
 
 	// Get paginated results
-	err := r.db.Offset(offset).Limit(limit).Find(&products).Error
+	err := r.db.WithContext(ctx).Order("id").Offset(offset).Limit(limit).Find(&products).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -54,26 +60,52 @@ func (r *ProductRepository) GetAll(page, limit int) ([]models.Product, int64, er
 	return products, total, nil
 }
 
-// Update updates an existing product
-func (r *ProductRepository) Update(product *models.Product) error {
-	return r.db.Save(product).Error
+// Update updates an existing product and increments its version. It returns
+// ErrVersionConflict when the product is no longer at the version it was read
+// at.
+func (r *ProductRepository) Update(ctx context.Context, product *models.Product) error {
+	version := product.Version
+	product.Version++
+
+	result := r.db.WithContext(ctx).Model(product).
+		Where("version = ?", version).
+		Select("*").
+		Omit("id", "created_at").
+		Updates(product)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		product.Version = version
+	}
+	return result.Error
 }
 
-// Delete deletes a product by its ID
-func (r *ProductRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Product{}, id).Error
+// Delete deletes a product by its ID. When version isn't zero, the product is
+// only deleted at that version, and ErrVersionConflict is returned otherwise.
+func (r *ProductRepository) Delete(ctx context.Context, id uint, version uint) error {
+	query := r.db.WithContext(ctx)
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+
+	result := query.Delete(&models.Product{}, id)
+	if result.Error == nil && version != 0 && result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return result.Error
 }
 
 // Count returns the total number of products
-func (r *ProductRepository) Count() (int64, error) {
+func (r *ProductRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Product{}).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&models.Product{}).Count(&count).Error
 	return count, err
 }
 
 // ExistsByID checks if a product exists by its ID
-func (r *ProductRepository) ExistsByID(id uint) (bool, error) {
+func (r *ProductRepository) ExistsByID(ctx context.Context, id uint) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.Product{}).Where("id = ?", id).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&models.Product{}).Where("id = ?", id).Count(&count).Error
 	return count > 0, err
 }
@@ -1,16 +1,18 @@
 package product
 
 import (
+	"context"
+
 	"api-rentcar/models"
 )
 
 // ProductRepositoryInterface defines the contract for product data operations
 type ProductRepositoryInterface interface {
-	Create(product *models.Product) error
-	GetByID(id uint) (*models.Product, error)
-	GetAll(page, limit int) ([]models.Product, int64, error)
-	Update(product *models.Product) error
-	Delete(id uint) error
-	Count() (int64, error)
-	ExistsByID(id uint) (bool, error)
+	Create(ctx context.Context, product *models.Product) error
+	GetByID(ctx context.Context, id uint) (*models.Product, error)
+	GetAll(ctx context.Context, page, limit int) ([]models.Product, int64, error)
+	Update(ctx context.Context, product *models.Product) error
+	Delete(ctx context.Context, id uint, version uint) error
+	Count(ctx context.Context) (int64, error)
+	ExistsByID(ctx context.Context, id uint) (bool, error)
 }
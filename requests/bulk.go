@@ -0,0 +1,9 @@
+package requests
+
+// Bulk operation modes
+const (
+	// BulkModeAtomic applies all items or, if any item fails, none of them
+	BulkModeAtomic = "atomic"
+	// BulkModePartial applies the items that succeed even when others fail
+	BulkModePartial = "partial"
+)
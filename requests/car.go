@@ -22,7 +22,7 @@ type CreateCarRequest struct {
 	// Category of the car
 	// @Description Category of the car
 	// @Example "SUV"
-	Category models.CarCategory `json:"category" validate:"required,oneof=CityCar LCGC Compact MPV SUV Crossover" example:"SUV"`
+	Category models.CarCategory `json:"category" validate:"required,oneof='City Car' LCGC Compact MPV SUV Crossover" example:"SUV"`
 
 	// Price Per Day of the car
 	// @Description Price Per Day of the car
@@ -72,7 +72,8 @@ type CreateCarRequest struct {
 	// Availability status of the car
 	// @Description Availability status of the car
 	// @Example true
-	IsAvailable bool `json:"is_available" validate:"required" example:"true"`
+	// A pointer, so that false is told apart from a missing value
+	IsAvailable *bool `json:"is_available" validate:"required" example:"true"`
 
 	// Add other fields as needed
 }
@@ -99,7 +100,7 @@ type UpdateCarRequest struct {
 	// Category of the car
 	// @Description Category of the car
 	// @Example "SUV"
-	Category *models.CarCategory `json:"category,omitempty" validate:"omitempty,oneof=CityCar LCGC Compact MPV SUV Crossover" example:"SUV"`
+	Category *models.CarCategory `json:"category,omitempty" validate:"omitempty,oneof='City Car' LCGC Compact MPV SUV Crossover" example:"SUV"`
 
 	// Price Per Day of the car
 	// @Description Price Per Day of the car
@@ -159,3 +160,127 @@ func (r *UpdateCarRequest) Validate() error {
 	validate := validator.New()
 	return validate.Struct(r)
 }
+
+// BulkCreateCarsRequest represents the request payload for creating several cars
+// @Description Request payload for creating several cars at once
+type BulkCreateCarsRequest struct {
+	// Cars to create, each validated like a single car creation
+	// @Description Cars to create
+	Items []CreateCarRequest `json:"items" validate:"required,min=1,max=100"`
+
+	// Bulk mode
+	// @Description "atomic" (default) creates all cars or none, "partial" creates the valid ones
+	// @Example "atomic"
+	Mode string `json:"mode,omitempty" validate:"omitempty,oneof=atomic partial" example:"atomic"`
+}
+
+// BulkUpdateCarItem represents one car of a bulk update
+// @Description Car update with the ID of the car to update
+type BulkUpdateCarItem struct {
+	// ID of the car to update
+	// @Description ID of the car to update
+	// @Example 1
+	ID uint `json:"id" validate:"required" example:"1"`
+
+	UpdateCarRequest
+}
+
+// BulkUpdateCarsRequest represents the request payload for updating several cars
+// @Description Request payload for updating several cars at once
+type BulkUpdateCarsRequest struct {
+	// Car updates, each validated like a single car update
+	// @Description Car updates
+	Items []BulkUpdateCarItem `json:"items" validate:"required,min=1,max=100"`
+
+	// Bulk mode
+	// @Description "atomic" (default) updates all cars or none, "partial" updates the ones that succeed
+	// @Example "atomic"
+	Mode string `json:"mode,omitempty" validate:"omitempty,oneof=atomic partial" example:"atomic"`
+}
+
+// BulkDeleteCarsRequest represents the request payload for deleting several cars
+// @Description Request payload for deleting several cars at once
+type BulkDeleteCarsRequest struct {
+	// IDs of the cars to delete
+	// @Description IDs of the cars to delete
+	// @Example [1, 2, 3]
+	IDs []uint `json:"ids" validate:"required,min=1,max=100" example:"1,2,3"`
+
+	// Bulk mode
+	// @Description "atomic" (default) deletes all cars or none, "partial" deletes the ones that exist
+	// @Example "atomic"
+	Mode string `json:"mode,omitempty" validate:"omitempty,oneof=atomic partial" example:"atomic"`
+}
+
+// PatchCarRequest documents the JSON Merge Patch of a car: fields present
+// replace the current values, null clears them. Patches are applied to the
+// current car and the result validated as a CreateCarRequest.
+// @Description JSON Merge Patch (RFC 7396) of a car; null clears a field
+type PatchCarRequest struct {
+	// Name of the car
+	// @Description Name of the car
+	// @Example "Updated Car"
+	Name *string `json:"name,omitempty" extensions:"x-nullable" example:"Updated Car"`
+
+	// Description of the car
+	// @Description Description of the car
+	// @Example "This is an updated car description"
+	Description *string `json:"description,omitempty" extensions:"x-nullable" example:"This is an updated car description"`
+
+	// Category of the car
+	// @Description Category of the car
+	// @Example "SUV"
+	Category *models.CarCategory `json:"category,omitempty" extensions:"x-nullable" example:"SUV"`
+
+	// Price Per Day of the car
+	// @Description Price Per Day of the car
+	// @Example 10000
+	PricePerDay *float64 `json:"price_per_day,omitempty" extensions:"x-nullable" example:"10000"`
+
+	// Price Per Week of the car
+	// @Description Price Per Week of the car
+	// @Example 7000
+	PricePerWeek *float64 `json:"price_per_week,omitempty" extensions:"x-nullable" example:"7000"`
+
+	// Price Per Month of the car
+	// @Description Price Per Month of the car
+	// @Example 40000
+	PricePerMonth *float64 `json:"price_per_month,omitempty" extensions:"x-nullable" example:"40000"`
+
+	// Brand of the car
+	// @Description Brand of the car
+	// @Example "Toyota"
+	Brand *models.Brand `json:"brand,omitempty" extensions:"x-nullable" example:"Toyota"`
+
+	// Model of the car
+	// @Description Model of the car
+	// @Example "Sample Model"
+	Model *string `json:"model,omitempty" extensions:"x-nullable" example:"Sample Model"`
+
+	// Transmission type of the car
+	// @Description Transmission type of the car
+	// @Example "Automatic"
+	Transmission *models.TransmissionType `json:"transmission,omitempty" extensions:"x-nullable" example:"Automatic"`
+
+	// Year of the car
+	// @Description Year of the car
+	// @Example 2023
+	Year *int `json:"year,omitempty" extensions:"x-nullable" example:"2023"`
+
+	// License plate of the car
+	// @Description License plate of the car
+	// @Example "ABC123"
+	LicensePlate *string `json:"license_plate,omitempty" extensions:"x-nullable" example:"ABC123"`
+
+	// Machine number of the car
+	// @Description Machine number of the car
+	// @Example "123456"
+	MachineNumber *string `json:"machine_number,omitempty" extensions:"x-nullable" example:"123456"`
+
+	// Availability status of the car
+	// @Description Availability status of the car
+	// @Example true
+	IsAvailable *bool `json:"is_available,omitempty" extensions:"x-nullable" example:"true"`
+
+	// Add other fields as needed
+}
@@ -0,0 +1,28 @@
+package requests
+
+import "time"
+
+// ScheduleCarPriceRequest represents the request payload for scheduling a
+// change of a car's prices
+// @Description Request payload for scheduling a change of a car's prices
+type ScheduleCarPriceRequest struct {
+	// Price Per Day of the car
+	// @Description Price Per Day of the car from effective_from
+	// @Example 12000
+	PricePerDay float64 `json:"price_per_day" validate:"required,gt=0" example:"12000"`
+
+	// Price Per Week of the car
+	// @Description Price Per Week of the car from effective_from
+	// @Example 75000
+	PricePerWeek float64 `json:"price_per_week" validate:"required,gt=0" example:"75000"`
+
+	// Price Per Month of the car
+	// @Description Price Per Month of the car from effective_from
+	// @Example 280000
+	PricePerMonth float64 `json:"price_per_month" validate:"required,gt=0" example:"280000"`
+
+	// Time the prices take effect
+	// @Description Time the prices take effect, in the future
+	// @Example "2024-04-01T00:00:00+07:00"
+	EffectiveFrom time.Time `json:"effective_from" validate:"required" example:"2024-04-01T00:00:00+07:00"`
+}
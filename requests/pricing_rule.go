@@ -0,0 +1,58 @@
+package requests
+
+import "api-rentcar/models"
+
+// CreatePricingRuleRequest represents the request payload for creating or
+// replacing a pricing rule
+// @Description Request payload for creating or replacing a pricing rule
+type CreatePricingRuleRequest struct {
+	// Name of the rule
+	// @Description Name of the rule
+	// @Example "Lebaran surcharge"
+	Name string `json:"name" validate:"required,min=3,max=100" example:"Lebaran surcharge"`
+
+	// First day the rule covers
+	// @Description First day the rule covers (YYYY-MM-DD), omitted for no bound
+	// @Example "2024-04-05"
+	StartDate string `json:"start_date,omitempty" validate:"omitempty,datetime=2006-01-02" example:"2024-04-05"`
+
+	// Last day the rule covers
+	// @Description Last day the rule covers (YYYY-MM-DD), inclusive, omitted for no bound
+	// @Example "2024-04-15"
+	EndDate string `json:"end_date,omitempty" validate:"omitempty,datetime=2006-01-02" example:"2024-04-15"`
+
+	// Weekdays the rule covers
+	// @Description Weekdays the rule covers, omitted or empty for every day
+	// @Example ["saturday","sunday"]
+	Weekdays []string `json:"weekdays,omitempty" validate:"omitempty,max=7,dive,oneof=monday tuesday wednesday thursday friday saturday sunday" example:"saturday,sunday"`
+
+	// Category of the cars the rule covers
+	// @Description Category of the cars the rule covers, omitted for all
+	// @Example "SUV"
+	Category models.CarCategory `json:"category,omitempty" validate:"omitempty,oneof='City Car' LCGC Compact MPV SUV Crossover" example:"SUV"`
+
+	// Brand of the cars the rule covers
+	// @Description Brand of the cars the rule covers, omitted for all
+	// @Example "Toyota"
+	Brand models.Brand `json:"brand,omitempty" validate:"omitempty,oneof=Toyota Honda Mercedes Wuling Mitsubishi Volkswagen Jeep Subaru Hyundai Kia Renault Volvo Chevrolet Ford BMW" example:"Toyota"`
+
+	// How the daily price is adjusted
+	// @Description percent to change the daily price by amount percent, fixed to change it by amount
+	// @Example "percent"
+	AdjustmentType string `json:"adjustment_type" validate:"required,oneof=percent fixed" example:"percent"`
+
+	// Adjustment
+	// @Description Adjustment, negative for discounts; percentages can't be below -100
+	// @Example 25
+	Amount float64 `json:"amount" validate:"required" example:"25"`
+
+	// Priority among rules covering the same day
+	// @Description Rules with a higher priority win over others covering the same day
+	// @Example 10
+	Priority int `json:"priority" example:"10"`
+
+	// Whether the rule is applied
+	// @Description Whether the rule is applied, true when omitted
+	// @Example true
+	IsActive *bool `json:"is_active,omitempty" example:"true"`
+}
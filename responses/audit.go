@@ -0,0 +1,112 @@
+package responses
+
+import (
+	"encoding/json"
+	"time"
+
+	"api-rentcar/models"
+)
+
+// AuditLogResponse represents an audit log entry
+// @Description Audit log entry
+type AuditLogResponse struct {
+	// Primary key
+	// @Description Unique identifier
+	// @Example 1
+	ID uint `json:"id" example:"1"`
+
+	// Who made the change
+	// @Description Who made the change: admin, bearer:<token hash prefix> or anonymous
+	// @Example "admin"
+	Actor string `json:"actor" example:"admin"`
+
+	// Action of the change
+	// @Description create, update or delete
+	// @Example "update"
+	Action string `json:"action" example:"update" enums:"create,update,delete"`
+
+	// Type of the changed entity
+	// @Description Type of the changed entity
+	// @Example "car"
+	EntityType string `json:"entity_type" example:"car"`
+
+	// ID of the changed entity
+	// @Description ID of the changed entity
+	// @Example "1"
+	EntityID string `json:"entity_id" example:"1"`
+
+	// Changed fields
+	// @Description Changed fields by name, each with its value before and after the change
+	Changes map[string]AuditFieldChange `json:"changes"`
+
+	// Request that made the change
+	// @Description ID of the request that made the change
+	// @Example "3f8c2a9e-6b1d-4c7a-9f2e-1a2b3c4d5e6f"
+	RequestID string `json:"request_id" example:"3f8c2a9e-6b1d-4c7a-9f2e-1a2b3c4d5e6f"`
+
+	// IP of the request
+	// @Description IP the request came from
+	// @Example "203.0.113.7"
+	IP string `json:"ip" example:"203.0.113.7"`
+
+	// Time of the change
+	// @Description Time of the change
+	// @Example "2023-01-01T00:00:00Z"
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// AuditFieldChange is the value of a field before and after a change; it has
+// no before when the entity was created, and no after when it was deleted
+// @Description Value of a field before and after a change
+type AuditFieldChange struct {
+	// Value before the change
+	// @Description Value before the change, of the field's type
+	Before any `json:"before,omitempty"`
+
+	// Value after the change
+	// @Description Value after the change, of the field's type
+	After any `json:"after,omitempty"`
+}
+
+// ToAuditLogResponse converts an AuditLog model to AuditLogResponse
+func ToAuditLogResponse(entry *models.AuditLog) AuditLogResponse {
+	// Values are kept as JSON, so a change from or to null isn't dropped
+	var stored map[string]struct {
+		Before json.RawMessage `json:"before"`
+		After  json.RawMessage `json:"after"`
+	}
+	_ = json.Unmarshal([]byte(entry.Changes), &stored)
+
+	changes := make(map[string]AuditFieldChange, len(stored))
+	for name, change := range stored {
+		var fieldChange AuditFieldChange
+		if len(change.Before) > 0 {
+			fieldChange.Before = change.Before
+		}
+		if len(change.After) > 0 {
+			fieldChange.After = change.After
+		}
+		changes[name] = fieldChange
+	}
+
+	return AuditLogResponse{
+		ID:         entry.ID,
+		Actor:      entry.Actor,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Changes:    changes,
+		RequestID:  entry.RequestID,
+		IP:         entry.IP,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
+
+// ToAuditLogResponses converts AuditLog models to AuditLogResponses
+func ToAuditLogResponses(entries []models.AuditLog) []AuditLogResponse {
+	items := make([]AuditLogResponse, len(entries))
+	for i := range entries {
+		items[i] = ToAuditLogResponse(&entries[i])
+	}
+	return items
+}
@@ -3,6 +3,7 @@ package responses
 import (
 	"api-rentcar/models"
 	"api-rentcar/utils"
+	"math"
 	"time"
 )
 
@@ -26,8 +27,8 @@ type CarResponse struct {
 
 	// Category of the car
 	// @Description Category of the car
-	// @Example "CityCar"
-	Category models.CarCategory `json:"category" example:"CityCar"`
+	// @Example "City Car"
+	Category models.CarCategory `json:"category" example:"City Car"`
 
 	// Price per day
 	// @Description Price per day in IDR
@@ -79,6 +80,11 @@ type CarResponse struct {
 	// @Example true
 	IsAvailable bool `json:"is_available" example:"true"`
 
+	// Version
+	// @Description Version of the car, also sent as its ETag
+	// @Example 1
+	Version uint `json:"version" example:"1"`
+
 	// Creation timestamp
 	// @Description Creation timestamp
 	// @Example "2023-01-01T00:00:00Z"
@@ -102,7 +108,199 @@ type CarsListResponse struct {
 	Pagination utils.PaginationMeta `json:"pagination"`
 }
 
+// CarStatsResponse represents fleet statistics
+// @Description Fleet statistics aggregated over all cars
+type CarStatsResponse struct {
+	// Total number of cars
+	// @Description Total number of cars
+	// @Example 12
+	TotalCars int64 `json:"total_cars" example:"12"`
+
+	// Number of cars available for rent
+	// @Description Number of cars available for rent
+	// @Example 9
+	AvailableCars int64 `json:"available_cars" example:"9"`
+
+	// Car counts by brand
+	// @Description Number of cars per brand
+	ByBrand map[string]int64 `json:"by_brand"`
+
+	// Car counts by category
+	// @Description Number of cars per category
+	ByCategory map[string]int64 `json:"by_category"`
+
+	// Car counts by transmission
+	// @Description Number of cars per transmission type
+	ByTransmission map[string]int64 `json:"by_transmission"`
+
+	// Car counts by availability
+	// @Description Number of available and unavailable cars
+	ByAvailability AvailabilityStats `json:"by_availability"`
+
+	// Daily price statistics by category
+	// @Description Average, minimum and maximum daily price per category
+	PriceByCategory []CategoryPriceStatsResponse `json:"price_by_category"`
+
+	// Car counts by year
+	// @Description Number of cars per production year, oldest first
+	ByYear []YearCountResponse `json:"by_year"`
+}
+
+// AvailabilityStats represents car counts by availability
+// @Description Car counts by availability
+type AvailabilityStats struct {
+	Available   int64 `json:"available" example:"9"`
+	Unavailable int64 `json:"unavailable" example:"3"`
+}
+
+// CategoryPriceStatsResponse represents the daily prices of a category
+// @Description Daily price statistics of a category
+type CategoryPriceStatsResponse struct {
+	Category models.CarCategory `json:"category" example:"SUV"`
+	Count    int64              `json:"count" example:"4"`
+	AvgPrice float64            `json:"avg_price" example:"450000"`
+	MinPrice float64            `json:"min_price" example:"350000"`
+	MaxPrice float64            `json:"max_price" example:"600000"`
+}
+
+// YearCountResponse represents the number of cars built in a year
+// @Description Number of cars built in a year
+type YearCountResponse struct {
+	Year  int   `json:"year" example:"2022"`
+	Count int64 `json:"count" example:"5"`
+}
+
+// Bulk item statuses
+const (
+	BulkStatusCreated = "created"
+	BulkStatusUpdated = "updated"
+	BulkStatusDeleted = "deleted"
+	BulkStatusFailed  = "failed"
+)
+
+// BulkCarsResponse represents the outcome of a bulk operation
+// @Description Outcome of a bulk car operation, item by item
+type BulkCarsResponse struct {
+	// Bulk mode
+	// @Description Bulk mode the request ran in
+	// @Example "atomic"
+	Mode string `json:"mode" example:"atomic"`
+
+	// Number of items applied
+	// @Description Number of items applied
+	// @Example 2
+	Succeeded int `json:"succeeded" example:"2"`
+
+	// Number of items that failed
+	// @Description Number of items that failed
+	// @Example 0
+	Failed int `json:"failed" example:"0"`
+
+	// Outcome of every item, in request order
+	// @Description Outcome of every item, in request order
+	Results []BulkCarItemResponse `json:"results"`
+}
+
+// BulkCarItemResponse represents the outcome of one item of a bulk operation
+// @Description Outcome of one item of a bulk car operation
+type BulkCarItemResponse struct {
+	// Index of the item in the request
+	// @Description Index of the item in the request
+	// @Example 0
+	Index int `json:"index" example:"0"`
+
+	// ID of the car
+	// @Description ID of the car, when known
+	// @Example 1
+	ID uint `json:"id,omitempty" example:"1"`
+
+	// Item status
+	// @Description created, updated or deleted when applied; failed when the item has errors
+	// @Example "created"
+	Status string `json:"status" example:"created"`
+
+	// Car after the operation
+	// @Description Created or updated car
+	Data *CarResponse `json:"data,omitempty"`
+
+	// Errors of the item
+	// @Description Why the item failed
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ImportJobResponse represents a fleet import job
+// @Description Fleet import job and its per-row error report
+type ImportJobResponse struct {
+	// Job ID
+	// @Description ID to poll the job with
+	// @Example "3f8c2a9e-6b1d-4c7a-9f2e-1a2b3c4d5e6f"
+	ID string `json:"id" example:"3f8c2a9e-6b1d-4c7a-9f2e-1a2b3c4d5e6f"`
+
+	// Job status
+	// @Description pending, running, completed or failed
+	// @Example "completed"
+	Status string `json:"status" example:"completed"`
+
+	// Dry run
+	// @Description Whether the import only validated the file
+	// @Example false
+	DryRun bool `json:"dry_run" example:"false"`
+
+	// Column mapping
+	// @Description Column header read for every car field
+	Mapping map[string]string `json:"mapping"`
+
+	// @Description Number of data rows in the file
+	// @Example 120
+	TotalRows int `json:"total_rows" example:"120"`
+
+	// @Description Number of rows processed so far
+	// @Example 120
+	ProcessedRows int `json:"processed_rows" example:"120"`
+
+	// @Description Percentage of rows processed
+	// @Example 100
+	Progress int `json:"progress" example:"100"`
+
+	// @Description Cars created, or that would be created in a dry run
+	// @Example 100
+	Created int `json:"created" example:"100"`
 
+	// @Description Cars updated by license plate, or that would be updated in a dry run
+	// @Example 18
+	Updated int `json:"updated" example:"18"`
+
+	// @Description Rows that weren't imported
+	// @Example 2
+	Failed int `json:"failed" example:"2"`
+
+	// @Description Why each failed row wasn't imported
+	RowErrors []ImportRowErrorResponse `json:"row_errors"`
+
+	// @Description Why the job stopped, when it failed
+	Error string `json:"error,omitempty" example:""`
+
+	// @Example "2023-01-01T00:00:00Z"
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+
+	// @Example "2023-01-01T00:00:05Z"
+	FinishedAt *time.Time `json:"finished_at,omitempty" example:"2023-01-01T00:00:05Z"`
+}
+
+// ImportRowErrorResponse represents the errors of a spreadsheet row
+// @Description Errors of a spreadsheet row
+type ImportRowErrorResponse struct {
+	// @Description Row number in the file, the header being row 1
+	// @Example 7
+	Row int `json:"row" example:"7"`
+
+	// @Description License plate of the row, when present
+	// @Example "B 1234 ABC"
+	LicensePlate string `json:"license_plate,omitempty" example:"B 1234 ABC"`
+
+	// @Description Validation or database errors of the row
+	Errors []string `json:"errors"`
+}
 
 // ToCarResponse converts a Car model to CarResponse
 func ToCarResponse(car *models.Car) CarResponse {
@@ -121,6 +319,7 @@ func ToCarResponse(car *models.Car) CarResponse {
 		LicensePlate:  car.LicensePlate,
 		MachineNumber: car.MachineNumber,
 		IsAvailable:   car.IsAvailable,
+		Version:       car.Version,
 		CreatedAt:     car.CreatedAt,
 		UpdatedAt:     car.UpdatedAt,
 	}
@@ -134,7 +333,113 @@ func ToCarsListResponse(cars []models.Car, total int64, page, limit int) CarsLis
 	}
 
 	return CarsListResponse{
-		Data: carResponses,
+		Data:       carResponses,
 		Pagination: utils.CreatePaginationMeta(total, page, limit),
 	}
 }
+
+// ToCarStatsResponse converts fleet statistics to CarStatsResponse
+func ToCarStatsResponse(stats *models.CarStats) CarStatsResponse {
+	response := CarStatsResponse{
+		TotalCars:     stats.Total,
+		AvailableCars: stats.Available,
+		ByAvailability: AvailabilityStats{
+			Available:   stats.Available,
+			Unavailable: stats.Total - stats.Available,
+		},
+		ByBrand:         groupCounts(stats.ByBrand),
+		ByCategory:      groupCounts(stats.ByCategory),
+		ByTransmission:  groupCounts(stats.ByTransmission),
+		PriceByCategory: make([]CategoryPriceStatsResponse, len(stats.PriceByCategory)),
+		ByYear:          make([]YearCountResponse, len(stats.ByYear)),
+	}
+
+	for i, price := range stats.PriceByCategory {
+		response.PriceByCategory[i] = CategoryPriceStatsResponse{
+			Category: price.Category,
+			Count:    price.Count,
+			// AVG of a decimal column isn't rounded to cents by every database
+			AvgPrice: math.Round(price.AvgPrice*100) / 100,
+			MinPrice: price.MinPrice,
+			MaxPrice: price.MaxPrice,
+		}
+	}
+
+	for i, year := range stats.ByYear {
+		response.ByYear[i] = YearCountResponse{Year: year.Year, Count: year.Count}
+	}
+
+	return response
+}
+
+// groupCounts converts grouped counts to a map keyed by value
+func groupCounts(counts []models.GroupCount) map[string]int64 {
+	result := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		result[count.Value] = count.Count
+	}
+	return result
+}
+
+// ToBulkCarsResponse converts the results of an applied bulk request to
+// BulkCarsResponse. status is given to the items without errors: created,
+// updated or deleted.
+func ToBulkCarsResponse(results []models.BulkCarResult, mode, status string) BulkCarsResponse {
+	response := BulkCarsResponse{
+		Mode:    mode,
+		Results: make([]BulkCarItemResponse, len(results)),
+	}
+
+	for i, result := range results {
+		item := BulkCarItemResponse{Index: result.Index, ID: result.ID}
+		switch {
+		case len(result.Errors) > 0:
+			item.Status = BulkStatusFailed
+			item.Errors = result.Errors
+			response.Failed++
+		default:
+			item.Status = status
+			if result.Car != nil {
+				data := ToCarResponse(result.Car)
+				item.Data = &data
+			}
+			response.Succeeded++
+		}
+		response.Results[i] = item
+	}
+
+	return response
+}
+
+// ToImportJobResponse converts an ImportJob to ImportJobResponse
+func ToImportJobResponse(job *models.ImportJob) ImportJobResponse {
+	response := ImportJobResponse{
+		ID:            job.ID,
+		Status:        job.Status,
+		DryRun:        job.DryRun,
+		Mapping:       job.Mapping,
+		TotalRows:     job.TotalRows,
+		ProcessedRows: job.ProcessedRows,
+		Progress:      100,
+		Created:       job.Created,
+		Updated:       job.Updated,
+		Failed:        job.Failed,
+		RowErrors:     make([]ImportRowErrorResponse, len(job.RowErrors)),
+		Error:         job.Error,
+		CreatedAt:     job.CreatedAt,
+		FinishedAt:    job.FinishedAt,
+	}
+	if job.TotalRows > 0 {
+		response.Progress = job.ProcessedRows * 100 / job.TotalRows
+	}
+
+	for i, rowError := range job.RowErrors {
+		response.RowErrors[i] = ImportRowErrorResponse{
+			Row:          rowError.Row,
+			LicensePlate: rowError.LicensePlate,
+			Errors:       rowError.Errors,
+		}
+	}
+
+	return response
+}
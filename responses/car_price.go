@@ -0,0 +1,80 @@
+package responses
+
+import (
+	"time"
+
+	"api-rentcar/models"
+)
+
+// CarPriceResponse represents the prices of a car from a point in time
+// @Description Car price tiers effective from a point in time
+type CarPriceResponse struct {
+	// Primary key
+	// @Description Unique identifier
+	// @Example 1
+	ID uint `json:"id" example:"1"`
+
+	// Car the prices are for
+	// @Description ID of the car
+	// @Example 1
+	CarID uint `json:"car_id" example:"1"`
+
+	// Price per day
+	// @Description Price per day in IDR
+	// @Example 300000
+	PricePerDay float64 `json:"price_per_day" example:"300000"`
+
+	// Price per week
+	// @Description Price per week in IDR
+	// @Example 1800000
+	PricePerWeek float64 `json:"price_per_week" example:"1800000"`
+
+	// Price per month
+	// @Description Price per month in IDR
+	// @Example 7000000
+	PricePerMonth float64 `json:"price_per_month" example:"7000000"`
+
+	// Time the prices take effect
+	// @Description Time the prices take effect
+	// @Example "2023-01-01T00:00:00Z"
+	EffectiveFrom time.Time `json:"effective_from" example:"2023-01-01T00:00:00Z"`
+
+	// Status of the prices
+	// @Description applied, or scheduled until they take effect
+	// @Example "applied"
+	Status string `json:"status" example:"applied" enums:"applied,scheduled"`
+
+	// Time the prices were applied
+	// @Description Time the prices were applied to the car, absent while scheduled
+	// @Example "2023-01-01T00:00:00Z"
+	AppliedAt *time.Time `json:"applied_at,omitempty" example:"2023-01-01T00:00:00Z"`
+
+	// Creation timestamp
+	// @Description Creation timestamp
+	// @Example "2023-01-01T00:00:00Z"
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// ToCarPriceResponse converts a CarPrice model to CarPriceResponse
+func ToCarPriceResponse(price *models.CarPrice) CarPriceResponse {
+	return CarPriceResponse{
+		ID:            price.ID,
+		CarID:         price.CarID,
+		PricePerDay:   price.PricePerDay,
+		PricePerWeek:  price.PricePerWeek,
+		PricePerMonth: price.PricePerMonth,
+		EffectiveFrom: price.EffectiveFrom,
+		Status:        price.Status(),
+		AppliedAt:     price.AppliedAt,
+		CreatedAt:     price.CreatedAt,
+	}
+}
+
+// ToCarPriceResponses converts CarPrice models to CarPriceResponses
+func ToCarPriceResponses(prices []models.CarPrice) []CarPriceResponse {
+	items := make([]CarPriceResponse, len(prices))
+	for i := range prices {
+		items[i] = ToCarPriceResponse(&prices[i])
+	}
+	return items
+}
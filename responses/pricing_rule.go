@@ -0,0 +1,234 @@
+package responses
+
+import (
+	"time"
+
+	"api-rentcar/models"
+)
+
+// PricingRuleResponse represents a single pricing rule response
+// @Description Pricing rule response structure
+type PricingRuleResponse struct {
+	// Primary key
+	// @Description Unique identifier
+	// @Example 1
+	ID uint `json:"id" example:"1"`
+
+	// Name of the rule
+	// @Description Name of the rule
+	// @Example "Lebaran surcharge"
+	Name string `json:"name" example:"Lebaran surcharge"`
+
+	// First day the rule covers
+	// @Description First day the rule covers (YYYY-MM-DD), absent for no bound
+	// @Example "2024-04-05"
+	StartDate string `json:"start_date,omitempty" example:"2024-04-05"`
+
+	// Last day the rule covers
+	// @Description Last day the rule covers (YYYY-MM-DD), inclusive, absent for no bound
+	// @Example "2024-04-15"
+	EndDate string `json:"end_date,omitempty" example:"2024-04-15"`
+
+	// Weekdays the rule covers
+	// @Description Weekdays the rule covers, empty for every day
+	// @Example ["saturday","sunday"]
+	Weekdays []string `json:"weekdays" example:"saturday,sunday"`
+
+	// Category of the cars the rule covers
+	// @Description Category of the cars the rule covers, absent for all
+	// @Example "SUV"
+	Category string `json:"category,omitempty" example:"SUV"`
+
+	// Brand of the cars the rule covers
+	// @Description Brand of the cars the rule covers, absent for all
+	// @Example "Toyota"
+	Brand string `json:"brand,omitempty" example:"Toyota"`
+
+	// How the daily price is adjusted
+	// @Description percent or fixed
+	// @Example "percent"
+	AdjustmentType string `json:"adjustment_type" example:"percent" enums:"percent,fixed"`
+
+	// Adjustment
+	// @Description Adjustment, negative for discounts
+	// @Example 25
+	Amount float64 `json:"amount" example:"25"`
+
+	// Priority among rules covering the same day
+	// @Description Rules with a higher priority win over others covering the same day
+	// @Example 10
+	Priority int `json:"priority" example:"10"`
+
+	// Whether the rule is applied
+	// @Description Whether the rule is applied
+	// @Example true
+	IsActive bool `json:"is_active" example:"true"`
+
+	// Creation timestamp
+	// @Description Creation timestamp
+	// @Example "2023-01-01T00:00:00Z"
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+
+	// Last update timestamp
+	// @Description Last update timestamp
+	// @Example "2023-01-01T00:00:00Z"
+	UpdatedAt time.Time `json:"updated_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// PriceQuoteResponse represents the price of renting a car for a period
+// @Description Price of renting a car for a period, day by day
+type PriceQuoteResponse struct {
+	// Car the price is for
+	// @Description ID of the car
+	// @Example 1
+	CarID uint `json:"car_id" example:"1"`
+
+	// Pickup day
+	// @Description Pickup day (YYYY-MM-DD)
+	// @Example "2024-04-08"
+	StartDate string `json:"start_date" example:"2024-04-08"`
+
+	// Return day
+	// @Description Return day (YYYY-MM-DD)
+	// @Example "2024-04-15"
+	EndDate string `json:"end_date" example:"2024-04-15"`
+
+	// Number of days
+	// @Description Number of days rented
+	// @Example 7
+	Days int `json:"days" example:"7"`
+
+	// Tier breakdown
+	// @Description Days priced as 30-day months, 7-day weeks and single days
+	Tiers PriceQuoteTiers `json:"tiers"`
+
+	// Price without pricing rules
+	// @Description Price with the car's tiers alone, in IDR
+	// @Example 1800000
+	BasePrice float64 `json:"base_price" example:"1800000"`
+
+	// Price with pricing rules
+	// @Description Price with the pricing rules applied, in IDR
+	// @Example 2057142.86
+	Total float64 `json:"total" example:"2057142.86"`
+
+	// Price of each day
+	// @Description Price of each day and the rule applied to it
+	Breakdown []PriceQuoteDayResponse `json:"breakdown"`
+}
+
+// PriceQuoteTiers is how the days of a quote are priced with the car's tiers
+// @Description Days priced as 30-day months, 7-day weeks and single days
+type PriceQuoteTiers struct {
+	// Number of 30-day months
+	// @Description Number of 30-day months, at the monthly price
+	// @Example 0
+	Months int `json:"months" example:"0"`
+
+	// Number of 7-day weeks
+	// @Description Number of 7-day weeks, at the weekly price
+	// @Example 1
+	Weeks int `json:"weeks" example:"1"`
+
+	// Number of remaining days
+	// @Description Number of remaining days, at the daily price
+	// @Example 0
+	Days int `json:"days" example:"0"`
+}
+
+// PriceQuoteDayResponse represents the price of one day of a quote
+// @Description Price of one day of a rental
+type PriceQuoteDayResponse struct {
+	// Day
+	// @Description Day (YYYY-MM-DD)
+	// @Example "2024-04-08"
+	Date string `json:"date" example:"2024-04-08"`
+
+	// Day's share of the tiered price
+	// @Description Day's share of the tiered price, in IDR
+	// @Example 257142.86
+	BasePrice float64 `json:"base_price" example:"257142.86"`
+
+	// Price of the day
+	// @Description Price of the day with the pricing rule applied, in IDR
+	// @Example 321428.57
+	Price float64 `json:"price" example:"321428.57"`
+
+	// Rule applied to the day
+	// @Description ID of the pricing rule applied to the day, absent when none covers it
+	// @Example 1
+	RuleID *uint `json:"rule_id,omitempty" example:"1"`
+
+	// Name of the rule applied to the day
+	// @Description Name of the pricing rule applied to the day
+	// @Example "Lebaran surcharge"
+	RuleName string `json:"rule_name,omitempty" example:"Lebaran surcharge"`
+}
+
+// ToPricingRuleResponse converts a PricingRule model to PricingRuleResponse
+func ToPricingRuleResponse(rule *models.PricingRule) PricingRuleResponse {
+	return PricingRuleResponse{
+		ID:             rule.ID,
+		Name:           rule.Name,
+		StartDate:      formatDate(rule.StartDate),
+		EndDate:        formatDate(rule.EndDate),
+		Weekdays:       rule.WeekdayList(),
+		Category:       string(rule.Category),
+		Brand:          string(rule.Brand),
+		AdjustmentType: rule.AdjustmentType,
+		Amount:         rule.Amount,
+		Priority:       rule.Priority,
+		IsActive:       rule.IsActive,
+		CreatedAt:      rule.CreatedAt,
+		UpdatedAt:      rule.UpdatedAt,
+	}
+}
+
+// ToPricingRuleResponses converts PricingRule models to PricingRuleResponses
+func ToPricingRuleResponses(rules []models.PricingRule) []PricingRuleResponse {
+	items := make([]PricingRuleResponse, len(rules))
+	for i := range rules {
+		items[i] = ToPricingRuleResponse(&rules[i])
+	}
+	return items
+}
+
+// ToPriceQuoteResponse converts a PriceQuote to PriceQuoteResponse
+func ToPriceQuoteResponse(quote *models.PriceQuote) PriceQuoteResponse {
+	response := PriceQuoteResponse{
+		CarID:     quote.CarID,
+		StartDate: quote.StartDate.Format(time.DateOnly),
+		EndDate:   quote.EndDate.Format(time.DateOnly),
+		Days:      quote.Days,
+		Tiers: PriceQuoteTiers{
+			Months: quote.Months,
+			Weeks:  quote.Weeks,
+			Days:   quote.ExtraDays,
+		},
+		BasePrice: quote.BasePrice,
+		Total:     quote.Total,
+		Breakdown: make([]PriceQuoteDayResponse, len(quote.Lines)),
+	}
+	for i, line := range quote.Lines {
+		day := PriceQuoteDayResponse{
+			Date:      line.Date.Format(time.DateOnly),
+			BasePrice: line.BasePrice,
+			Price:     line.Price,
+		}
+		if line.Rule != nil {
+			day.RuleID = &line.Rule.ID
+			day.RuleName = line.Rule.Name
+		}
+		response.Breakdown[i] = day
+	}
+	return response
+}
+
+// formatDate formats an optional date as YYYY-MM-DD in its own location,
+// empty when nil
+func formatDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format(time.DateOnly)
+}
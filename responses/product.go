@@ -24,6 +24,11 @@ type ProductResponse struct {
 	// @Example "This is a sample product description"
 	Description string `json:"description" example:"This is a sample product description"`
 
+	// Version
+	// @Description Version of the product, also sent as its ETag
+	// @Example 1
+	Version uint `json:"version" example:"1"`
+
 	// Creation timestamp
 	// @Description Creation timestamp
 	// @Example "2023-01-01T00:00:00Z"
@@ -55,6 +60,7 @@ func ToProductResponse(product *models.Product) ProductResponse {
 		ID:          product.ID,
 		Name:        product.Name,
 		Description: product.Description,
+		Version:     product.Version,
 		CreatedAt:   product.CreatedAt,
 		UpdatedAt:   product.UpdatedAt,
 		// Add other field mappings as needed
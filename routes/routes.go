@@ -1,11 +1,22 @@
 package routes
 
 import (
+	"context"
+	"errors"
+	"net/http"
+
+	"api-rentcar/cache"
+	"api-rentcar/config"
 	"api-rentcar/controllers"
+	"api-rentcar/docs"
+	"api-rentcar/metrics"
 	"api-rentcar/middleware"
+	"api-rentcar/repositories/audit"
 	"api-rentcar/repositories/car"
+	"api-rentcar/repositories/pricingrule"
 	"api-rentcar/repositories/product"
 	"api-rentcar/services"
+	"api-rentcar/utils"
 
 	"gorm.io/gorm"
 
@@ -14,23 +25,54 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-// SetupRoutes configures all application routes
-func SetupRoutes(router *gin.Engine, db *gorm.DB) {
+// SetupRoutes configures all application routes. Car and product reads are
+// cached in appCache.
+func SetupRoutes(router *gin.Engine, db *gorm.DB, appCache cache.Cache) {
 	// Initialize repository
 	productRepo := product.NewProductRepository(db)
 	carRepo := car.NewCarRepository(db)
+	auditRepo := audit.NewAuditRepository(db)
+	pricingRuleRepo := pricingrule.NewPricingRuleRepository(db)
+
+	// Initialize caches
+	productCache := cache.NewNamespace(appCache, "products", config.AppConfig.CacheTTL)
+	carCache := cache.NewNamespace(appCache, "cars", config.AppConfig.CacheTTL)
 
 	// Initialize service
-	productService := services.NewProductService(productRepo)
-	carService := services.NewCarService(carRepo)
+	productService := services.NewProductService(productRepo, productCache)
+	carService := services.NewCarService(carRepo, carCache)
+	carImportService := services.NewCarImportService(carRepo, config.AppConfig.ImportAsyncRows, carCache)
+	carPriceService := services.NewCarPriceService(carRepo, carCache)
+	auditService := services.NewAuditService(auditRepo)
+	pricingRuleService := services.NewPricingRuleService(pricingRuleRepo, carRepo)
 
 	// Initialize controllers
 	productController := controllers.NewProductController(productService)
 	carController := controllers.NewCarController(carService)
+	carImportController := controllers.NewCarImportController(carImportService)
+	carPriceController := controllers.NewCarPriceController(carPriceService)
+	auditController := controllers.NewAuditController(auditService)
+	pricingRuleController := controllers.NewPricingRuleController(pricingRuleService)
+	// generator:controllers
+
+	// Business metrics, computed when /metrics is scraped
+	metrics.RegisterStats("cars", func(ctx context.Context) (map[string]interface{}, error) {
+		stats, err := carService.GetCarStats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"total_cars": stats.Total, "available_cars": stats.Available}, nil
+	},
+		metrics.StatGauge{Key: "total_cars", Name: "cars", Help: "Number of cars."},
+		metrics.StatGauge{Key: "available_cars", Name: "cars_available", Help: "Number of cars available for rent."},
+	)
+	metrics.RegisterStats("products", productService.GetProductStats,
+		metrics.StatGauge{Key: "total_products", Name: "products", Help: "Number of products."},
+	)
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
+		utils.SendDataResponse(c, http.StatusOK, gin.H{
 			"status":  "ok",
 			"message": "API is running",
 			"version": "1.0.0",
@@ -40,40 +82,73 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB) {
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// OpenAPI 3 documentation
+	router.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", docs.OpenAPIJSON)
+	})
+	router.GET("/openapi.yaml", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/yaml", docs.OpenAPIYAML)
+	})
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
 		// Apply middleware to API routes
-		v1.Use(middleware.RequestID())
 		v1.Use(middleware.RateLimiter())
+		v1.Use(middleware.AuditCaller(config.AppConfig.AdminToken))
+		cacheControl := middleware.CacheControl(config.AppConfig.HTTPCacheMaxAge)
+		requireIfMatch := middleware.RequireIfMatch(config.AppConfig.RequireIfMatch)
 
 		// Product routes
 		products := v1.Group("/products")
 		{
 			products.POST("", productController.CreateProduct)
-			products.GET("", productController.GetProducts)
-			products.GET("/:id", productController.GetProduct)
-			products.PUT("/:id", productController.UpdateProduct)
-			products.DELETE("/:id", productController.DeleteProduct)
+			products.GET("", cacheControl, productController.GetProducts)
+			products.GET("/:id", cacheControl, productController.GetProduct)
+			products.PUT("/:id", requireIfMatch, productController.UpdateProduct)
+			products.DELETE("/:id", requireIfMatch, productController.DeleteProduct)
 		}
 
 		// Car routes
 		cars := v1.Group("/cars")
 		{
 			cars.POST("", carController.CreateCar)
-			cars.GET("", carController.GetCars)
-			cars.GET("/:id", carController.GetCar)
-			cars.PUT("/:id", carController.UpdateCar)
-			cars.DELETE("/:id", carController.DeleteCar)
+			cars.GET("", cacheControl, carController.GetCars)
+			cars.GET("/stats", cacheControl, carController.GetCarStats)
+			cars.GET("/export", carController.ExportCars)
+			cars.POST("/bulk", carController.BulkCreateCars)
+			cars.PATCH("/bulk", carController.BulkUpdateCars)
+			cars.DELETE("/bulk", carController.BulkDeleteCars)
+			cars.POST("/import", carImportController.ImportCars)
+			cars.GET("/import/:id", carImportController.GetImportJob)
+			cars.GET("/:id", cacheControl, carController.GetCar)
+			cars.PUT("/:id", requireIfMatch, carController.ReplaceCar)
+			cars.PATCH("/:id", requireIfMatch, carController.PatchCar)
+			cars.DELETE("/:id", requireIfMatch, carController.DeleteCar)
+			cars.GET("/:id/prices", carPriceController.GetCarPrices)
+			cars.POST("/:id/prices", carPriceController.ScheduleCarPrice)
+			cars.DELETE("/:id/prices/:price_id", carPriceController.CancelCarPrice)
 		}
+
+		// Pricing rule routes
+		pricingRules := v1.Group("/pricing-rules")
+		{
+			pricingRules.POST("", pricingRuleController.CreatePricingRule)
+			pricingRules.GET("", pricingRuleController.GetPricingRules)
+			pricingRules.GET("/preview", pricingRuleController.PreviewPricing)
+			pricingRules.GET("/:id", pricingRuleController.GetPricingRule)
+			pricingRules.PUT("/:id", pricingRuleController.ReplacePricingRule)
+			pricingRules.DELETE("/:id", pricingRuleController.DeletePricingRule)
+		}
+
+		// Audit log, for admins
+		v1.GET("/audit", middleware.RequireAdmin(config.AppConfig.AdminToken), auditController.GetAuditLogs)
+
+		// generator:routes
 	}
 
 	// 404 handler
 	router.NoRoute(func(c *gin.Context) {
-		c.JSON(404, gin.H{
-			"success": false,
-			"message": "Route not found",
-			"error":   "The requested endpoint does not exist",
-		})
+		utils.SendErrorResponse(c, http.StatusNotFound, "Route not found", errors.New("The requested endpoint does not exist"))
 	})
 }
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"api-rentcar/models"
+	auditRepo "api-rentcar/repositories/audit"
+	"api-rentcar/telemetry"
+	"api-rentcar/utils"
+)
+
+// AuditServiceInterface defines the contract for reading the audit log
+type AuditServiceInterface interface {
+	GetAuditLogs(ctx context.Context, filter auditRepo.AuditLogFilter, page, limit int) ([]models.AuditLog, int64, error)
+}
+
+// AuditService implements AuditServiceInterface. Entries are written by the
+// audit GORM plugin as entities change, so the service only reads them.
+type AuditService struct {
+	auditRepo auditRepo.AuditRepositoryInterface
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(auditRepo auditRepo.AuditRepositoryInterface) AuditServiceInterface {
+	return &AuditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// GetAuditLogs retrieves the audit log of an entity type, optionally of one
+// entity and action, newest first
+func (s *AuditService) GetAuditLogs(ctx context.Context, filter auditRepo.AuditLogFilter, page, limit int) ([]models.AuditLog, int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "AuditService.GetAuditLogs")
+	defer span.End()
+
+	if filter.EntityType == "" {
+		return nil, 0, errors.New("entity is required")
+	}
+	switch filter.Action {
+	case "", models.AuditActionCreate, models.AuditActionUpdate, models.AuditActionDelete:
+	default:
+		return nil, 0, errors.New("action must be create, update or delete")
+	}
+
+	page, limit = utils.NormalizePagination(page, limit)
+	return s.auditRepo.GetAll(ctx, filter, page, limit)
+}
@@ -0,0 +1,396 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"api-rentcar/cache"
+	"api-rentcar/models"
+	carRepo "api-rentcar/repositories/car"
+	requests "api-rentcar/requests"
+	"api-rentcar/telemetry"
+	"api-rentcar/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidImportMapping is returned when the columns of an import can't be
+// mapped to car fields
+var ErrInvalidImportMapping = errors.New("invalid column mapping")
+
+const (
+	// importBatchSize is the number of rows written per transaction
+	importBatchSize = 100
+	// importJobRetention is how long finished jobs can be polled
+	importJobRetention = 24 * time.Hour
+)
+
+// CarImport is a parsed spreadsheet of cars to import
+type CarImport struct {
+	Header []string
+	Rows   [][]string
+	// Mapping maps car fields to column headers. Fields not listed are read
+	// from the column named after them, e.g., "license_plate" or "License Plate".
+	Mapping map[string]string
+	DryRun  bool
+	// Async runs the import in the background regardless of its size
+	Async bool
+}
+
+// CarImportServiceInterface defines the contract for fleet imports
+type CarImportServiceInterface interface {
+	ImportCars(ctx context.Context, imp *CarImport) (*models.ImportJob, error)
+	GetImportJob(ctx context.Context, id string) (*models.ImportJob, error)
+}
+
+// CarImportService implements CarImportServiceInterface. Jobs are kept in
+// memory, so they can only be polled on the instance that runs them.
+type CarImportService struct {
+	carRepo   carRepo.CarRepositoryInterface
+	asyncRows int
+	carCache  *cache.Namespace
+
+	mu   sync.RWMutex
+	jobs map[string]*models.ImportJob
+}
+
+// NewCarImportService creates a new car import service. Imports of more than
+// asyncRows rows run in the background. Each imported batch invalidates
+// carCache, the cache of car reads.
+func NewCarImportService(carRepo carRepo.CarRepositoryInterface, asyncRows int, carCache *cache.Namespace) CarImportServiceInterface {
+	return &CarImportService{
+		carRepo:   carRepo,
+		asyncRows: asyncRows,
+		carCache:  carCache,
+		jobs:      make(map[string]*models.ImportJob),
+	}
+}
+
+// ImportCars upserts cars by license plate from spreadsheet rows, validating
+// every row like a car creation. Rows that fail are reported and skipped. In a
+// dry run nothing is written. Small imports complete before returning; large
+// ones return a pending job to poll with GetImportJob.
+func (s *CarImportService) ImportCars(ctx context.Context, imp *CarImport) (*models.ImportJob, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CarImportService.ImportCars")
+	defer span.End()
+
+	columns, mapping, err := resolveImportMapping(imp.Header, imp.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.ImportJob{
+		ID:        uuid.NewString(),
+		Status:    models.ImportStatusPending,
+		DryRun:    imp.DryRun,
+		Mapping:   mapping,
+		TotalRows: len(imp.Rows),
+		CreatedAt: time.Now(),
+	}
+	s.addJob(job)
+
+	if imp.Async || len(imp.Rows) > s.asyncRows {
+		// The job outlives the request, so it mustn't be cancelled with it
+		go s.run(context.WithoutCancel(ctx), job.ID, imp.Rows, columns, imp.DryRun)
+		return s.GetImportJob(ctx, job.ID)
+	}
+
+	s.run(ctx, job.ID, imp.Rows, columns, imp.DryRun)
+	return s.GetImportJob(ctx, job.ID)
+}
+
+// GetImportJob returns a snapshot of an import job
+func (s *CarImportService) GetImportJob(ctx context.Context, id string) (*models.ImportJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, exists := s.jobs[id]
+	if !exists {
+		return nil, errors.New("import job not found")
+	}
+
+	snapshot := *job
+	snapshot.RowErrors = append([]models.ImportRowError(nil), job.RowErrors...)
+	return &snapshot, nil
+}
+
+// run processes the rows of a job in batches, recording progress after each
+func (s *CarImportService) run(ctx context.Context, jobID string, rows [][]string, columns map[string]int, dryRun bool) {
+	ctx, span := telemetry.StartSpan(ctx, "CarImportService.run")
+	defer span.End()
+
+	s.updateJob(jobID, func(job *models.ImportJob) {
+		job.Status = models.ImportStatusRunning
+	})
+
+	// Plates seen earlier in the file, which later rows update
+	seen := make(map[string]bool)
+
+	for start := 0; start < len(rows); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		var results []importRowResult
+		var err error
+		if dryRun {
+			results, err = s.checkBatch(ctx, rows[start:end], start, columns, seen)
+		} else {
+			results, err = s.importBatch(ctx, rows[start:end], start, columns)
+		}
+		if err != nil {
+			s.finishJob(jobID, err)
+			return
+		}
+
+		s.updateJob(jobID, func(job *models.ImportJob) {
+			for _, result := range results {
+				switch {
+				case result.rowError != nil:
+					job.Failed++
+					job.RowErrors = append(job.RowErrors, *result.rowError)
+				case result.created:
+					job.Created++
+				default:
+					job.Updated++
+				}
+			}
+			job.ProcessedRows = end
+		})
+	}
+
+	s.finishJob(jobID, nil)
+}
+
+// importRowResult is the outcome of one row
+type importRowResult struct {
+	created  bool
+	rowError *models.ImportRowError
+}
+
+// importBatch upserts a batch of rows in a transaction, each row in its own
+// savepoint so a failed row leaves the others intact
+func (s *CarImportService) importBatch(ctx context.Context, rows [][]string, offset int, columns map[string]int) ([]importRowResult, error) {
+	results := make([]importRowResult, len(rows))
+	defer s.carCache.Invalidate(ctx)
+
+	err := s.carRepo.Transaction(ctx, func(tx carRepo.CarRepositoryInterface) error {
+		for i, row := range rows {
+			req, rowError := decodeImportRow(row, offset+i, columns)
+			if rowError != nil {
+				results[i].rowError = rowError
+				continue
+			}
+
+			err := tx.Transaction(ctx, func(repo carRepo.CarRepositoryInterface) error {
+				created, err := upsertCar(ctx, repo, req)
+				results[i].created = created
+				return err
+			})
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				results[i].rowError = &models.ImportRowError{
+					Row:          importRowNumber(offset + i),
+					LicensePlate: req.LicensePlate,
+					Errors:       []string{err.Error()},
+				}
+			}
+		}
+		return nil
+	})
+
+	return results, err
+}
+
+// checkBatch validates a batch of rows and looks up which would create a car
+// and which would update one, without writing
+func (s *CarImportService) checkBatch(ctx context.Context, rows [][]string, offset int, columns map[string]int, seen map[string]bool) ([]importRowResult, error) {
+	results := make([]importRowResult, len(rows))
+
+	for i, row := range rows {
+		req, rowError := decodeImportRow(row, offset+i, columns)
+		if rowError != nil {
+			results[i].rowError = rowError
+			continue
+		}
+
+		if seen[req.LicensePlate] {
+			continue
+		}
+		seen[req.LicensePlate] = true
+
+		_, err := s.carRepo.GetByLicensePlate(ctx, req.LicensePlate)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			results[i].created = true
+		case err != nil:
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// upsertCar creates a car or, if one with the same license plate exists,
+// overwrites it. It reports whether the car was created.
+func upsertCar(ctx context.Context, repo carRepo.CarRepositoryInterface, req *requests.CreateCarRequest) (bool, error) {
+	car, err := repo.GetByLicensePlate(ctx, req.LicensePlate)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	if car == nil {
+		car = &models.Car{}
+		utils.MapFields(req, car)
+		return true, repo.Create(ctx, car)
+	}
+
+	utils.MapFields(req, car)
+	return false, repo.Update(ctx, car)
+}
+
+// decodeImportRow converts a row to a car creation request and validates it
+func decodeImportRow(row []string, index int, columns map[string]int) (*requests.CreateCarRequest, *models.ImportRowError) {
+	values := make(map[string]string, len(columns))
+	for field, column := range columns {
+		values[field] = row[column]
+	}
+
+	req := &requests.CreateCarRequest{}
+	errs := utils.MapStringValues(values, req)
+	if len(errs) == 0 {
+		errs = utils.ValidateStruct(req)
+	}
+	if len(errs) > 0 {
+		return nil, &models.ImportRowError{
+			Row:          importRowNumber(index),
+			LicensePlate: values["license_plate"],
+			Errors:       errs,
+		}
+	}
+
+	return req, nil
+}
+
+// importRowNumber converts the index of a data row to its spreadsheet row number
+func importRowNumber(index int) int {
+	return index + 2
+}
+
+// resolveImportMapping maps every car field to a column, using the explicit
+// mapping first and falling back to columns named after the fields. It returns
+// the column index and the header of every field.
+func resolveImportMapping(header []string, mapping map[string]string) (map[string]int, map[string]string, error) {
+	fields := importFields()
+
+	columnsByName := make(map[string]int, len(header))
+	for i, name := range header {
+		columnsByName[normalizeColumnName(name)] = i
+	}
+
+	columns := make(map[string]int, len(fields))
+	for field, column := range mapping {
+		if !containsString(fields, field) {
+			return nil, nil, fmt.Errorf("%w: unknown field %q", ErrInvalidImportMapping, field)
+		}
+		index, exists := columnsByName[normalizeColumnName(column)]
+		if !exists {
+			return nil, nil, fmt.Errorf("%w: column %q for field %q not found", ErrInvalidImportMapping, column, field)
+		}
+		columns[field] = index
+	}
+
+	var missing []string
+	for _, field := range fields {
+		if _, mapped := columns[field]; mapped {
+			continue
+		}
+		if index, exists := columnsByName[normalizeColumnName(field)]; exists {
+			columns[field] = index
+			continue
+		}
+		missing = append(missing, field)
+	}
+	if len(missing) > 0 {
+		return nil, nil, fmt.Errorf("%w: no column for %s", ErrInvalidImportMapping, strings.Join(missing, ", "))
+	}
+
+	resolved := make(map[string]string, len(columns))
+	for field, index := range columns {
+		resolved[field] = header[index]
+	}
+	return columns, resolved, nil
+}
+
+// importFields returns the JSON names of the fields of a car creation request
+func importFields() []string {
+	t := reflect.TypeOf(requests.CreateCarRequest{})
+	fields := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.SplitN(t.Field(i).Tag.Get("json"), ",", 2)[0]
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// normalizeColumnName lets "License Plate" and "license-plate" match license_plate
+func normalizeColumnName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *CarImportService) addJob(job *models.ImportJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Forget jobs that finished long ago
+	for id, existing := range s.jobs {
+		if existing.FinishedAt != nil && time.Since(*existing.FinishedAt) > importJobRetention {
+			delete(s.jobs, id)
+		}
+	}
+	s.jobs[job.ID] = job
+}
+
+func (s *CarImportService) updateJob(id string, update func(job *models.ImportJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, exists := s.jobs[id]; exists {
+		update(job)
+	}
+}
+
+func (s *CarImportService) finishJob(id string, err error) {
+	s.updateJob(id, func(job *models.ImportJob) {
+		now := time.Now()
+		job.FinishedAt = &now
+		job.Status = models.ImportStatusCompleted
+		if err != nil {
+			job.Status = models.ImportStatusFailed
+			job.Error = err.Error()
+		}
+	})
+}
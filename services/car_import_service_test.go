@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"api-rentcar/utils"
+)
+
+var importHeader = []string{
+	"Name", "Description", "Category", "Price Per Day", "Price Per Week", "Price Per Month",
+	"Brand", "Model", "Transmission", "Year", "Plate No", "Machine Number", "is-available",
+}
+
+func importRow(overrides map[int]string) []string {
+	row := []string{
+		"Avanza", "A family car for daily trips", "MPV", "350000", "2100000", "8000000",
+		"Toyota", "Avanza G", "Manual", "2022", "B1234XYZ", "M123456", "true",
+	}
+	for i, value := range overrides {
+		row[i] = value
+	}
+	return row
+}
+
+func TestResolveImportMapping(t *testing.T) {
+	columns, resolved, err := resolveImportMapping(importHeader, map[string]string{"license_plate": "plate no"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if columns["license_plate"] != 10 || resolved["license_plate"] != "Plate No" {
+		t.Errorf("license_plate mapped to column %d (%q), want 10 (Plate No)", columns["license_plate"], resolved["license_plate"])
+	}
+	if columns["price_per_day"] != 3 || columns["is_available"] != 12 {
+		t.Errorf("columns named after fields weren't matched: %v", columns)
+	}
+
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		want    string
+	}{
+		{"missing column", nil, "no column for license_plate"},
+		{"unknown field", map[string]string{"plate": "Plate No"}, `unknown field "plate"`},
+		{"unknown column", map[string]string{"license_plate": "Plate"}, `column "Plate" for field "license_plate" not found`},
+	}
+	for _, tt := range tests {
+		_, _, err := resolveImportMapping(importHeader, tt.mapping)
+		if !errors.Is(err, ErrInvalidImportMapping) || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeImportRow(t *testing.T) {
+	utils.InitValidator()
+	columns, _, err := resolveImportMapping(importHeader, map[string]string{"license_plate": "Plate No"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, rowError := decodeImportRow(importRow(nil), 0, columns)
+	if rowError != nil {
+		t.Fatalf("valid row rejected: %v", rowError.Errors)
+	}
+	if req.LicensePlate != "B1234XYZ" || req.PricePerDay != 350000 || req.Year != 2022 || req.IsAvailable == nil || !*req.IsAvailable {
+		t.Errorf("row decoded as %+v", req)
+	}
+
+	tests := []struct {
+		name      string
+		overrides map[int]string
+	}{
+		{"unparsable number", map[int]string{3: "cheap"}},
+		{"invalid category", map[int]string{2: "Truck"}},
+		{"missing license plate", map[int]string{10: ""}},
+	}
+	for _, tt := range tests {
+		_, rowError := decodeImportRow(importRow(tt.overrides), 3, columns)
+		if rowError == nil {
+			t.Errorf("%s: row accepted", tt.name)
+			continue
+		}
+		if rowError.Row != 5 {
+			t.Errorf("%s: row = %d, want the spreadsheet row 5", tt.name, rowError.Row)
+		}
+		if len(rowError.Errors) == 0 {
+			t.Errorf("%s: no errors reported", tt.name)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"api-rentcar/cache"
+	"api-rentcar/models"
+	carRepo "api-rentcar/repositories/car"
+	requests "api-rentcar/requests"
+	"api-rentcar/telemetry"
+
+	"gorm.io/gorm"
+)
+
+// duePriceBatchSize is the number of due price changes applied per pass
+const duePriceBatchSize = 100
+
+// CarPriceServiceInterface defines the contract for car price history and
+// scheduled price changes
+type CarPriceServiceInterface interface {
+	GetCarPrices(ctx context.Context, carID uint) ([]models.CarPrice, error)
+	GetCarPriceAt(ctx context.Context, carID uint, at time.Time) (*models.CarPrice, error)
+	ScheduleCarPrice(ctx context.Context, carID uint, req *requests.ScheduleCarPriceRequest) (*models.CarPrice, error)
+	CancelCarPrice(ctx context.Context, carID, priceID uint) error
+	ApplyDuePrices(ctx context.Context) (int, error)
+	RunScheduler(ctx context.Context, interval time.Duration)
+}
+
+// CarPriceService implements CarPriceServiceInterface. Price history is
+// recorded by the car repository as cars change; the service reads it and
+// applies scheduled changes once they take effect.
+type CarPriceService struct {
+	carRepo carRepo.CarRepositoryInterface
+	cache   *cache.Namespace
+}
+
+// NewCarPriceService creates a new car price service. Applying a scheduled
+// price change invalidates carCache, the cache of car reads.
+func NewCarPriceService(carRepo carRepo.CarRepositoryInterface, carCache *cache.Namespace) CarPriceServiceInterface {
+	return &CarPriceService{
+		carRepo: carRepo,
+		cache:   carCache,
+	}
+}
+
+// GetCarPrices retrieves the price history and scheduled price changes of a
+// car, latest effective first
+func (s *CarPriceService) GetCarPrices(ctx context.Context, carID uint) ([]models.CarPrice, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CarPriceService.GetCarPrices")
+	defer span.End()
+
+	if err := s.checkCarExists(ctx, carID); err != nil {
+		return nil, err
+	}
+	return s.carRepo.GetPrices(ctx, carID)
+}
+
+// GetCarPriceAt retrieves the prices a car had at a time, e.g., when it was
+// booked
+func (s *CarPriceService) GetCarPriceAt(ctx context.Context, carID uint, at time.Time) (*models.CarPrice, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CarPriceService.GetCarPriceAt")
+	defer span.End()
+
+	if err := s.checkCarExists(ctx, carID); err != nil {
+		return nil, err
+	}
+	price, err := s.carRepo.GetPriceAt(ctx, carID, at)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("no car price at that time")
+		}
+		return nil, err
+	}
+	return price, nil
+}
+
+// ScheduleCarPrice schedules a change of a car's prices, applied by the
+// scheduler once it takes effect
+func (s *CarPriceService) ScheduleCarPrice(ctx context.Context, carID uint, req *requests.ScheduleCarPriceRequest) (*models.CarPrice, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CarPriceService.ScheduleCarPrice")
+	defer span.End()
+
+	if !req.EffectiveFrom.After(time.Now()) {
+		return nil, errors.New("effective_from must be in the future")
+	}
+	if err := s.checkCarExists(ctx, carID); err != nil {
+		return nil, err
+	}
+
+	price := &models.CarPrice{
+		CarID:         carID,
+		PricePerDay:   req.PricePerDay,
+		PricePerWeek:  req.PricePerWeek,
+		PricePerMonth: req.PricePerMonth,
+		EffectiveFrom: req.EffectiveFrom,
+	}
+	if err := s.carRepo.SchedulePrice(ctx, price); err != nil {
+		return nil, err
+	}
+	return price, nil
+}
+
+// CancelCarPrice cancels a scheduled change of a car's prices
+func (s *CarPriceService) CancelCarPrice(ctx context.Context, carID, priceID uint) error {
+	ctx, span := telemetry.StartSpan(ctx, "CarPriceService.CancelCarPrice")
+	defer span.End()
+
+	if err := s.carRepo.DeleteScheduledPrice(ctx, carID, priceID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("car price not found")
+		}
+		if errors.Is(err, carRepo.ErrPriceApplied) {
+			return errors.New("car price already applied")
+		}
+		return err
+	}
+	return nil
+}
+
+// ApplyDuePrices applies the scheduled price changes that have taken effect
+// and returns how many were applied. Changes applied meanwhile by another
+// instance are skipped, and a change that fails to apply is logged and retried
+// on the next pass, so it doesn't hold back the others.
+func (s *CarPriceService) ApplyDuePrices(ctx context.Context) (int, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CarPriceService.ApplyDuePrices")
+	defer span.End()
+
+	applied := 0
+	defer func() {
+		if applied > 0 {
+			s.cache.Invalidate(ctx)
+		}
+	}()
+
+	// Failed changes stay due, so each fetch asks for as many more as have
+	// failed and skips them
+	failed := make(map[uint]bool)
+	for {
+		limit := duePriceBatchSize + len(failed)
+		due, err := s.carRepo.GetDuePrices(ctx, time.Now(), limit)
+		if err != nil {
+			return applied, err
+		}
+		for i := range due {
+			if failed[due[i].ID] {
+				continue
+			}
+			err := s.carRepo.ApplyPrice(ctx, &due[i])
+			if errors.Is(err, carRepo.ErrPriceApplied) {
+				continue
+			}
+			if err != nil {
+				failed[due[i].ID] = true
+				slog.ErrorContext(ctx, "failed to apply scheduled car price",
+					slog.Uint64("car_price_id", uint64(due[i].ID)),
+					slog.Uint64("car_id", uint64(due[i].CarID)),
+					slog.String("error", err.Error()))
+				continue
+			}
+			applied++
+		}
+		if len(due) < limit {
+			return applied, nil
+		}
+	}
+}
+
+// RunScheduler applies due price changes every interval until ctx is done
+func (s *CarPriceService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if applied, err := s.ApplyDuePrices(ctx); err != nil {
+			slog.ErrorContext(ctx, "failed to apply scheduled car prices", slog.String("error", err.Error()))
+		} else if applied > 0 {
+			slog.InfoContext(ctx, "applied scheduled car prices", slog.Int("count", applied))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkCarExists returns "car not found" unless the car exists
+func (s *CarPriceService) checkCarExists(ctx context.Context, carID uint) error {
+	exists, err := s.carRepo.ExistsByID(ctx, carID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("car not found")
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"api-rentcar/cache"
+	"api-rentcar/models"
+	carRepo "api-rentcar/repositories/car"
+)
+
+// duePriceRepository keeps scheduled price changes in memory. Applying the
+// changes in failing returns an error and leaves them due; those in
+// appliedElsewhere were applied by another instance.
+type duePriceRepository struct {
+	carRepo.CarRepositoryInterface
+	due              map[uint]models.CarPrice
+	failing          map[uint]bool
+	appliedElsewhere map[uint]bool
+	attempts         map[uint]int
+}
+
+func newDuePriceRepository(count int) *duePriceRepository {
+	repo := &duePriceRepository{
+		due:              make(map[uint]models.CarPrice),
+		failing:          make(map[uint]bool),
+		appliedElsewhere: make(map[uint]bool),
+		attempts:         make(map[uint]int),
+	}
+	for id := uint(1); id <= uint(count); id++ {
+		repo.due[id] = models.CarPrice{ID: id, CarID: id}
+	}
+	return repo
+}
+
+func (r *duePriceRepository) GetDuePrices(_ context.Context, _ time.Time, limit int) ([]models.CarPrice, error) {
+	prices := make([]models.CarPrice, 0, len(r.due))
+	for _, price := range r.due {
+		prices = append(prices, price)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].ID < prices[j].ID })
+	if len(prices) > limit {
+		prices = prices[:limit]
+	}
+	return prices, nil
+}
+
+func (r *duePriceRepository) ApplyPrice(_ context.Context, price *models.CarPrice) error {
+	r.attempts[price.ID]++
+	switch {
+	case r.failing[price.ID]:
+		return errors.New("database is locked")
+	case r.appliedElsewhere[price.ID]:
+		delete(r.due, price.ID)
+		return carRepo.ErrPriceApplied
+	}
+	delete(r.due, price.ID)
+	return nil
+}
+
+func TestApplyDuePricesContinuesPastFailures(t *testing.T) {
+	repo := newDuePriceRepository(2*duePriceBatchSize + 10)
+	repo.failing[1] = true
+	repo.failing[duePriceBatchSize+5] = true
+	repo.appliedElsewhere[2] = true
+	service := NewCarPriceService(repo, cache.NewNamespace(cache.None{}, "cars", time.Minute))
+
+	applied, err := service.ApplyDuePrices(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 2*duePriceBatchSize + 10 - 3; applied != want {
+		t.Errorf("applied = %d, want %d", applied, want)
+	}
+	if len(repo.due) != 2 {
+		t.Errorf("%d changes left due, want the 2 failing ones", len(repo.due))
+	}
+	for id, attempts := range repo.attempts {
+		if attempts != 1 {
+			t.Errorf("change %d attempted %d times, want once", id, attempts)
+		}
+	}
+}
+
+func TestApplyDuePricesStopsWhenEveryChangeFails(t *testing.T) {
+	repo := newDuePriceRepository(duePriceBatchSize + 1)
+	for id := range repo.due {
+		repo.failing[id] = true
+	}
+	service := NewCarPriceService(repo, cache.NewNamespace(cache.None{}, "cars", time.Minute))
+
+	applied, err := service.ApplyDuePrices(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied != 0 || len(repo.attempts) != duePriceBatchSize+1 {
+		t.Errorf("applied %d and attempted %d changes, want 0 and %d", applied, len(repo.attempts), duePriceBatchSize+1)
+	}
+}
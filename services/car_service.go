@@ -1,58 +1,87 @@
 package services
 
 import (
+	"api-rentcar/cache"
 	"api-rentcar/models"
 	carRepo "api-rentcar/repositories/car"
 	requests "api-rentcar/requests"
+	"api-rentcar/telemetry"
 	"api-rentcar/utils"
+	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"gorm.io/gorm"
 )
 
 // CarServiceInterface defines the contract for car business logic
 type CarServiceInterface interface {
-	CreateCar(req *requests.CreateCarRequest) (*models.Car, error)
-	GetCarByID(id uint) (*models.Car, error)
-	GetCars(page, limit int, available *bool) ([]models.Car, int64, error)
-	UpdateCar(id uint, req *requests.UpdateCarRequest) (*models.Car, error)
-	DeleteCar(id uint) error
-	GetCarStats() (map[string]interface{}, error)
+	CreateCar(ctx context.Context, req *requests.CreateCarRequest) (*models.Car, error)
+	GetCarByID(ctx context.Context, id uint, fields []string) (*models.Car, error)
+	GetCars(ctx context.Context, page, limit int, available *bool, fields []string) ([]models.Car, int64, error)
+	ExportCars(ctx context.Context, available *bool, fn func(cars []models.Car) error) error
+	ReplaceCar(ctx context.Context, id uint, version uint, req *requests.CreateCarRequest) (*models.Car, error)
+	DeleteCar(ctx context.Context, id uint, version uint) error
+	GetCarStats(ctx context.Context) (*models.CarStats, error)
+	BulkCreateCars(ctx context.Context, req *requests.BulkCreateCarsRequest) ([]models.BulkCarResult, error)
+	BulkUpdateCars(ctx context.Context, req *requests.BulkUpdateCarsRequest) ([]models.BulkCarResult, error)
+	BulkDeleteCars(ctx context.Context, req *requests.BulkDeleteCarsRequest) ([]models.BulkCarResult, error)
 }
 
 // CarService implements CarServiceInterface
 type CarService struct {
 	carRepo carRepo.CarRepositoryInterface
+	cache   *cache.Namespace
 }
 
-// NewCarService creates a new car service
-func NewCarService(carRepo carRepo.CarRepositoryInterface) CarServiceInterface {
+// NewCarService creates a new car service. Reads are cached in carCache,
+// which every change invalidates; a nil carCache caches nothing.
+func NewCarService(carRepo carRepo.CarRepositoryInterface, carCache *cache.Namespace) CarServiceInterface {
 	return &CarService{
 		carRepo: carRepo,
+		cache:   carCache,
 	}
 }
 
 // CreateCar creates a new car with business logic validation
-func (s *CarService) CreateCar(req *requests.CreateCarRequest) (*models.Car, error) {
+func (s *CarService) CreateCar(ctx context.Context, req *requests.CreateCarRequest) (*models.Car, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CarService.CreateCar")
+	defer span.End()
+
 	car := &models.Car{}
-	
+
 	// Use reflection-based field mapping for automatic assignment
 	utils.MapFields(req, car)
 
-	if err := s.carRepo.Create(car); err != nil {
+	if err := s.carRepo.Create(ctx, car); err != nil {
 		return nil, err
 	}
+	s.cache.Invalidate(ctx)
 
 	return car, nil
 }
 
-// GetCarByID retrieves a car by its ID
-func (s *CarService) GetCarByID(id uint) (*models.Car, error) {
+// GetCarByID retrieves a car by its ID. When fields, JSON field names, are
+// given only those are loaded.
+func (s *CarService) GetCarByID(ctx context.Context, id uint, fields []string) (*models.Car, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CarService.GetCarByID")
+	defer span.End()
+
 	if id == 0 {
 		return nil, errors.New("invalid car ID")
 	}
 
-	car, err := s.carRepo.GetByID(id)
+	car := &models.Car{}
+	key := fmt.Sprintf("id:%d:fields:%s", id, strings.Join(fields, ","))
+	err := s.cache.Remember(ctx, key, car, func() error {
+		loaded, err := s.carRepo.GetByID(ctx, id, fields)
+		if err != nil {
+			return err
+		}
+		*car = *loaded
+		return nil
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("car not found")
@@ -63,8 +92,12 @@ func (s *CarService) GetCarByID(id uint) (*models.Car, error) {
 	return car, nil
 }
 
-// GetCars retrieves all cars with pagination
-func (s *CarService) GetCars(page, limit int, available *bool) ([]models.Car, int64, error) {
+// GetCars retrieves all cars with pagination. When fields, JSON field names,
+// are given only those are loaded.
+func (s *CarService) GetCars(ctx context.Context, page, limit int, available *bool, fields []string) ([]models.Car, int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CarService.GetCars")
+	defer span.End()
+
 	// Business logic: validate pagination parameters
 	if page < 1 {
 		page = 1
@@ -73,40 +106,81 @@ func (s *CarService) GetCars(page, limit int, available *bool) ([]models.Car, in
 		limit = 10
 	}
 
-	cars, total, err := s.carRepo.GetAll(page, limit, available)
+	availableKey := "all"
+	if available != nil {
+		availableKey = fmt.Sprint(*available)
+	}
+	key := fmt.Sprintf("list:page:%d:limit:%d:available:%s:fields:%s", page, limit, availableKey, strings.Join(fields, ","))
+
+	var list carList
+	err := s.cache.Remember(ctx, key, &list, func() error {
+		var err error
+		list.Cars, list.Total, err = s.carRepo.GetAll(ctx, page, limit, available, fields)
+		return err
+	})
 	if err != nil {
 		return nil, 0, err
 	}
 
-	return cars, total, nil
+	return list.Cars, list.Total, nil
 }
 
-// UpdateCar updates an existing car
-func (s *CarService) UpdateCar(id uint, req *requests.UpdateCarRequest) (*models.Car, error) {
+// carList is a cached page of cars
+type carList struct {
+	Cars  []models.Car `json:"cars"`
+	Total int64        `json:"total"`
+}
+
+// exportBatchSize is the number of cars read per query when exporting
+const exportBatchSize = 500
+
+// ExportCars passes all cars matching the filters of GetCars to fn in batches
+func (s *CarService) ExportCars(ctx context.Context, available *bool, fn func(cars []models.Car) error) error {
+	ctx, span := telemetry.StartSpan(ctx, "CarService.ExportCars")
+	defer span.End()
+
+	return s.carRepo.FindInBatches(ctx, available, exportBatchSize, fn)
+}
+
+// ReplaceCar replaces all fields of an existing car. When version isn't zero,
+// the car must still be at that version.
+func (s *CarService) ReplaceCar(ctx context.Context, id uint, version uint, req *requests.CreateCarRequest) (*models.Car, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CarService.ReplaceCar")
+	defer span.End()
+
 	// Check if car exists
-	existingCar, err := s.carRepo.GetByID(id)
+	existingCar, err := s.carRepo.GetByID(ctx, id, nil)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("car not found")
 		}
 		return nil, err
 	}
+	if version != 0 && existingCar.Version != version {
+		return nil, errors.New("car version mismatch")
+	}
 
-	// Use reflection-based field mapping for automatic assignment
-	// This will handle all pointer fields automatically
-	utils.MapFieldsWithExclusions(req, existingCar, "ID", "CreatedAt", "UpdatedAt", "DeletedAt")
+	utils.MapFields(req, existingCar)
 
-	if err := s.carRepo.Update(existingCar); err != nil {
+	if err := s.carRepo.Update(ctx, existingCar); err != nil {
+		if errors.Is(err, carRepo.ErrVersionConflict) {
+			return nil, errors.New("car version mismatch")
+		}
 		return nil, err
 	}
+	s.cache.Invalidate(ctx)
 
 	return existingCar, nil
 }
 
-// DeleteCar deletes a car by its ID
-func (s *CarService) DeleteCar(id uint) error {
+// DeleteCar deletes a car by its ID. When version isn't zero, the car must
+// still be at that version.
+func (s *CarService) DeleteCar(ctx context.Context, id uint, version uint) error {
+	ctx, span := telemetry.StartSpan(ctx, "CarService.DeleteCar")
+	defer span.End()
+
 	// Check if car exists
-	exists, err := s.carRepo.ExistsByID(id)
+	exists, err := s.carRepo.ExistsByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -114,19 +188,169 @@ func (s *CarService) DeleteCar(id uint) error {
 		return errors.New("car not found")
 	}
 
-	return s.carRepo.Delete(id)
+	if err := s.carRepo.Delete(ctx, id, version); err != nil {
+		if errors.Is(err, carRepo.ErrVersionConflict) {
+			return errors.New("car version mismatch")
+		}
+		return err
+	}
+	s.cache.Invalidate(ctx)
+	return nil
 }
 
-// GetCarStats returns statistics about cars
-func (s *CarService) GetCarStats() (map[string]interface{}, error) {
-	total, err := s.carRepo.Count()
+// GetCarStats returns fleet statistics: counts by brand, category, transmission,
+// availability and year, and daily price ranges per category
+func (s *CarService) GetCarStats(ctx context.Context) (*models.CarStats, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CarService.GetCarStats")
+	defer span.End()
+
+	var stats *models.CarStats
+	err := s.cache.Remember(ctx, "stats", &stats, func() error {
+		var err error
+		stats, err = s.carRepo.GetStats(ctx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	return stats, nil
+}
+
+// BulkCreateCars creates several cars in a single transaction
+func (s *CarService) BulkCreateCars(ctx context.Context, req *requests.BulkCreateCarsRequest) ([]models.BulkCarResult, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CarService.BulkCreateCars")
+	defer span.End()
 
-	stats := map[string]interface{}{
-		"total_cars": total,
+	results := make([]models.BulkCarResult, len(req.Items))
+	for i := range req.Items {
+		results[i] = models.BulkCarResult{Index: i, Errors: utils.ValidateStruct(&req.Items[i])}
 	}
 
-	return stats, nil
+	err := s.runBulk(ctx, results, req.Mode, func(repo carRepo.CarRepositoryInterface, result *models.BulkCarResult) error {
+		car := &models.Car{}
+		utils.MapFields(&req.Items[result.Index], car)
+
+		if err := repo.Create(ctx, car); err != nil {
+			return err
+		}
+
+		result.ID = car.ID
+		result.Car = car
+		return nil
+	})
+	if err != nil {
+		// IDs assigned to rolled back inserts don't exist
+		for i := range results {
+			results[i].ID = 0
+		}
+	}
+
+	return results, err
+}
+
+// BulkUpdateCars updates several cars in a single transaction
+func (s *CarService) BulkUpdateCars(ctx context.Context, req *requests.BulkUpdateCarsRequest) ([]models.BulkCarResult, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CarService.BulkUpdateCars")
+	defer span.End()
+
+	results := make([]models.BulkCarResult, len(req.Items))
+	for i := range req.Items {
+		results[i] = models.BulkCarResult{Index: i, ID: req.Items[i].ID, Errors: utils.ValidateStruct(&req.Items[i])}
+	}
+
+	err := s.runBulk(ctx, results, req.Mode, func(repo carRepo.CarRepositoryInterface, result *models.BulkCarResult) error {
+		car, err := repo.GetByID(ctx, result.ID, nil)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("car not found")
+			}
+			return err
+		}
+
+		utils.MapFieldsWithExclusions(&req.Items[result.Index].UpdateCarRequest, car, "ID", "CreatedAt", "UpdatedAt", "DeletedAt")
+
+		if err := repo.Update(ctx, car); err != nil {
+			return err
+		}
+
+		result.Car = car
+		return nil
+	})
+
+	return results, err
+}
+
+// BulkDeleteCars deletes several cars in a single transaction
+func (s *CarService) BulkDeleteCars(ctx context.Context, req *requests.BulkDeleteCarsRequest) ([]models.BulkCarResult, error) {
+	ctx, span := telemetry.StartSpan(ctx, "CarService.BulkDeleteCars")
+	defer span.End()
+
+	results := make([]models.BulkCarResult, len(req.IDs))
+	for i, id := range req.IDs {
+		results[i] = models.BulkCarResult{Index: i, ID: id}
+		if id == 0 {
+			results[i].Errors = []string{"invalid car ID"}
+		}
+	}
+
+	err := s.runBulk(ctx, results, req.Mode, func(repo carRepo.CarRepositoryInterface, result *models.BulkCarResult) error {
+		exists, err := repo.ExistsByID(ctx, result.ID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.New("car not found")
+		}
+
+		return repo.Delete(ctx, result.ID, 0)
+	})
+
+	return results, err
+}
+
+// runBulk applies apply to every item that passed validation, in a single
+// transaction. Each item runs in its own savepoint so a failed item leaves the
+// others intact. In atomic mode nothing is written when any item is invalid,
+// and the transaction is rolled back when any item fails.
+func (s *CarService) runBulk(ctx context.Context, results []models.BulkCarResult, mode string, apply func(repo carRepo.CarRepositoryInterface, result *models.BulkCarResult) error) error {
+	atomic := mode != requests.BulkModePartial
+
+	if atomic {
+		for _, result := range results {
+			if len(result.Errors) > 0 {
+				return errors.New("bulk validation failed")
+			}
+		}
+	}
+
+	// Even a rolled back transaction may have been partly visible
+	defer s.cache.Invalidate(ctx)
+
+	failed := false
+	return s.carRepo.Transaction(ctx, func(tx carRepo.CarRepositoryInterface) error {
+		for i := range results {
+			result := &results[i]
+			if len(result.Errors) > 0 {
+				continue
+			}
+
+			err := tx.Transaction(ctx, func(item carRepo.CarRepositoryInterface) error {
+				return apply(item, result)
+			})
+			if err != nil {
+				// A cancelled request fails every remaining item the same way
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				result.Car = nil
+				result.Errors = []string{err.Error()}
+				failed = true
+			}
+		}
+
+		if atomic && failed {
+			return errors.New("bulk operation rolled back")
+		}
+		return nil
+	})
 }
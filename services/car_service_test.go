@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"api-rentcar/cache"
+	"api-rentcar/models"
+	carRepo "api-rentcar/repositories/car"
+	"api-rentcar/requests"
+	"api-rentcar/utils"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// carsTable creates the cars table in SQLite, which doesn't know the enum
+// columns AutoMigrate would create
+const carsTable = `CREATE TABLE cars (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name VARCHAR(100) NOT NULL,
+	description TEXT,
+	category VARCHAR(20) NOT NULL,
+	price_per_day DECIMAL(10,2) NOT NULL,
+	price_per_week DECIMAL(10,2) NOT NULL,
+	price_per_month DECIMAL(10,2) NOT NULL,
+	brand VARCHAR(20) NOT NULL,
+	model VARCHAR(100) NOT NULL,
+	transmission VARCHAR(10) NOT NULL,
+	year INTEGER NOT NULL,
+	license_plate VARCHAR(10) NOT NULL UNIQUE,
+	machine_number VARCHAR(10) NOT NULL,
+	is_available BOOLEAN NOT NULL,
+	version INTEGER NOT NULL DEFAULT 1,
+	created_at DATETIME,
+	updated_at DATETIME
+)`
+
+// newBulkCarService returns a car service over an in-memory SQLite database
+func newBulkCarService(t *testing.T) (CarServiceInterface, *gorm.DB) {
+	t.Helper()
+	utils.InitValidator()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger:         logger.Discard,
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to :memory: opens a database of its own
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.Exec(carsTable).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.CarPrice{}); err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewCarService(carRepo.NewCarRepository(db), cache.NewNamespace(cache.None{}, "cars", time.Minute))
+	return service, db
+}
+
+func bulkCar(plate string) requests.CreateCarRequest {
+	available := true
+	return requests.CreateCarRequest{
+		Name:          "Avanza " + plate,
+		Description:   "A family car for daily trips",
+		Category:      "MPV",
+		PricePerDay:   350000,
+		PricePerWeek:  2100000,
+		PricePerMonth: 8000000,
+		Brand:         "Toyota",
+		Model:         "Avanza G",
+		Transmission:  "Manual",
+		Year:          2022,
+		LicensePlate:  plate,
+		MachineNumber: "M123456",
+		IsAvailable:   &available,
+	}
+}
+
+func countCars(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Model(&models.Car{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestBulkCreateAtomicRollsBackOnItemFailure(t *testing.T) {
+	service, db := newBulkCarService(t)
+	ctx := context.Background()
+
+	// The third car repeats the plate of the first, which the unique index rejects
+	results, err := service.BulkCreateCars(ctx, &requests.BulkCreateCarsRequest{
+		Items: []requests.CreateCarRequest{bulkCar("B101"), bulkCar("B102"), bulkCar("B101")},
+	})
+	if err == nil || err.Error() != "bulk operation rolled back" {
+		t.Fatalf("err = %v, want the bulk operation rolled back", err)
+	}
+	if got := results[2].Errors; len(got) != 1 || got[0] != carRepo.ErrDuplicateLicensePlate.Error() {
+		t.Errorf("failed item errors = %v", got)
+	}
+	for _, result := range results {
+		if result.ID != 0 {
+			t.Errorf("item %d reports ID %d of a rolled back insert", result.Index, result.ID)
+		}
+	}
+	if count := countCars(t, db); count != 0 {
+		t.Errorf("%d cars written, want none", count)
+	}
+}
+
+func TestBulkCreatePartialKeepsSuccessfulItems(t *testing.T) {
+	service, db := newBulkCarService(t)
+	ctx := context.Background()
+
+	results, err := service.BulkCreateCars(ctx, &requests.BulkCreateCarsRequest{
+		Items: []requests.CreateCarRequest{bulkCar("B101"), bulkCar("B101"), bulkCar("B103")},
+		Mode:  requests.BulkModePartial,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results[1].Errors) == 0 {
+		t.Error("the duplicate plate didn't fail")
+	}
+	for _, i := range []int{0, 2} {
+		if results[i].ID == 0 || len(results[i].Errors) > 0 {
+			t.Errorf("item %d = %+v, want it created", i, results[i])
+		}
+	}
+
+	var plates []string
+	if err := db.Model(&models.Car{}).Order("id").Pluck("license_plate", &plates).Error; err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(plates) != "[B101 B103]" {
+		t.Errorf("plates written = %v, want [B101 B103]", plates)
+	}
+	// The failed item's savepoint was rolled back along with its price history
+	var prices int64
+	if err := db.Model(&models.CarPrice{}).Count(&prices).Error; err != nil {
+		t.Fatal(err)
+	}
+	if prices != 2 {
+		t.Errorf("%d price history entries, want 2", prices)
+	}
+}
+
+func TestBulkCreateAtomicValidationFailureWritesNothing(t *testing.T) {
+	service, db := newBulkCarService(t)
+	ctx := context.Background()
+
+	invalid := bulkCar("B102")
+	invalid.Name = ""
+	results, err := service.BulkCreateCars(ctx, &requests.BulkCreateCarsRequest{
+		Items: []requests.CreateCarRequest{bulkCar("B101"), invalid},
+	})
+	if err == nil || err.Error() != "bulk validation failed" {
+		t.Fatalf("err = %v, want the validation to fail", err)
+	}
+	if len(results[0].Errors) > 0 || len(results[1].Errors) == 0 {
+		t.Errorf("results = %+v, want only the second item invalid", results)
+	}
+	if count := countCars(t, db); count != 0 {
+		t.Errorf("%d cars written, want none", count)
+	}
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"api-rentcar/models"
+	carRepo "api-rentcar/repositories/car"
+	pricingRuleRepo "api-rentcar/repositories/pricingrule"
+	requests "api-rentcar/requests"
+	"api-rentcar/telemetry"
+	"api-rentcar/utils"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidPricingRule is returned for pricing rules whose fields contradict
+// each other, e.g., an end date before the start date
+var ErrInvalidPricingRule = errors.New("invalid pricing rule")
+
+// ErrInvalidRentalPeriod is returned for rental periods that can't be priced
+var ErrInvalidRentalPeriod = errors.New("invalid rental period")
+
+const (
+	// maxRentalDays is the longest rental period that can be priced
+	maxRentalDays = 366
+	// Days priced with the monthly and weekly price tiers
+	daysPerMonth = 30
+	daysPerWeek  = 7
+)
+
+// weekOrder lists the weekdays from Monday
+var weekOrder = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+
+// PricingRuleServiceInterface defines the contract for pricing rules and the
+// price computation of rentals
+type PricingRuleServiceInterface interface {
+	CreatePricingRule(ctx context.Context, req *requests.CreatePricingRuleRequest) (*models.PricingRule, error)
+	GetPricingRuleByID(ctx context.Context, id uint) (*models.PricingRule, error)
+	GetPricingRules(ctx context.Context, page, limit int, active *bool) ([]models.PricingRule, int64, error)
+	ReplacePricingRule(ctx context.Context, id uint, req *requests.CreatePricingRuleRequest) (*models.PricingRule, error)
+	DeletePricingRule(ctx context.Context, id uint) error
+	QuoteRental(ctx context.Context, carID uint, startDate, endDate time.Time) (*models.PriceQuote, error)
+}
+
+// PricingRuleService implements PricingRuleServiceInterface
+type PricingRuleService struct {
+	ruleRepo pricingRuleRepo.PricingRuleRepositoryInterface
+	carRepo  carRepo.CarRepositoryInterface
+}
+
+// NewPricingRuleService creates a new pricing rule service
+func NewPricingRuleService(ruleRepo pricingRuleRepo.PricingRuleRepositoryInterface, carRepo carRepo.CarRepositoryInterface) PricingRuleServiceInterface {
+	return &PricingRuleService{
+		ruleRepo: ruleRepo,
+		carRepo:  carRepo,
+	}
+}
+
+// CreatePricingRule creates a new pricing rule
+func (s *PricingRuleService) CreatePricingRule(ctx context.Context, req *requests.CreatePricingRuleRequest) (*models.PricingRule, error) {
+	ctx, span := telemetry.StartSpan(ctx, "PricingRuleService.CreatePricingRule")
+	defer span.End()
+
+	rule := &models.PricingRule{}
+	if err := mapPricingRule(req, rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// GetPricingRuleByID retrieves a pricing rule by its ID
+func (s *PricingRuleService) GetPricingRuleByID(ctx context.Context, id uint) (*models.PricingRule, error) {
+	ctx, span := telemetry.StartSpan(ctx, "PricingRuleService.GetPricingRuleByID")
+	defer span.End()
+
+	rule, err := s.ruleRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("pricing rule not found")
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+// GetPricingRules retrieves pricing rules with pagination, highest priority
+// first, optionally only the active or inactive ones
+func (s *PricingRuleService) GetPricingRules(ctx context.Context, page, limit int, active *bool) ([]models.PricingRule, int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "PricingRuleService.GetPricingRules")
+	defer span.End()
+
+	page, limit = utils.NormalizePagination(page, limit)
+	return s.ruleRepo.GetAll(ctx, page, limit, active)
+}
+
+// ReplacePricingRule replaces all fields of an existing pricing rule
+func (s *PricingRuleService) ReplacePricingRule(ctx context.Context, id uint, req *requests.CreatePricingRuleRequest) (*models.PricingRule, error) {
+	ctx, span := telemetry.StartSpan(ctx, "PricingRuleService.ReplacePricingRule")
+	defer span.End()
+
+	rule, err := s.ruleRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("pricing rule not found")
+		}
+		return nil, err
+	}
+	if err := mapPricingRule(req, rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeletePricingRule deletes a pricing rule by its ID
+func (s *PricingRuleService) DeletePricingRule(ctx context.Context, id uint) error {
+	ctx, span := telemetry.StartSpan(ctx, "PricingRuleService.DeletePricingRule")
+	defer span.End()
+
+	if _, err := s.GetPricingRuleByID(ctx, id); err != nil {
+		return err
+	}
+	return s.ruleRepo.Delete(ctx, id)
+}
+
+// QuoteRental computes the price of renting a car from startDate, the pickup
+// day, to endDate, the return day. The days are priced with the car's
+// monthly, weekly and daily tiers, and the tiered price is spread evenly over
+// them, each day's share using the tiers in effect when the day starts,
+// scheduled price changes included; each share is then adjusted by the active
+// pricing rule with the highest priority covering the day, if any.
+func (s *PricingRuleService) QuoteRental(ctx context.Context, carID uint, startDate, endDate time.Time) (*models.PriceQuote, error) {
+	ctx, span := telemetry.StartSpan(ctx, "PricingRuleService.QuoteRental")
+	defer span.End()
+
+	startDate, endDate = truncateDay(startDate), truncateDay(endDate)
+	days := int(endDate.Sub(startDate).Hours() / 24)
+	if days < 1 {
+		return nil, fmt.Errorf("%w: end_date must be after start_date", ErrInvalidRentalPeriod)
+	}
+	if days > maxRentalDays {
+		return nil, fmt.Errorf("%w: rentals can't be longer than %d days", ErrInvalidRentalPeriod, maxRentalDays)
+	}
+
+	car, err := s.carRepo.GetByID(ctx, carID, nil)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("car not found")
+		}
+		return nil, err
+	}
+
+	prices, err := s.carRepo.GetPrices(ctx, car.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	lastDay := endDate.AddDate(0, 0, -1)
+	rules, err := s.ruleRepo.FindActive(ctx, car.Category, car.Brand, startDate, lastDay)
+	if err != nil {
+		return nil, err
+	}
+
+	return quoteRental(car, prices, rules, startDate, days), nil
+}
+
+// quoteRental prices days days of renting car from startDate with prices, the
+// car's price history and scheduled price changes, latest effective first,
+// and rules, ordered by precedence. Days before the earliest prices use the
+// car's current ones.
+func quoteRental(car *models.Car, prices []models.CarPrice, rules []models.PricingRule, startDate time.Time, days int) *models.PriceQuote {
+	quote := &models.PriceQuote{
+		CarID:     car.ID,
+		StartDate: startDate,
+		EndDate:   startDate.AddDate(0, 0, days),
+		Days:      days,
+		Months:    days / daysPerMonth,
+		Weeks:     days % daysPerMonth / daysPerWeek,
+		ExtraDays: days % daysPerMonth % daysPerWeek,
+		Lines:     make([]models.PriceQuoteDay, days),
+	}
+	tieredPrice := func(perDay, perWeek, perMonth float64) float64 {
+		return float64(quote.Months)*perMonth + float64(quote.Weeks)*perWeek + float64(quote.ExtraDays)*perDay
+	}
+
+	basePrice := 0.0
+	for i := range quote.Lines {
+		line := &quote.Lines[i]
+		line.Date = startDate.AddDate(0, 0, i)
+
+		share := tieredPrice(car.PricePerDay, car.PricePerWeek, car.PricePerMonth) / float64(days)
+		for j := range prices {
+			if !prices[j].EffectiveFrom.After(line.Date) {
+				share = tieredPrice(prices[j].PricePerDay, prices[j].PricePerWeek, prices[j].PricePerMonth) / float64(days)
+				break
+			}
+		}
+		basePrice += share
+
+		line.BasePrice = roundPrice(share)
+		line.Price = line.BasePrice
+		for j := range rules {
+			if rules[j].Covers(car, line.Date) {
+				line.Rule = &rules[j]
+				line.Price = roundPrice(rules[j].Adjust(share))
+				break
+			}
+		}
+		quote.Total += line.Price
+	}
+	quote.BasePrice = roundPrice(basePrice)
+	quote.Total = roundPrice(quote.Total)
+
+	return quote
+}
+
+// mapPricingRule sets the fields of rule from req
+func mapPricingRule(req *requests.CreatePricingRuleRequest, rule *models.PricingRule) error {
+	startDate, err := parseOptionalDate(req.StartDate)
+	if err != nil {
+		return fmt.Errorf("%w: start_date: %v", ErrInvalidPricingRule, err)
+	}
+	endDate, err := parseOptionalDate(req.EndDate)
+	if err != nil {
+		return fmt.Errorf("%w: end_date: %v", ErrInvalidPricingRule, err)
+	}
+	if startDate != nil && endDate != nil && endDate.Before(*startDate) {
+		return fmt.Errorf("%w: end_date is before start_date", ErrInvalidPricingRule)
+	}
+	if req.AdjustmentType == models.AdjustmentPercent && req.Amount < -100 {
+		return fmt.Errorf("%w: a percent amount can't be below -100", ErrInvalidPricingRule)
+	}
+
+	// Weekdays are stored once each, in week order
+	var weekdays []string
+	for _, day := range weekOrder {
+		if name := strings.ToLower(day.String()); slices.Contains(req.Weekdays, name) {
+			weekdays = append(weekdays, name)
+		}
+	}
+
+	rule.Name = req.Name
+	rule.StartDate = startDate
+	rule.EndDate = endDate
+	rule.Weekdays = strings.Join(weekdays, ",")
+	rule.Category = req.Category
+	rule.Brand = req.Brand
+	rule.AdjustmentType = req.AdjustmentType
+	rule.Amount = req.Amount
+	rule.Priority = req.Priority
+	rule.IsActive = req.IsActive == nil || *req.IsActive
+	return nil
+}
+
+// parseOptionalDate parses a YYYY-MM-DD date as midnight in the local time
+// zone, which the database connection stores DATE columns in; nil when empty
+func parseOptionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// truncateDay returns the start of the day of t, in UTC
+func truncateDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// roundPrice rounds a price to cents
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
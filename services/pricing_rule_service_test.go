@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"api-rentcar/models"
+)
+
+func TestQuoteRentalTiers(t *testing.T) {
+	car := &models.Car{ID: 1, Category: models.SUV, Brand: models.Toyota, PricePerDay: 100, PricePerWeek: 600, PricePerMonth: 2000}
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	quote := quoteRental(car, nil, nil, start, 40)
+	if quote.Months != 1 || quote.Weeks != 1 || quote.ExtraDays != 3 {
+		t.Errorf("tiers = %d months, %d weeks, %d days, want 1, 1, 3", quote.Months, quote.Weeks, quote.ExtraDays)
+	}
+	if quote.BasePrice != 2900 || quote.Total != 2900 {
+		t.Errorf("base price %v, total %v, want 2900", quote.BasePrice, quote.Total)
+	}
+	if !quote.EndDate.Equal(start.AddDate(0, 0, 40)) || len(quote.Lines) != 40 {
+		t.Errorf("quote ends %v with %d days", quote.EndDate, len(quote.Lines))
+	}
+}
+
+func TestQuoteRentalAppliesRules(t *testing.T) {
+	car := &models.Car{ID: 1, Category: models.SUV, Brand: models.Toyota, PricePerDay: 100, PricePerWeek: 700, PricePerMonth: 3000}
+	// Monday to Thursday, returned on Friday
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	tuesday := time.Date(2024, 4, 2, 0, 0, 0, 0, time.Local)
+	rules := []models.PricingRule{
+		{ID: 2, Name: "Tuesday surcharge", StartDate: &tuesday, EndDate: &tuesday, AdjustmentType: models.AdjustmentFixed, Amount: 50, Priority: 10},
+		{ID: 1, Name: "Weekday discount", Weekdays: "monday,tuesday,wednesday,thursday,friday", AdjustmentType: models.AdjustmentPercent, Amount: -10},
+	}
+
+	quote := quoteRental(car, nil, rules, start, 4)
+	want := []float64{90, 150, 90, 90}
+	for i, line := range quote.Lines {
+		if line.Price != want[i] {
+			t.Errorf("day %d: price %v, want %v", i, line.Price, want[i])
+		}
+	}
+	if quote.Lines[1].Rule == nil || quote.Lines[1].Rule.ID != 2 {
+		t.Errorf("Tuesday priced with rule %v, want the higher priority one", quote.Lines[1].Rule)
+	}
+	if quote.BasePrice != 400 || quote.Total != 420 {
+		t.Errorf("base price %v, total %v, want 400 and 420", quote.BasePrice, quote.Total)
+	}
+}
+
+func TestQuoteRentalUsesPricesInEffectEachDay(t *testing.T) {
+	car := &models.Car{ID: 1, PricePerDay: 100, PricePerWeek: 700, PricePerMonth: 3000}
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	prices := []models.CarPrice{
+		// Scheduled change taking effect on the third day
+		{ID: 3, PricePerDay: 200, PricePerWeek: 1400, PricePerMonth: 6000, EffectiveFrom: start.AddDate(0, 0, 2)},
+		{ID: 2, PricePerDay: 100, PricePerWeek: 700, PricePerMonth: 3000, EffectiveFrom: start.Add(-time.Hour)},
+		{ID: 1, PricePerDay: 50, PricePerWeek: 350, PricePerMonth: 1500, EffectiveFrom: start.AddDate(0, -1, 0)},
+	}
+
+	quote := quoteRental(car, prices, nil, start, 4)
+	want := []float64{100, 100, 200, 200}
+	for i, line := range quote.Lines {
+		if line.BasePrice != want[i] {
+			t.Errorf("day %d: base price %v, want %v", i, line.BasePrice, want[i])
+		}
+	}
+	if quote.BasePrice != 600 || quote.Total != 600 {
+		t.Errorf("base price %v, total %v, want 600", quote.BasePrice, quote.Total)
+	}
+
+	// Days before the earliest prices use the car's current ones
+	quote = quoteRental(car, prices[:1], nil, start, 4)
+	if quote.Lines[0].BasePrice != 100 || quote.Lines[3].BasePrice != 200 {
+		t.Errorf("base prices %v and %v, want 100 and 200", quote.Lines[0].BasePrice, quote.Lines[3].BasePrice)
+	}
+}
@@ -1,57 +1,80 @@
 package services
 
 import (
-	"errors"
+	"api-rentcar/cache"
 	"api-rentcar/models"
-	requests "api-rentcar/requests"
 	productRepo "api-rentcar/repositories/product"
+	requests "api-rentcar/requests"
+	"api-rentcar/telemetry"
 	"api-rentcar/utils"
+	"context"
+	"errors"
+	"fmt"
 	"gorm.io/gorm"
 )
 
 // ProductServiceInterface defines the contract for product business logic
 type ProductServiceInterface interface {
-	CreateProduct(req *requests.CreateProductRequest) (*models.Product, error)
-	GetProductByID(id uint) (*models.Product, error)
-	GetProducts(page, limit int) ([]models.Product, int64, error)
-	UpdateProduct(id uint, req *requests.UpdateProductRequest) (*models.Product, error)
-	DeleteProduct(id uint) error
-	GetProductStats() (map[string]interface{}, error)
+	CreateProduct(ctx context.Context, req *requests.CreateProductRequest) (*models.Product, error)
+	GetProductByID(ctx context.Context, id uint) (*models.Product, error)
+	GetProducts(ctx context.Context, page, limit int) ([]models.Product, int64, error)
+	UpdateProduct(ctx context.Context, id uint, version uint, req *requests.UpdateProductRequest) (*models.Product, error)
+	DeleteProduct(ctx context.Context, id uint, version uint) error
+	GetProductStats(ctx context.Context) (map[string]interface{}, error)
 }
 
 // ProductService implements ProductServiceInterface
 type ProductService struct {
 	productRepo productRepo.ProductRepositoryInterface
+	cache       *cache.Namespace
 }
 
-// NewProductService creates a new product service
-func NewProductService(productRepo productRepo.ProductRepositoryInterface) ProductServiceInterface {
+// NewProductService creates a new product service. Reads are cached in
+// productCache, which every change invalidates; a nil productCache caches
+// nothing.
+func NewProductService(productRepo productRepo.ProductRepositoryInterface, productCache *cache.Namespace) ProductServiceInterface {
 	return &ProductService{
 		productRepo: productRepo,
+		cache:       productCache,
 	}
 }
 
 // CreateProduct creates a new product with business logic validation
-func (s *ProductService) CreateProduct(req *requests.CreateProductRequest) (*models.Product, error) {
+func (s *ProductService) CreateProduct(ctx context.Context, req *requests.CreateProductRequest) (*models.Product, error) {
+	ctx, span := telemetry.StartSpan(ctx, "ProductService.CreateProduct")
+	defer span.End()
+
 	product := &models.Product{}
-	
+
 	// Use reflection-based field mapping for automatic assignment
 	utils.MapFields(req, product)
 
-	if err := s.productRepo.Create(product); err != nil {
+	if err := s.productRepo.Create(ctx, product); err != nil {
 		return nil, err
 	}
+	s.cache.Invalidate(ctx)
 
 	return product, nil
 }
 
 // GetProductByID retrieves a product by its ID
-func (s *ProductService) GetProductByID(id uint) (*models.Product, error) {
+func (s *ProductService) GetProductByID(ctx context.Context, id uint) (*models.Product, error) {
+	ctx, span := telemetry.StartSpan(ctx, "ProductService.GetProductByID")
+	defer span.End()
+
 	if id == 0 {
 		return nil, errors.New("invalid product ID")
 	}
 
-	product, err := s.productRepo.GetByID(id)
+	product := &models.Product{}
+	err := s.cache.Remember(ctx, fmt.Sprintf("id:%d", id), product, func() error {
+		loaded, err := s.productRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		*product = *loaded
+		return nil
+	})
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("product not found")
@@ -62,8 +85,17 @@ func (s *ProductService) GetProductByID(id uint) (*models.Product, error) {
 	return product, nil
 }
 
+// productList is a cached page of products
+type productList struct {
+	Products []models.Product `json:"products"`
+	Total    int64            `json:"total"`
+}
+
 // GetProducts retrieves all products with pagination
-func (s *ProductService) GetProducts(page, limit int) ([]models.Product, int64, error) {
+func (s *ProductService) GetProducts(ctx context.Context, page, limit int) ([]models.Product, int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "ProductService.GetProducts")
+	defer span.End()
+
 	// Business logic: validate pagination parameters
 	if page < 1 {
 		page = 1
@@ -72,40 +104,60 @@ func (s *ProductService) GetProducts(page, limit int) ([]models.Product, int64,
 		limit = 10
 	}
 
-	products, total, err := s.productRepo.GetAll(page, limit)
+	var list productList
+	err := s.cache.Remember(ctx, fmt.Sprintf("list:page:%d:limit:%d", page, limit), &list, func() error {
+		var err error
+		list.Products, list.Total, err = s.productRepo.GetAll(ctx, page, limit)
+		return err
+	})
 	if err != nil {
 		return nil, 0, err
 	}
 
-	return products, total, nil
+	return list.Products, list.Total, nil
 }
 
-// UpdateProduct updates an existing product
-func (s *ProductService) UpdateProduct(id uint, req *requests.UpdateProductRequest) (*models.Product, error) {
+// UpdateProduct updates an existing product. When version isn't zero, the
+// product must still be at that version.
+func (s *ProductService) UpdateProduct(ctx context.Context, id uint, version uint, req *requests.UpdateProductRequest) (*models.Product, error) {
+	ctx, span := telemetry.StartSpan(ctx, "ProductService.UpdateProduct")
+	defer span.End()
+
 	// Check if product exists
-	existingProduct, err := s.productRepo.GetByID(id)
+	existingProduct, err := s.productRepo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("product not found")
 		}
 		return nil, err
 	}
+	if version != 0 && existingProduct.Version != version {
+		return nil, errors.New("product version mismatch")
+	}
 
 	// Use reflection-based field mapping for automatic assignment
 	// This will handle all pointer fields automatically
 	utils.MapFieldsWithExclusions(req, existingProduct, "ID", "CreatedAt", "UpdatedAt", "DeletedAt")
 
-	if err := s.productRepo.Update(existingProduct); err != nil {
+	if err := s.productRepo.Update(ctx, existingProduct); err != nil {
+		if errors.Is(err, productRepo.ErrVersionConflict) {
+			return nil, errors.New("product version mismatch")
+		}
 		return nil, err
 	}
+	s.cache.Invalidate(ctx)
 
 	return existingProduct, nil
 }
 
-// DeleteProduct deletes a product by its ID
-func (s *ProductService) DeleteProduct(id uint) error {
+// DeleteProduct deletes a product by its ID. When version isn't zero, the
+// product must still be at that version.
+func (s *ProductService) DeleteProduct(ctx context.Context, id uint, version uint) error {
+	ctx, span := telemetry.StartSpan(ctx, "ProductService.DeleteProduct")
+	defer span.End()
+
 	// Check if product exists
-	exists, err := s.productRepo.ExistsByID(id)
+	exists, err := s.productRepo.ExistsByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -113,12 +165,22 @@ func (s *ProductService) DeleteProduct(id uint) error {
 		return errors.New("product not found")
 	}
 
-	return s.productRepo.Delete(id)
+	if err := s.productRepo.Delete(ctx, id, version); err != nil {
+		if errors.Is(err, productRepo.ErrVersionConflict) {
+			return errors.New("product version mismatch")
+		}
+		return err
+	}
+	s.cache.Invalidate(ctx)
+	return nil
 }
 
 // GetProductStats returns statistics about products
-func (s *ProductService) GetProductStats() (map[string]interface{}, error) {
-	total, err := s.productRepo.Count()
+func (s *ProductService) GetProductStats(ctx context.Context) (map[string]interface{}, error) {
+	ctx, span := telemetry.StartSpan(ctx, "ProductService.GetProductStats")
+	defer span.End()
+
+	total, err := s.productRepo.Count(ctx)
 	if err != nil {
 		return nil, err
 	}
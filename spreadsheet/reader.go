@@ -0,0 +1,135 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Supported formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// utf8BOM starts CSV files saved by Excel
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// DetectFormat returns the format of a file from its name
+func DetectFormat(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	default:
+		return "", fmt.Errorf("unsupported file type %q (use .csv or .xlsx)", filepath.Ext(filename))
+	}
+}
+
+// Read reads the rows of a CSV file or of the first sheet of an XLSX file. The
+// first non-empty row is returned as the header; empty rows are skipped, cells
+// are trimmed and every row is padded or cut to the length of the header.
+func Read(r io.Reader, format string) (header []string, rows [][]string, err error) {
+	var records [][]string
+	switch format {
+	case FormatCSV:
+		records, err = readCSV(r)
+	case FormatXLSX:
+		records, err = readXLSX(r)
+	default:
+		return nil, nil, fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, record := range records {
+		if isEmpty(record) {
+			continue
+		}
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+		if header == nil {
+			header = record
+			continue
+		}
+		rows = append(rows, fit(record, len(header)))
+	}
+
+	if header == nil {
+		return nil, nil, errors.New("file has no header row")
+	}
+	return header, rows, nil
+}
+
+// readCSV reads comma or semicolon separated values, picking the separator
+// that occurs more often in the first line
+func readCSV(r io.Reader) ([][]string, error) {
+	buffered := bufio.NewReader(r)
+	if bom, err := buffered.Peek(len(utf8BOM)); err == nil && bytes.Equal(bom, utf8BOM) {
+		buffered.Discard(len(utf8BOM))
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if firstLine, _ := buffered.Peek(buffered.Size()); len(firstLine) > 0 {
+		if i := bytes.IndexByte(firstLine, '\n'); i >= 0 {
+			firstLine = firstLine[:i]
+		}
+		if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+			reader.Comma = ';'
+		}
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV file: %v", err)
+	}
+	return records, nil
+}
+
+// readXLSX reads the cell values of the first sheet as displayed
+func readXLSX(r io.Reader) ([][]string, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid XLSX file: %v", err)
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("XLSX file has no sheets")
+	}
+
+	records, err := file.GetRows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid XLSX file: %v", err)
+	}
+	return records, nil
+}
+
+func isEmpty(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func fit(record []string, length int) []string {
+	if len(record) >= length {
+		return record[:length]
+	}
+	return append(record, make([]string, length-len(record))...)
+}
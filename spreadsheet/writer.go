@@ -0,0 +1,214 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// FormatNDJSON writes one JSON object per line
+const FormatNDJSON = "ndjson"
+
+// Writer writes records, one value per column, as rows of a file
+type Writer interface {
+	// Write adds a record
+	Write(record []interface{}) error
+	// Flush sends the records written so far to the underlying writer. XLSX
+	// files can only be sent whole, on Close.
+	Flush() error
+	// Close flushes the remaining records and completes the file
+	Close() error
+}
+
+// NewWriter creates a writer for a format whose records have the given columns.
+// CSV and XLSX files start with a header row of the column names.
+func NewWriter(w io.Writer, format string, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	case FormatNDJSON:
+		return &ndjsonWriter{out: bufio.NewWriter(w), columns: columns}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q (use csv, xlsx or ndjson)", format)
+	}
+}
+
+// ContentType returns the media type of a format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// formulaPrefixes are the first characters that make spreadsheet applications
+// read a cell as a formula
+const formulaPrefixes = "=+-@\t\r"
+
+// EscapeFormula prefixes text that a spreadsheet application would run as a
+// formula, e.g., "=HYPERLINK(...)", with a single quote so it is shown as text
+func EscapeFormula(s string) string {
+	if s != "" && strings.IndexByte(formulaPrefixes, s[0]) >= 0 {
+		return "'" + s
+	}
+	return s
+}
+
+// formatCell converts a value to the text of a CSV cell. Text is escaped so it
+// can't run as a formula; numbers, e.g., negative ones, are written as they are.
+func formatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return EscapeFormula(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type csvWriter struct {
+	out *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	writer := &csvWriter{out: csv.NewWriter(w)}
+	if err := writer.out.Write(columns); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (w *csvWriter) Write(record []interface{}) error {
+	cells := make([]string, len(record))
+	for i, value := range record {
+		cells[i] = formatCell(value)
+	}
+	return w.out.Write(cells)
+}
+
+func (w *csvWriter) Flush() error {
+	w.out.Flush()
+	return w.out.Error()
+}
+
+func (w *csvWriter) Close() error {
+	return w.Flush()
+}
+
+// xlsxWriter streams rows to a worksheet, which excelize keeps in a temporary
+// file once it grows, and writes the workbook on Close
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXWriter(w io.Writer, columns []string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	stream, err := file.NewStreamWriter(file.GetSheetName(0))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	writer := &xlsxWriter{out: w, file: file, stream: stream}
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	if err := writer.Write(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (w *xlsxWriter) Write(record []interface{}) error {
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(record))
+	for i, value := range record {
+		// Timestamps are written as text, as in the other formats
+		if t, ok := value.(time.Time); ok {
+			value = t.Format(time.RFC3339)
+		}
+		values[i] = value
+	}
+	return w.stream.SetRow(cell, values)
+}
+
+func (w *xlsxWriter) Flush() error {
+	return nil
+}
+
+func (w *xlsxWriter) Close() error {
+	defer w.file.Close()
+
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	return w.file.Write(w.out)
+}
+
+type ndjsonWriter struct {
+	out     *bufio.Writer
+	columns []string
+}
+
+func (w *ndjsonWriter) Write(record []interface{}) error {
+	// Keys are written in column order, which encoding a map wouldn't keep
+	w.out.WriteByte('{')
+	for i, value := range record {
+		if i > 0 {
+			w.out.WriteByte(',')
+		}
+		key, err := json.Marshal(w.columns[i])
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		w.out.Write(key)
+		w.out.WriteByte(':')
+		w.out.Write(data)
+	}
+	w.out.WriteByte('}')
+	return w.out.WriteByte('\n')
+}
+
+func (w *ndjsonWriter) Flush() error {
+	return w.out.Flush()
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.Flush()
+}
@@ -0,0 +1,44 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCSVWriterEscapesFormulas(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewWriter(&out, FormatCSV, []string{"name", "brand", "plate", "note", "memo", "price", "discount", "year"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := []interface{}{"=HYPERLINK(\"http://x\")", "+Toyota", "-B 1234", "@SUM(A1)", "\tcell", -150000.5, -20, 2024}
+	if err := writer.Write(record); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "name,brand,plate,note,memo,price,discount,year\n" +
+		"\"'=HYPERLINK(\"\"http://x\"\")\",'+Toyota,'-B 1234,'@SUM(A1),'\tcell,-150000.5,-20,2024\n"
+	if out.String() != want {
+		t.Errorf("CSV =\n%q\nwant\n%q", out.String(), want)
+	}
+}
+
+func TestEscapeFormula(t *testing.T) {
+	tests := map[string]string{
+		"":        "",
+		"Avanza":  "Avanza",
+		"B 1234":  "B 1234",
+		"=1+2":    "'=1+2",
+		"\rline":  "'\rline",
+		"a=b":     "a=b",
+		"'quoted": "'quoted",
+	}
+	for value, want := range tests {
+		if got := EscapeFormula(value); got != want {
+			t.Errorf("EscapeFormula(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidETag is returned when an If-Match header isn't a single entity tag or *
+	ErrInvalidETag = errors.New("If-Match must be a single entity tag or *")
+	// ErrWeakETag is returned for weak If-Match tags, which never match
+	ErrWeakETag = errors.New("weak entity tags never match If-Match")
+)
+
+// ETag formats a resource version as a strong entity tag, e.g., "3"
+func ETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// ParseIfMatch returns the version an If-Match header requires, or 0 when the
+// header is empty or "*" and any version matches. Weak tags return ErrWeakETag,
+// as RFC 9110 requires a strong comparison for If-Match.
+func ParseIfMatch(header string) (uint, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	if strings.Contains(header, ",") {
+		return 0, ErrInvalidETag
+	}
+	if strings.HasPrefix(header, "W/") {
+		return 0, ErrWeakETag
+	}
+
+	version, err := strconv.ParseUint(strings.Trim(header, `"`), 10, 32)
+	if err != nil || version == 0 || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		return 0, ErrInvalidETag
+	}
+	return uint(version), nil
+}
+
+// MatchesIfNoneMatch reports whether an If-None-Match header matches etag,
+// using the weak comparison RFC 9110 specifies for it
+func MatchesIfNoneMatch(header string, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// NotModifiedSince reports whether a resource last modified at lastModified is
+// unchanged since the date of an If-Modified-Since header, to the second as
+// HTTP dates go. RFC 9110 has If-None-Match take precedence when both are sent.
+func NotModifiedSince(header string, lastModified time.Time) bool {
+	if header == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
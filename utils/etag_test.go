@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header  string
+		version uint
+		err     error
+	}{
+		{"", 0, nil},
+		{"*", 0, nil},
+		{ETag(7), 7, nil},
+		{` "3" `, 3, nil},
+		{`W/"3"`, 0, ErrWeakETag},
+		{`"1", "2"`, 0, ErrInvalidETag},
+		{`3`, 0, ErrInvalidETag},
+		{`"0"`, 0, ErrInvalidETag},
+		{`"abc"`, 0, ErrInvalidETag},
+	}
+	for _, tt := range tests {
+		version, err := ParseIfMatch(tt.header)
+		if version != tt.version || !errors.Is(err, tt.err) {
+			t.Errorf("ParseIfMatch(%q) = %d, %v, want %d, %v", tt.header, version, err, tt.version, tt.err)
+		}
+	}
+}
+
+func TestMatchesIfNoneMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		match  bool
+	}{
+		{`"2"`, true},
+		{`W/"2"`, true},
+		{`"1", "2"`, true},
+		{"*", true},
+		{`"1"`, false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := MatchesIfNoneMatch(tt.header, ETag(2)); got != tt.match {
+			t.Errorf("MatchesIfNoneMatch(%q) = %v, want %v", tt.header, got, tt.match)
+		}
+	}
+}
+
+func TestNotModifiedSince(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 10, 0, 0, 500, time.UTC)
+	if !NotModifiedSince("Wed, 01 May 2024 10:00:00 GMT", modified) {
+		t.Error("unchanged resource reported as modified")
+	}
+	if NotModifiedSince("Wed, 01 May 2024 09:59:59 GMT", modified) {
+		t.Error("changed resource reported as unmodified")
+	}
+	if NotModifiedSince("yesterday", modified) {
+		t.Error("unparsable date reported as unmodified")
+	}
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ParseFields parses a sparse fieldset such as "id,name,price_per_day" and
+// checks every name against the JSON fields of the response struct dto. It
+// returns nil, meaning all fields, for an empty value.
+func ParseFields(value string, dto any) ([]string, error) {
+	names := ParseCommaList(value)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	allowed := JSONFieldNames(dto)
+	fields := make([]string, 0, len(names))
+	for _, name := range names {
+		if !containsName(allowed, name) {
+			return nil, fmt.Errorf("unknown field %q (available: %s)", name, strings.Join(allowed, ", "))
+		}
+		if !containsName(fields, name) {
+			fields = append(fields, name)
+		}
+	}
+	return fields, nil
+}
+
+// SelectFields returns a struct, or each struct of a slice, reduced to the
+// given JSON fields, in their declaration order. With no fields v is returned
+// as is.
+func SelectFields(v any, fields []string) any {
+	if len(fields) == 0 {
+		return v
+	}
+
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Slice {
+		items := make([]fieldSet, value.Len())
+		for i := range items {
+			items[i] = newFieldSet(value.Index(i).Interface(), fields)
+		}
+		return items
+	}
+	return newFieldSet(v, fields)
+}
+
+// fieldSet is a subset of the fields of a struct, marshalled as a JSON object
+type fieldSet struct {
+	names  []string
+	values []any
+}
+
+func newFieldSet(s any, fields []string) fieldSet {
+	var names []string
+	for _, name := range JSONFieldNames(s) {
+		if containsName(fields, name) {
+			names = append(names, name)
+		}
+	}
+	return fieldSet{names: names, values: JSONFieldValues(s, names)}
+}
+
+// MarshalJSON writes the fields in order, which a map wouldn't keep
+func (f fieldSet) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range f.names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
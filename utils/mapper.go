@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -186,3 +188,127 @@ func HasField(s any, fieldName string) bool {
 
 	return false
 }
+
+// MapStringValues sets the fields of dst, a pointer to a struct, from string
+// values keyed by the fields' JSON names, e.g., cells of a spreadsheet row.
+// Values are converted to the field types; empty values leave fields unset.
+// It returns a message for every value that can't be converted.
+func MapStringValues(values map[string]string, dst any) []string {
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	dstValue = dstValue.Elem()
+	dstType := dstValue.Type()
+
+	var errors []string
+	for i := 0; i < dstType.NumField(); i++ {
+		name := strings.SplitN(dstType.Field(i).Tag.Get("json"), ",", 2)[0]
+		value, exists := values[name]
+		if name == "" || name == "-" || !exists || value == "" {
+			continue
+		}
+
+		field := dstValue.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		if field.Kind() == reflect.Ptr {
+			field.Set(reflect.New(field.Type().Elem()))
+			field = field.Elem()
+		}
+
+		if err := setFromString(field, value); err != nil {
+			errors = append(errors, fmt.Sprintf("%s %s", name, err.Error()))
+		}
+	}
+
+	return errors
+}
+
+// setFromString converts a string to the type of field and assigns it
+func setFromString(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a whole number")
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a positive whole number")
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		field.SetFloat(n)
+	case reflect.Bool:
+		switch strings.ToLower(value) {
+		case "true", "yes", "y", "1":
+			field.SetBool(true)
+		case "false", "no", "n", "0":
+			field.SetBool(false)
+		default:
+			return fmt.Errorf("must be true or false")
+		}
+	default:
+		return fmt.Errorf("has an unsupported type")
+	}
+	return nil
+}
+
+// JSONFieldNames returns the JSON names of the fields of a struct, in the
+// order they are declared
+func JSONFieldNames(s any) []string {
+	t := reflect.TypeOf(s)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if field.PkgPath == "" && name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// JSONFieldValues returns the values of the fields of a struct with the given
+// JSON names, in that order. Unknown names get nil.
+func JSONFieldValues(s any, names []string) []any {
+	v := reflect.ValueOf(s)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	t := v.Type()
+	indexes := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.SplitN(t.Field(i).Tag.Get("json"), ",", 2)[0]
+		indexes[name] = i
+	}
+
+	values := make([]any, len(names))
+	for i, name := range names {
+		if index, exists := indexes[name]; exists {
+			values[i] = v.Field(index).Interface()
+		}
+	}
+	return values
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// acceptRange is an item of an Accept or Accept-Encoding header with its quality
+type acceptRange struct {
+	value   string
+	quality float64
+}
+
+// parseAccept parses an Accept-style header such as
+// "application/json, text/csv;q=0.5" into its items, lowercased, with
+// parameters other than q dropped
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, item := range strings.Split(header, ",") {
+		value, params, _ := strings.Cut(item, ";")
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, q, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(name) != "q" {
+				continue
+			}
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(q), 64); err == nil && parsed >= 0 && parsed <= 1 {
+				quality = parsed
+			} else {
+				quality = 0
+			}
+		}
+		ranges = append(ranges, acceptRange{value: value, quality: quality})
+	}
+	return ranges
+}
+
+// NegotiateMediaType returns the offer an Accept header prefers, or "" when it
+// accepts none of them. Every offer is acceptable for an empty header, and
+// ties go to the earlier offer.
+func NegotiateMediaType(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" && len(offers) > 0 {
+		return offers[0]
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQuality := "", 0.0
+	for _, offer := range offers {
+		offerType, _, _ := strings.Cut(offer, "/")
+
+		// The most specific range matching the offer sets its quality
+		quality, specificity := 0.0, -1
+		for _, r := range ranges {
+			rangeType, rangeSubtype, _ := strings.Cut(r.value, "/")
+			var s int
+			switch {
+			case r.value == strings.ToLower(offer):
+				s = 2
+			case rangeType == offerType && rangeSubtype == "*":
+				s = 1
+			case r.value == "*/*":
+				s = 0
+			default:
+				continue
+			}
+			if s > specificity {
+				quality, specificity = r.quality, s
+			}
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+	return best
+}
+
+// NegotiateEncoding returns the content coding an Accept-Encoding header
+// prefers among offers, or "" for identity, i.e., no coding. Ties go to the
+// earlier offer.
+func NegotiateEncoding(acceptEncoding string, offers []string) string {
+	ranges := parseAccept(acceptEncoding)
+
+	qualityOf := func(coding string) (float64, bool) {
+		wildcard, hasWildcard := 0.0, false
+		for _, r := range ranges {
+			if r.value == coding {
+				return r.quality, true
+			}
+			if r.value == "*" {
+				wildcard, hasWildcard = r.quality, true
+			}
+		}
+		return wildcard, hasWildcard
+	}
+
+	best, bestQuality := "", 0.0
+	for _, offer := range offers {
+		if quality, ok := qualityOf(offer); ok && quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+
+	// Identity is acceptable unless excluded, and preferred when rated higher
+	if identity, ok := qualityOf("identity"); ok && identity > bestQuality {
+		return ""
+	}
+	return best
+}
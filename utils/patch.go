@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// Patch media types
+const (
+	// MergePatchContentType is a JSON Merge Patch (RFC 7396): fields present
+	// replace the current values, null clears them
+	MergePatchContentType = "application/merge-patch+json"
+	// JSONPatchContentType is a JSON Patch (RFC 6902): a list of operations
+	JSONPatchContentType = "application/json-patch+json"
+)
+
+// ApplyPatch applies a merge patch or JSON patch to the JSON form of current
+// and decodes the result into dst, a pointer to a request struct. Only the
+// fields of dst are patchable: the document patched holds just those fields
+// of current, and patches adding any other field are rejected. Plain
+// application/json bodies are treated as merge patches.
+func ApplyPatch(current any, patch []byte, contentType string, dst any) error {
+	doc, err := patchableDocument(current, dst)
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	switch contentType {
+	case JSONPatchContentType:
+		operations, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return fmt.Errorf("invalid JSON patch: %v", err)
+		}
+		if patched, err = operations.Apply(doc); err != nil {
+			return fmt.Errorf("failed to apply JSON patch: %v", err)
+		}
+	case MergePatchContentType, "application/json", "":
+		if !json.Valid(patch) || !bytes.HasPrefix(bytes.TrimSpace(patch), []byte("{")) {
+			return fmt.Errorf("invalid merge patch: must be a JSON object")
+		}
+		if patched, err = jsonpatch.MergePatch(doc, patch); err != nil {
+			return fmt.Errorf("invalid merge patch: %v", err)
+		}
+	default:
+		return fmt.Errorf("unsupported patch type %q (use %s or %s)", contentType, MergePatchContentType, JSONPatchContentType)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return fmt.Errorf("invalid patched document: %v", err)
+	}
+	return nil
+}
+
+// patchableDocument returns the JSON form of current restricted to the fields of dst
+func patchableDocument(current any, dst any) ([]byte, error) {
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	doc := make(map[string]json.RawMessage)
+	for _, name := range JSONFieldNames(dst) {
+		if value, exists := fields[name]; exists {
+			doc[name] = value
+		}
+	}
+	return json.Marshal(doc)
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+// patchedCar is the stored resource: its ID isn't part of patchCarRequest
+type patchedCar struct {
+	ID          uint    `json:"id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	Year        int     `json:"year"`
+}
+
+// patchCarRequest is the body a patched car is validated as
+type patchCarRequest struct {
+	Name        string  `json:"name" validate:"required,min=3"`
+	Description *string `json:"description" validate:"omitempty,min=10"`
+	Year        int     `json:"year" validate:"required"`
+}
+
+func TestApplyPatch(t *testing.T) {
+	InitValidator()
+	description := "A family car for daily trips"
+	current := patchedCar{ID: 7, Name: "Avanza", Description: &description, Year: 2022}
+
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		err         string
+		invalid     bool
+		check       func(t *testing.T, req patchCarRequest)
+	}{
+		{
+			name:        "merge patch replaces fields",
+			contentType: MergePatchContentType,
+			patch:       `{"year": 2024}`,
+			check: func(t *testing.T, req patchCarRequest) {
+				if req.Year != 2024 || req.Name != "Avanza" {
+					t.Errorf("patched = %+v, want year 2024 and the name kept", req)
+				}
+			},
+		},
+		{
+			name:        "null clears an optional field",
+			contentType: MergePatchContentType,
+			patch:       `{"description": null}`,
+			check: func(t *testing.T, req patchCarRequest) {
+				if req.Description != nil {
+					t.Errorf("description = %q, want it cleared", *req.Description)
+				}
+			},
+		},
+		{
+			name:        "null on a required field fails validation",
+			contentType: MergePatchContentType,
+			patch:       `{"name": null}`,
+			invalid:     true,
+		},
+		{
+			name:        "merge patch must be an object",
+			contentType: MergePatchContentType,
+			patch:       `[{"name": "Xenia"}]`,
+			err:         "must be a JSON object",
+		},
+		{
+			name:        "merge patch must be JSON",
+			contentType: MergePatchContentType,
+			patch:       `{"name": `,
+			err:         "must be a JSON object",
+		},
+		{
+			name:        "JSON patch replaces fields",
+			contentType: JSONPatchContentType,
+			patch:       `[{"op": "replace", "path": "/name", "value": "Xenia"}]`,
+			check: func(t *testing.T, req patchCarRequest) {
+				if req.Name != "Xenia" || req.Year != 2022 {
+					t.Errorf("patched = %+v, want name Xenia and the year kept", req)
+				}
+			},
+		},
+		{
+			name:        "JSON patch can't add fields of the resource outside the request",
+			contentType: JSONPatchContentType,
+			patch:       `[{"op": "add", "path": "/id", "value": 8}]`,
+			err:         `unknown field "id"`,
+		},
+		{
+			name:        "JSON patch can't add unknown fields",
+			contentType: JSONPatchContentType,
+			patch:       `[{"op": "add", "path": "/color", "value": "red"}]`,
+			err:         `unknown field "color"`,
+		},
+		{
+			name:        "plain JSON is a merge patch",
+			contentType: "application/json",
+			patch:       `{"name": "Xenia", "description": null}`,
+			check: func(t *testing.T, req patchCarRequest) {
+				if req.Name != "Xenia" || req.Description != nil || req.Year != 2022 {
+					t.Errorf("patched = %+v, want it merged", req)
+				}
+			},
+		},
+		{
+			name:        "other media types are rejected",
+			contentType: "text/plain",
+			patch:       `{"year": 2024}`,
+			err:         "unsupported patch type",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req patchCarRequest
+			err := ApplyPatch(&current, []byte(tt.patch), tt.contentType, &req)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if validationErrors := ValidateStruct(&req); tt.invalid != (len(validationErrors) > 0) {
+				t.Errorf("validation errors = %v, want invalid %v", validationErrors, tt.invalid)
+			}
+			if tt.check != nil {
+				tt.check(t, req)
+			}
+		})
+	}
+
+	if current.Name != "Avanza" || current.Description == nil || current.Year != 2022 {
+		t.Errorf("current changed to %+v", current)
+	}
+}
@@ -0,0 +1,14 @@
+package utils
+
+import "strings"
+
+// ParseCommaList splits a comma-separated query value such as "car,tags" into its non-empty items
+func ParseCommaList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
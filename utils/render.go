@@ -0,0 +1,221 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"api-rentcar/logger"
+	"api-rentcar/spreadsheet"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// Media types responses can be sent in, chosen by the request's Accept header
+const (
+	MediaTypeJSON    = "application/json"
+	MediaTypeMsgPack = "application/msgpack"
+	MediaTypeCSV     = "text/csv"
+)
+
+// mediaTypeXMsgPack is the unregistered name MessagePack clients often send
+const mediaTypeXMsgPack = "application/x-msgpack"
+
+// ResponseMediaTypes are the media types responses can be sent in, JSON first
+// as the default
+var ResponseMediaTypes = []string{MediaTypeJSON, MediaTypeMsgPack, mediaTypeXMsgPack, MediaTypeCSV}
+
+// errNotTabular is returned for data that can't be written as CSV rows
+var errNotTabular = errors.New("data is not a list of objects")
+
+// respond sends body, an Envelope or a legacy body, in the media type the
+// request accepts: JSON, MessagePack, or, for data that is an object or a list
+// of objects, CSV of data alone. Reads that accept none of them are answered
+// with 406 Not Acceptable. Errors and the results of writes are sent as JSON
+// instead, as the request has already been handled.
+func respond(c *gin.Context, statusCode int, body any, data any, isError bool) {
+	c.Writer.Header().Add("Vary", "Accept")
+
+	accept := c.GetHeader("Accept")
+	offers := ResponseMediaTypes
+	if isError {
+		offers = []string{MediaTypeJSON, MediaTypeMsgPack, mediaTypeXMsgPack}
+	}
+
+	var rows [][]string
+	mediaType := NegotiateMediaType(accept, offers)
+	if mediaType == MediaTypeCSV {
+		var err error
+		if rows, err = csvRows(data); err != nil {
+			mediaType = NegotiateMediaType(accept, offers[:3])
+		}
+	}
+
+	switch mediaType {
+	case MediaTypeMsgPack, mediaTypeXMsgPack:
+		value, err := msgpackValue(body)
+		if err != nil {
+			c.JSON(statusCode, body)
+			return
+		}
+		c.Render(statusCode, render.MsgPack{Data: value})
+	case MediaTypeCSV:
+		c.Header("Content-Type", MediaTypeCSV+"; charset=utf-8")
+		c.Status(statusCode)
+		writer := csv.NewWriter(c.Writer)
+		_ = writer.WriteAll(rows)
+	case MediaTypeJSON:
+		c.JSON(statusCode, body)
+	default:
+		method := c.Request.Method
+		if isError || (method != http.MethodGet && method != http.MethodHead) {
+			c.JSON(statusCode, body)
+			return
+		}
+		sendNotAcceptable(c)
+	}
+}
+
+// sendNotAcceptable sends 406 Not Acceptable, as JSON since the request
+// accepts nothing else we send
+func sendNotAcceptable(c *gin.Context) {
+	message := "Not acceptable"
+	detail := "responses are available as " + strings.Join([]string{MediaTypeJSON, MediaTypeMsgPack, MediaTypeCSV}, ", ")
+	requestID := logger.RequestID(c.Request.Context())
+	if legacyResponses {
+		c.JSON(http.StatusNotAcceptable, ErrorResponse{Success: false, Message: message, Error: detail, RequestID: requestID})
+		return
+	}
+	c.JSON(http.StatusNotAcceptable, Envelope{
+		Meta:   Meta{RequestID: requestID},
+		Errors: []APIError{{Message: message, Detail: detail}},
+	})
+}
+
+// msgpackValue converts body to maps, slices and scalars through its JSON
+// encoding, so MessagePack carries the same field names and values as JSON
+func msgpackValue(body any) (any, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return convertNumbers(value), nil
+}
+
+// convertNumbers replaces the json.Numbers of a decoded value with int64s or
+// float64s
+func convertNumbers(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = convertNumbers(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = convertNumbers(item)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return value
+}
+
+// csvRows returns the header and rows of data, an object or a list of
+// objects, with a column per JSON field in the order the fields first appear.
+// Nested objects and lists are written as JSON.
+func csvRows(data any) ([][]string, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []json.RawMessage
+	switch trimmed := bytes.TrimSpace(encoded); {
+	case len(trimmed) > 0 && trimmed[0] == '[':
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, err
+		}
+	case len(trimmed) > 0 && trimmed[0] == '{':
+		items = []json.RawMessage{trimmed}
+	default:
+		return nil, errNotTabular
+	}
+
+	var columns []string
+	objects := make([]map[string]json.RawMessage, len(items))
+	for i, item := range items {
+		names, values, err := decodeObject(item)
+		if err != nil {
+			return nil, err
+		}
+		objects[i] = values
+		for _, name := range names {
+			if !containsName(columns, name) {
+				columns = append(columns, name)
+			}
+		}
+	}
+
+	rows := [][]string{columns}
+	for _, object := range objects {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = csvCell(object[column])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// decodeObject decodes a JSON object into its values by name, along with the
+// names in order
+func decodeObject(raw json.RawMessage) ([]string, map[string]json.RawMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, nil, errNotTabular
+	}
+
+	var names []string
+	values := make(map[string]json.RawMessage)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		name, _ := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		names = append(names, name)
+		values[name] = value
+	}
+	return names, values, nil
+}
+
+// csvCell formats a JSON value as a CSV cell: strings unquoted and escaped
+// so they can't run as formulas, null empty and everything else as its JSON
+func csvCell(value json.RawMessage) string {
+	if len(value) == 0 || string(value) == "null" {
+		return ""
+	}
+	var s string
+	if value[0] == '"' && json.Unmarshal(value, &s) == nil {
+		return spreadsheet.EscapeFormula(s)
+	}
+	return string(value)
+}
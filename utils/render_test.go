@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCSVCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{`"Avanza"`, "Avanza"},
+		{`"=HYPERLINK(\"http://x\")"`, `'=HYPERLINK("http://x")`},
+		{`"-B 1234"`, "'-B 1234"},
+		{`-20`, "-20"},
+		{`150000.5`, "150000.5"},
+		{`true`, "true"},
+		{`null`, ""},
+	}
+	for _, tt := range tests {
+		if got := csvCell(json.RawMessage(tt.value)); got != tt.want {
+			t.Errorf("csvCell(%s) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
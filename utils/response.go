@@ -1,24 +1,91 @@
 package utils
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"api-rentcar/logger"
 
 	"github.com/gin-gonic/gin"
 )
 
-// ErrorResponse represents an error response
+// Response formats
+const (
+	// ResponseFormatEnvelope wraps every response body in an Envelope
+	ResponseFormatEnvelope = "envelope"
+	// ResponseFormatLegacy keeps the bodies of earlier versions: ErrorResponse
+	// for errors, SuccessResponse for writes and bare resources for reads
+	ResponseFormatLegacy = "legacy"
+)
+
+// legacyResponses is set when responses use ResponseFormatLegacy
+var legacyResponses bool
+
+// SetResponseFormat selects the format every response is sent in
+func SetResponseFormat(format string) error {
+	switch format {
+	case ResponseFormatEnvelope:
+		legacyResponses = false
+	case ResponseFormatLegacy:
+		legacyResponses = true
+	default:
+		return fmt.Errorf("unknown response format %q (use %s or %s)", format, ResponseFormatEnvelope, ResponseFormatLegacy)
+	}
+	return nil
+}
+
+// Envelope is the body of every response
+// @Description Response envelope: the resource in data, or what went wrong in errors
+type Envelope struct {
+	// The requested, created or updated resource
+	Data any `json:"data,omitempty"`
+	// Message, request ID and pagination
+	Meta Meta `json:"meta"`
+	// Why the request failed
+	Errors []APIError `json:"errors,omitempty"`
+}
+
+// Meta holds the metadata of a response
+// @Description Response metadata
+type Meta struct {
+	Message string `json:"message,omitempty" example:"Operation completed successfully"`
+	// ID of the request, to quote when reporting an error
+	RequestID string `json:"request_id,omitempty" example:"3f8c2a9e-6b1d-4c7a-9f2e-1a2b3c4d5e6f"`
+	// Pagination of lists
+	Pagination *PaginationMeta `json:"pagination,omitempty"`
+}
+
+// APIError describes why a request failed
+// @Description Error of a failed request
+type APIError struct {
+	Message string `json:"message" example:"Error message"`
+	Detail  string `json:"detail,omitempty" example:"Detailed error information"`
+	// Index of the item the error is about, in requests with several items
+	Index *int `json:"index,omitempty" example:"0"`
+}
+
+// ErrorResponse represents an error response in the legacy format
 // @Description Error response format
 type ErrorResponse struct {
 	Success bool   `json:"success" example:"false"`
 	Message string `json:"message" example:"Error message"`
 	Error   string `json:"error,omitempty" example:"Detailed error information"`
+	// ID of the request, to quote when reporting the error
+	RequestID string `json:"request_id,omitempty" example:"3f8c2a9e-6b1d-4c7a-9f2e-1a2b3c4d5e6f"`
 }
 
-// SuccessResponse represents a success response
+// SuccessResponse represents a success response in the legacy format
 // @Description Success response format
 type SuccessResponse struct {
 	Success bool   `json:"success" example:"true"`
 	Message string `json:"message" example:"Operation completed successfully"`
+	// The created, updated or requested resource
+	Data any `json:"data,omitempty"`
 }
 
 // PaginationMeta represents pagination metadata
@@ -55,7 +122,7 @@ type PaginationMeta struct {
 	HasPrev bool `json:"has_prev" example:"false"`
 }
 
-// PaginatedResponse represents a paginated response
+// PaginatedResponse represents a paginated response in the legacy format
 // @Description Paginated response format
 type PaginatedResponse struct {
 	Success bool   `json:"success" example:"true"`
@@ -68,51 +135,190 @@ type PaginatedResponse struct {
 
 // SendErrorResponse sends an error response
 func SendErrorResponse(c *gin.Context, statusCode int, message string, err error) {
-	response := ErrorResponse{
-		Success: false,
-		Message: message,
+	var detail string
+	if err != nil {
+		detail = err.Error()
 	}
 
-	if err != nil {
-		response.Error = err.Error()
+	// Failures caused by the request's query deadline are timeouts rather than server errors
+	if statusCode == http.StatusInternalServerError && errors.Is(err, context.DeadlineExceeded) {
+		statusCode = http.StatusGatewayTimeout
+		message = "Request timed out"
+	}
+
+	sendErrors(c, statusCode, message, detail, APIError{Message: message, Detail: detail})
+}
+
+// SendValidationErrorResponse sends a validation error response
+func SendValidationErrorResponse(c *gin.Context, validationErrors []string) {
+	apiErrors := make([]APIError, len(validationErrors))
+	for i, validationError := range validationErrors {
+		apiErrors[i] = APIError{Message: "Validation failed", Detail: validationError}
 	}
 
-	c.JSON(statusCode, response)
+	sendErrors(c, http.StatusBadRequest, "Validation failed", "Invalid input data: "+joinErrors(validationErrors), apiErrors...)
 }
 
-// SendSuccessResponse sends a success response
+// SendErrorsResponse sends several errors, e.g., one per failed item of a bulk
+// request. In the legacy format, their details are joined, each after the index
+// of its item if it has one.
+func SendErrorsResponse(c *gin.Context, statusCode int, message string, apiErrors []APIError) {
+	details := make([]string, len(apiErrors))
+	for i, apiError := range apiErrors {
+		details[i] = apiError.Detail
+		if apiError.Index != nil {
+			details[i] = fmt.Sprintf("item %d: %s", *apiError.Index, apiError.Detail)
+		}
+	}
+
+	sendErrors(c, statusCode, message, joinErrors(details), apiErrors...)
+}
+
+// sendErrors sends errors in an Envelope, or as an ErrorResponse with message
+// and detail in the legacy format
+func sendErrors(c *gin.Context, statusCode int, message, detail string, apiErrors ...APIError) {
+	// Errors aren't cached like the resource they were asked for
+	c.Writer.Header().Del("Cache-Control")
+	c.Writer.Header().Del("Last-Modified")
+
+	requestID := logger.RequestID(c.Request.Context())
+	if legacyResponses {
+		respond(c, statusCode, ErrorResponse{
+			Success:   false,
+			Message:   message,
+			Error:     detail,
+			RequestID: requestID,
+		}, nil, true)
+		return
+	}
+
+	respond(c, statusCode, Envelope{
+		Meta:   Meta{RequestID: requestID},
+		Errors: apiErrors,
+	}, nil, true)
+}
+
+// SendSuccessResponse sends a success response with a message and, unless
+// nil, data. In the legacy format it is a SuccessResponse.
 func SendSuccessResponse(c *gin.Context, statusCode int, message string, data interface{}) {
-	response := SuccessResponse{
-		Success: true,
-		Message: message,
+	if legacyResponses {
+		respond(c, statusCode, SuccessResponse{
+			Success: true,
+			Message: message,
+			Data:    data,
+		}, data, false)
+		return
 	}
 
-	c.JSON(statusCode, response)
+	sendEnvelope(c, statusCode, Envelope{Data: data, Meta: Meta{Message: message}})
 }
 
-// SendPaginatedResponse sends a paginated response
-func SendPaginatedResponse(c *gin.Context, message string, data interface{}, total int64, page, limit int) {
-	response := PaginatedResponse{
-		Success: true,
-		Message: message,
-		Data:    data,
-		Total:   total,
-		Page:    page,
-		Limit:   limit,
+// SendDataResponse sends data, e.g., a resource. In the legacy format it is
+// the bare data.
+func SendDataResponse(c *gin.Context, statusCode int, data interface{}) {
+	if legacyResponses {
+		respond(c, statusCode, data, data, false)
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	sendEnvelope(c, statusCode, Envelope{Data: data})
 }
 
-// SendValidationErrorResponse sends a validation error response
-func SendValidationErrorResponse(c *gin.Context, validationErrors []string) {
-	response := ErrorResponse{
-		Success: false,
-		Message: "Validation failed",
-		Error:   "Invalid input data: " + joinErrors(validationErrors),
+// SendListResponse sends a page of a list. In the legacy format it is an
+// object with the data and pagination.
+func SendListResponse(c *gin.Context, data interface{}, pagination PaginationMeta) {
+	if legacyResponses {
+		respond(c, http.StatusOK, gin.H{"data": data, "pagination": pagination}, data, false)
+		return
+	}
+
+	sendEnvelope(c, http.StatusOK, Envelope{Data: data, Meta: Meta{Pagination: &pagination}})
+}
+
+// SendWriteResponse sends the response of a create or update with the
+// resource as data, or without it when the client sent Prefer: return=minimal
+func SendWriteResponse(c *gin.Context, statusCode int, message string, data interface{}) {
+	if PrefersMinimalReturn(c) {
+		c.Header("Preference-Applied", "return=minimal")
+		data = nil
+	}
+	SendSuccessResponse(c, statusCode, message, data)
+}
+
+// SendCreatedResponse sends a 201 write response with a Location header
+// pointing to the new resource, below the request path
+func SendCreatedResponse(c *gin.Context, message string, id uint, data interface{}) {
+	c.Header("Location", strings.TrimSuffix(c.Request.URL.Path, "/")+"/"+strconv.FormatUint(uint64(id), 10))
+	SendWriteResponse(c, http.StatusCreated, message, data)
+}
+
+// SetLastModified sets the Last-Modified header to the latest of times,
+// unless they are all zero, e.g., when not loaded
+func SetLastModified(c *gin.Context, times ...time.Time) {
+	var latest time.Time
+	for _, t := range times {
+		if t.After(latest) {
+			latest = t
+		}
 	}
+	if !latest.IsZero() {
+		c.Header("Last-Modified", latest.UTC().Format(http.TimeFormat))
+	}
+}
+
+// PrefersMinimalReturn reports whether the request's Prefer headers
+// (RFC 7240) ask for return=minimal
+func PrefersMinimalReturn(c *gin.Context) bool {
+	for _, header := range c.Request.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			// Drop parameters, e.g., return=minimal; foo=bar
+			token, _, _ := strings.Cut(preference, ";")
+			name, value, _ := strings.Cut(strings.TrimSpace(token), "=")
+			if strings.EqualFold(strings.TrimSpace(name), "return") &&
+				strings.EqualFold(strings.Trim(strings.TrimSpace(value), `"`), "minimal") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SendPaginatedResponse sends a page of a list with a message. In the legacy
+// format it is a PaginatedResponse.
+func SendPaginatedResponse(c *gin.Context, message string, data interface{}, total int64, page, limit int) {
+	if legacyResponses {
+		respond(c, http.StatusOK, PaginatedResponse{
+			Success: true,
+			Message: message,
+			Data:    data,
+			Total:   total,
+			Page:    page,
+			Limit:   limit,
+		}, data, false)
+		return
+	}
+
+	pagination := CreatePaginationMeta(total, page, limit)
+	sendEnvelope(c, http.StatusOK, Envelope{Data: data, Meta: Meta{Message: message, Pagination: &pagination}})
+}
 
-	c.JSON(http.StatusBadRequest, response)
+// sendEnvelope sends an Envelope with the request ID in its metadata. CSV
+// responses carry the envelope's data alone.
+func sendEnvelope(c *gin.Context, statusCode int, envelope Envelope) {
+	envelope.Meta.RequestID = logger.RequestID(c.Request.Context())
+	respond(c, statusCode, envelope, envelope.Data, false)
+}
+
+// NormalizePagination returns the page and limit a list is read with: the
+// first page for a page below 1, and 10 items for a limit outside 1 to 100
+func NormalizePagination(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	return page, limit
 }
 
 // CreatePaginationMeta creates pagination metadata
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sendItemErrors sends errors about the first and third items of a request
+func sendItemErrors(t *testing.T) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/cars/bulk", nil)
+
+	first, third := 0, 2
+	SendErrorsResponse(c, http.StatusBadRequest, "Some cars are invalid", []APIError{
+		{Message: "Item 0 failed", Detail: "name is required", Index: &first},
+		{Message: "Item 2 failed", Detail: "year is too low", Index: &third},
+	})
+	return w
+}
+
+func TestSendErrorsResponseIndexesItems(t *testing.T) {
+	var body Envelope
+	if err := json.Unmarshal(sendItemErrors(t).Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Errors) != 2 {
+		t.Fatalf("errors = %+v, want 2", body.Errors)
+	}
+	for i, want := range []int{0, 2} {
+		if got := body.Errors[i].Index; got == nil || *got != want {
+			t.Errorf("errors[%d].index = %v, want %d", i, got, want)
+		}
+	}
+}
+
+func TestSendErrorsResponseLegacyIndexesItems(t *testing.T) {
+	if err := SetResponseFormat(ResponseFormatLegacy); err != nil {
+		t.Fatal(err)
+	}
+	defer SetResponseFormat(ResponseFormatEnvelope)
+
+	var body ErrorResponse
+	if err := json.Unmarshal(sendItemErrors(t).Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	want := joinErrors([]string{"item 0: name is required", "item 2: year is too low"})
+	if body.Message != "Some cars are invalid" || body.Error != want {
+		t.Errorf("legacy body = %+v, want error %q", body, want)
+	}
+}
+
+func TestNormalizePagination(t *testing.T) {
+	tests := []struct {
+		page, limit         int
+		wantPage, wantLimit int
+	}{
+		{page: 2, limit: 25, wantPage: 2, wantLimit: 25},
+		{page: 0, limit: 0, wantPage: 1, wantLimit: 10},
+		{page: -3, limit: -1, wantPage: 1, wantLimit: 10},
+		{page: 1, limit: 100, wantPage: 1, wantLimit: 100},
+		{page: 1, limit: 500, wantPage: 1, wantLimit: 10},
+	}
+	for _, tt := range tests {
+		page, limit := NormalizePagination(tt.page, tt.limit)
+		if page != tt.wantPage || limit != tt.wantLimit {
+			t.Errorf("NormalizePagination(%d, %d) = %d, %d, want %d, %d", tt.page, tt.limit, page, limit, tt.wantPage, tt.wantLimit)
+		}
+		// The normalized limit can always be paginated by
+		CreatePaginationMeta(42, page, limit)
+	}
+}